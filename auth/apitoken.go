@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// ApiTokenPrefix starts every api token, telling them apart from JWTs
+const ApiTokenPrefix = "gtk_"
+
+// apiTokenPrefixLength is the length of the part of the token kept in clear to identify it
+const apiTokenPrefixLength = len(ApiTokenPrefix) + 8
+
+// ApiTokenResources are the resources api tokens can be scoped to. A scope is
+// a resource followed by :read or :write, e.g. task:read. Write scopes also
+// allow reading.
+var ApiTokenResources = []string{"task", "template", "customer", "site", "project", "custom-field", "webhook", "user", "organization"}
+
+// NewApiToken returns a new api token, the prefix that identifies it and the hash to be stored
+func NewApiToken() (string, string, string, error) {
+	random, _, err := utils.NewToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	token := ApiTokenPrefix + random
+	return token, token[:apiTokenPrefixLength], utils.HashToken(token), nil
+}
+
+// IsApiToken reports whether the credential is an api token instead of a JWT
+func IsApiToken(token string) bool {
+	return strings.HasPrefix(token, ApiTokenPrefix)
+}
+
+// ValidApiTokenScope reports whether the scope names a known resource and access
+func ValidApiTokenScope(scope string) bool {
+	resource, access, ok := strings.Cut(scope, ":")
+	if !ok || (access != "read" && access != "write") {
+		return false
+	}
+
+	for _, r := range ApiTokenResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// ApiTokenScope returns the scope required to call the route, e.g. GET
+// /task/:id requires task:read and PATCH /task/:id requires task:write
+func ApiTokenScope(method string, fullPath string) string {
+	resource := strings.SplitN(strings.TrimPrefix(fullPath, "/"), "/", 2)[0]
+
+	access := "write"
+	if method == http.MethodGet || method == http.MethodHead {
+		access = "read"
+	}
+
+	return resource + ":" + access
+}
+
+// ApiTokenAllows reports whether the scopes grant access to the route
+func ApiTokenAllows(scopes []string, method string, fullPath string) bool {
+	required := ApiTokenScope(method, fullPath)
+	resource, access, _ := strings.Cut(required, ":")
+
+	for _, scope := range scopes {
+		if scope == required || (access == "read" && scope == resource+":write") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewApiToken(t *testing.T) {
+	assert := assert.New(t)
+
+	token, prefix, hash, err := auth.NewApiToken()
+
+	// asserts
+	assert.Nil(err)
+	assert.True(auth.IsApiToken(token))
+	assert.True(strings.HasPrefix(token, prefix))
+	assert.Len(prefix, 12)
+	assert.Equal(utils.HashToken(token), hash)
+	assert.NotContains(hash, token)
+}
+
+func TestValidApiTokenScope(t *testing.T) {
+	assert := assert.New(t)
+
+	// asserts
+	assert.True(auth.ValidApiTokenScope("task:read"))
+	assert.True(auth.ValidApiTokenScope("custom-field:write"))
+	assert.False(auth.ValidApiTokenScope("task"))
+	assert.False(auth.ValidApiTokenScope("task:admin"))
+	assert.False(auth.ValidApiTokenScope("billing:read"))
+}
+
+func TestApiTokenAllows(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: read scope allows reading", func(t *testing.T) {
+		// asserts
+		assert.Equal("task:read", auth.ApiTokenScope("GET", "/task/:id"))
+		assert.True(auth.ApiTokenAllows([]string{"task:read"}, "GET", "/task/:id"))
+	})
+
+	t.Run("Success: write scope allows reading and writing", func(t *testing.T) {
+		// asserts
+		assert.True(auth.ApiTokenAllows([]string{"task:write"}, "GET", "/task"))
+		assert.True(auth.ApiTokenAllows([]string{"task:write"}, "PATCH", "/task/execute/:id"))
+	})
+
+	t.Run("Failed: read scope does not allow writing", func(t *testing.T) {
+		// asserts
+		assert.False(auth.ApiTokenAllows([]string{"task:read"}, "POST", "/task"))
+	})
+
+	t.Run("Failed: scope of another resource", func(t *testing.T) {
+		// asserts
+		assert.False(auth.ApiTokenAllows([]string{"task:write"}, "GET", "/customer"))
+	})
+}
@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"os"
+
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// RequireMfaForManagers makes two-factor authentication mandatory for
+// managers, set by MFA_REQUIRED_FOR_MANAGERS=true. Managers without MFA get a
+// token that only allows the MFA enrollment.
+var RequireMfaForManagers = os.Getenv("MFA_REQUIRED_FOR_MANAGERS") == "true"
+
+// MfaRequired reports whether the policy requires MFA for the user
+func MfaRequired(user models.User) bool {
+	return RequireMfaForManagers && user.IsManager
+}
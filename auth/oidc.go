@@ -0,0 +1,384 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// OIDCConfig configures the login with an OpenID Connect identity provider.
+// Users get the manager role when they belong to one of ManagerGroups. When
+// TechnicianGroups is set, users outside of both lists are refused.
+type OIDCConfig struct {
+	Issuer           string
+	ClientID         string
+	ClientSecret     string
+	RedirectURL      string
+	Scopes           []string
+	GroupsClaim      string
+	ManagerGroups    []string
+	TechnicianGroups []string
+	OrganizationId   uint32
+}
+
+// OIDCConfigFromEnv reads the OIDC_* environment variables
+func OIDCConfigFromEnv() OIDCConfig {
+	organizationId, _ := strconv.ParseUint(os.Getenv("OIDC_ORGANIZATION_ID"), 10, 32)
+
+	config := OIDCConfig{
+		Issuer:           os.Getenv("OIDC_ISSUER"),
+		ClientID:         os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:     os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:      os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:           splitList(os.Getenv("OIDC_SCOPES")),
+		GroupsClaim:      os.Getenv("OIDC_GROUPS_CLAIM"),
+		ManagerGroups:    splitList(os.Getenv("OIDC_MANAGER_GROUPS")),
+		TechnicianGroups: splitList(os.Getenv("OIDC_TECHNICIAN_GROUPS")),
+		OrganizationId:   uint32(organizationId),
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile", "groups"}
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	return config
+}
+
+// Configured reports whether an identity provider is set
+func (config OIDCConfig) Configured() bool {
+	return config.Issuer != "" && config.ClientID != "" && config.RedirectURL != ""
+}
+
+// Role maps the groups of the identity provider to the role of the user. It
+// returns false when the groups don't allow the access.
+func (config OIDCConfig) Role(groups []string) (isManager bool, allowed bool) {
+	for _, group := range groups {
+		if containsGroup(config.ManagerGroups, group) {
+			return true, true
+		}
+	}
+
+	if len(config.TechnicianGroups) == 0 {
+		return false, true
+	}
+	for _, group := range groups {
+		if containsGroup(config.TechnicianGroups, group) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// OIDCIdentity is the user authenticated by the identity provider
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// IOIDCProvider runs the authorization code flow with PKCE
+type IOIDCProvider interface {
+	Config() OIDCConfig
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (OIDCIdentity, error)
+}
+
+// OIDCProviderServices is the identity provider of the api, nil when single sign-on is not configured
+var OIDCProviderServices IOIDCProvider
+
+// OIDCProvider talks to the identity provider. The discovery document and the
+// signing keys are fetched on first use, the keys are fetched again when a
+// token is signed with an unknown key.
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider returns the provider of the configuration, nil when it is not configured
+func NewOIDCProvider(config OIDCConfig) IOIDCProvider {
+	if !config.Configured() {
+		return nil
+	}
+	return &OIDCProvider{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *OIDCProvider) Config() OIDCConfig {
+	return p.config
+}
+
+// AuthCodeURL returns the url of the identity provider the user is redirected to
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code and verifies the ID token returned
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (OIDCIdentity, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := p.doJSON(request, &tokens); err != nil {
+		return OIDCIdentity{}, fmt.Errorf("token exchange: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return OIDCIdentity{}, errors.New("token exchange: id_token not returned")
+	}
+
+	return p.verifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, idToken string, nonce string) (OIDCIdentity, error) {
+	token, err := jwt.Parse(idToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return OIDCIdentity{}, fmt.Errorf("id token: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return OIDCIdentity{}, errors.New("id token: invalid claims")
+	}
+	if !claims.VerifyIssuer(p.config.Issuer, true) {
+		return OIDCIdentity{}, errors.New("id token: invalid issuer")
+	}
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return OIDCIdentity{}, errors.New("id token: invalid audience")
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return OIDCIdentity{}, errors.New("id token: expired")
+	}
+	if claimNonce, _ := claims["nonce"].(string); claimNonce == "" || claimNonce != nonce {
+		return OIDCIdentity{}, errors.New("id token: invalid nonce")
+	}
+
+	identity := OIDCIdentity{}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+	identity.Name, _ = claims["name"].(string)
+	identity.Groups = stringList(claims[p.config.GroupsClaim])
+
+	if identity.Subject == "" {
+		return OIDCIdentity{}, errors.New("id token: subject not provided")
+	}
+	return identity, nil
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var discovery oidcDiscovery
+	if err := p.doJSON(request, &discovery); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if discovery.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", discovery.Issuer, p.config.Issuer)
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.JwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.doJSON(request, &jwks); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	key, ok = keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("jwks: unknown key %q", kid)
+	}
+	return key, nil
+}
+
+func (p *OIDCProvider) doJSON(request *http.Request, v interface{}) error {
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+// NewPKCE returns a PKCE code verifier and its S256 challenge
+func NewPKCE() (string, string, error) {
+	verifier, _, err := utils.NewToken()
+	if err != nil {
+		return "", "", err
+	}
+	return verifier, PKCEChallenge(verifier), nil
+}
+
+// PKCEChallenge returns the S256 challenge of a code verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return []string{}
+}
+
+func containsGroup(groups []string, group string) bool {
+	for _, g := range groups {
+		if strings.EqualFold(g, group) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/auth/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+// authorize runs the authorization on the provider and returns the code
+func authorize(t *testing.T, provider auth.IOIDCProvider, nonce string, challenge string) string {
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", nonce, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	response, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	callback, _ := url.Parse(response.Header.Get("Location"))
+	return callback.Query().Get("code")
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	assert := assert.New(t)
+
+	idp := oidctest.NewServer("gtasks", "client-secret")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "idp-1", Email: "tech@corp.com", EmailVerified: true, Name: "Tech", Groups: []string{"gtasks-technicians"}})
+
+	config := auth.OIDCConfig{
+		Issuer:       idp.URL,
+		ClientID:     "gtasks",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:8080/user/login/oidc/callback",
+		Scopes:       []string{"openid"},
+		GroupsClaim:  "groups",
+	}
+	provider := auth.NewOIDCProvider(config)
+
+	t.Run("Success: identity of the ID token", func(t *testing.T) {
+		verifier, challenge, _ := auth.NewPKCE()
+		code := authorize(t, provider, "nonce", challenge)
+
+		identity, err := provider.Exchange(context.Background(), code, verifier, "nonce")
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(auth.OIDCIdentity{Subject: "idp-1", Email: "tech@corp.com", EmailVerified: true, Name: "Tech", Groups: []string{"gtasks-technicians"}}, identity)
+	})
+
+	t.Run("Failed: wrong PKCE verifier", func(t *testing.T) {
+		_, challenge, _ := auth.NewPKCE()
+		otherVerifier, _, _ := auth.NewPKCE()
+		code := authorize(t, provider, "nonce", challenge)
+
+		_, err := provider.Exchange(context.Background(), code, otherVerifier, "nonce")
+
+		// asserts
+		assert.NotNil(err)
+	})
+
+	t.Run("Failed: nonce of another login", func(t *testing.T) {
+		verifier, challenge, _ := auth.NewPKCE()
+		code := authorize(t, provider, "nonce", challenge)
+
+		_, err := provider.Exchange(context.Background(), code, verifier, "other-nonce")
+
+		// asserts
+		assert.EqualError(err, "id token: invalid nonce")
+	})
+
+	t.Run("Failed: token issued to another client", func(t *testing.T) {
+		other := config
+		other.ClientID = "other"
+		otherProvider := auth.NewOIDCProvider(other)
+
+		verifier, challenge, _ := auth.NewPKCE()
+		code := authorize(t, provider, "nonce", challenge)
+
+		_, err := otherProvider.Exchange(context.Background(), code, verifier, "nonce")
+
+		// asserts
+		assert.NotNil(err)
+	})
+}
+
+func TestOIDCConfigRole(t *testing.T) {
+	assert := assert.New(t)
+
+	config := auth.OIDCConfig{ManagerGroups: []string{"gtasks-managers"}, TechnicianGroups: []string{"gtasks-technicians"}}
+
+	isManager, allowed := config.Role([]string{"sales", "GTasks-Managers"})
+	assert.True(isManager)
+	assert.True(allowed)
+
+	isManager, allowed = config.Role([]string{"gtasks-technicians"})
+	assert.False(isManager)
+	assert.True(allowed)
+
+	_, allowed = config.Role([]string{"sales"})
+	assert.False(allowed)
+
+	config.TechnicianGroups = nil
+	isManager, allowed = config.Role([]string{"sales"})
+	assert.False(isManager)
+	assert.True(allowed)
+}
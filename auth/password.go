@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// PasswordPolicy is checked when a password is chosen (signup, reset and
+// change). BreachedListPath is a local file with one breached or common
+// password per line, compared case-insensitively.
+type PasswordPolicy struct {
+	MinLength        int
+	MaxLength        int
+	BreachedListPath string
+
+	breachedOnce sync.Once
+	breached     map[string]struct{}
+}
+
+// DefaultPasswordPolicy is the policy of the api, set by PASSWORD_MIN_LENGTH
+// and PASSWORD_BREACHED_LIST
+var DefaultPasswordPolicy = PasswordPolicyFromEnv()
+
+// PasswordPolicyFromEnv reads the PASSWORD_* environment variables
+func PasswordPolicyFromEnv() *PasswordPolicy {
+	minLength, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH"))
+	if err != nil || minLength <= 0 {
+		minLength = 8
+	}
+
+	return &PasswordPolicy{
+		MinLength:        minLength,
+		MaxLength:        128,
+		BreachedListPath: os.Getenv("PASSWORD_BREACHED_LIST"),
+	}
+}
+
+// Validate returns the reason the password is refused, nil when it is accepted
+func (p *PasswordPolicy) Validate(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%v: minimum %d characters", utils.UserPasswordTooShort, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%v: maximum %d characters", utils.UserPasswordTooLong, p.MaxLength)
+	}
+
+	if p.isBreached(password) {
+		return fmt.Errorf("%v", utils.UserPasswordBreached)
+	}
+	return nil
+}
+
+func (p *PasswordPolicy) isBreached(password string) bool {
+	p.breachedOnce.Do(p.loadBreached)
+
+	_, found := p.breached[strings.ToLower(password)]
+	return found
+}
+
+// loadBreached reads the breached password list once, the policy works
+// without the list when the file can't be read
+func (p *PasswordPolicy) loadBreached() {
+	p.breached = map[string]struct{}{}
+	if p.BreachedListPath == "" {
+		return
+	}
+
+	file, err := os.Open(p.BreachedListPath)
+	if err != nil {
+		log.Printf("error reading breached password list: %s\n", err)
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			p.breached[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("error reading breached password list: %s\n", err)
+	}
+}
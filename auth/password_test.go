@@ -0,0 +1,32 @@
+package auth_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	breachedList := filepath.Join(t.TempDir(), "breached.txt")
+	os.WriteFile(breachedList, []byte("password123\nqwertyuiop\r\n"), 0o600)
+
+	policy := &auth.PasswordPolicy{MinLength: 8, MaxLength: 128, BreachedListPath: breachedList}
+
+	// asserts
+	assert.Nil(policy.Validate("correct-horse"))
+	assert.EqualError(policy.Validate("secret"), "password is too short: minimum 8 characters")
+	assert.EqualError(policy.Validate("Password123"), "password is too common or appeared in a data breach")
+	assert.EqualError(policy.Validate("qwertyuiop"), "password is too common or appeared in a data breach")
+
+	t.Run("Success: missing list does not block", func(t *testing.T) {
+		policy := &auth.PasswordPolicy{MinLength: 8, BreachedListPath: filepath.Join(t.TempDir(), "missing.txt")}
+
+		// asserts
+		assert.Nil(policy.Validate("password123"))
+	})
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+)
+
+// LoginPolicy configures the login throttling. After FreeAttempts failures
+// each new attempt must wait BaseDelay, doubled on every further failure up to
+// MaxDelay. Reaching the lockout thresholds blocks the account from the IP (or
+// the IP) for LockoutDuration. The failures on the account from every IP
+// follow the same steps from EmailFreeAttempts to EmailLockout, higher so an
+// attack spread over many IPs slows its owner down before locking it out.
+// Only the failures inside Window count, and a successful login resets the
+// failures of the account.
+type LoginPolicy struct {
+	FreeAttempts      int
+	BaseDelay         time.Duration
+	MaxDelay          time.Duration
+	AccountLockout    int
+	IPLockout         int
+	EmailFreeAttempts int
+	EmailLockout      int
+	LockoutDuration   time.Duration
+	Window            time.Duration
+}
+
+var DefaultLoginPolicy = LoginPolicy{
+	FreeAttempts:      3,
+	BaseDelay:         time.Second,
+	MaxDelay:          5 * time.Minute,
+	AccountLockout:    10,
+	IPLockout:         50,
+	EmailFreeAttempts: 20,
+	EmailLockout:      100,
+	LockoutDuration:   15 * time.Minute,
+	Window:            time.Hour,
+}
+
+// LoginRetryAfter returns how long the next login attempt on email from ip must
+// wait, zero when it can be tried now. The account is throttled per IP, so
+// failures from a few other addresses can't lock its owner out, and on the
+// failures from every IP with higher thresholds; the IP is throttled on the
+// failures on every account.
+func LoginRetryAfter(email string, ip string, now time.Time) (time.Duration, error) {
+	policy := DefaultLoginPolicy
+	since := now.Add(-policy.Window)
+
+	accountFailures, err := repository.LoginAttemptRepositoryServices.CountAccountFailures(email, ip, since)
+	if err != nil {
+		return 0, err
+	}
+
+	emailFailures, err := repository.LoginAttemptRepositoryServices.CountEmailFailures(email, since)
+	if err != nil {
+		return 0, err
+	}
+
+	ipFailures, err := repository.LoginAttemptRepositoryServices.CountIPFailures(ip, since)
+	if err != nil {
+		return 0, err
+	}
+
+	wait := policy.AccountRetryAfter(accountFailures, now)
+	if emailWait := policy.EmailRetryAfter(emailFailures, now); emailWait > wait {
+		wait = emailWait
+	}
+	if ipWait := policy.IPRetryAfter(ipFailures, now); ipWait > wait {
+		wait = ipWait
+	}
+	return wait, nil
+}
+
+// AccountRetryAfter applies the policy to the failures on an account from an IP
+func (p LoginPolicy) AccountRetryAfter(failures models.LoginFailures, now time.Time) time.Duration {
+	return p.retryAfter(failures, p.FreeAttempts, p.AccountLockout, now)
+}
+
+// EmailRetryAfter applies the policy to the failures on an account from every IP
+func (p LoginPolicy) EmailRetryAfter(failures models.LoginFailures, now time.Time) time.Duration {
+	return p.retryAfter(failures, p.EmailFreeAttempts, p.EmailLockout, now)
+}
+
+// IPRetryAfter applies the policy to the failures from an IP
+func (p LoginPolicy) IPRetryAfter(failures models.LoginFailures, now time.Time) time.Duration {
+	return p.retryAfter(failures, p.FreeAttempts, p.IPLockout, now)
+}
+
+func (p LoginPolicy) retryAfter(failures models.LoginFailures, freeAttempts int, lockout int, now time.Time) time.Duration {
+	count := failures.Count
+	if count < freeAttempts || failures.LastAt == nil {
+		return 0
+	}
+
+	last := *failures.LastAt
+
+	var wait time.Duration
+	if count >= lockout {
+		wait = p.LockoutDuration
+	} else {
+		wait = p.BaseDelay
+		for i := freeAttempts; i < count && wait < p.MaxDelay; i++ {
+			wait *= 2
+		}
+		if wait > p.MaxDelay {
+			wait = p.MaxDelay
+		}
+	}
+
+	if remaining := last.Add(wait).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
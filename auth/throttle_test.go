@@ -0,0 +1,74 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func failures(now time.Time, count int) models.LoginFailures {
+	last := now.Add(-time.Millisecond)
+	return models.LoginFailures{Count: count, LastAt: &last}
+}
+
+func TestAccountRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := auth.DefaultLoginPolicy
+	now := time.Now()
+
+	t.Run("Success: free attempts are not delayed", func(t *testing.T) {
+		// asserts
+		assert.Zero(policy.AccountRetryAfter(failures(now, 2), now))
+		assert.Zero(policy.AccountRetryAfter(models.LoginFailures{}, now))
+	})
+
+	t.Run("Success: delay doubles on every failure", func(t *testing.T) {
+		first := policy.AccountRetryAfter(failures(now, 3), now)
+		second := policy.AccountRetryAfter(failures(now, 4), now)
+		third := policy.AccountRetryAfter(failures(now, 5), now)
+
+		// asserts
+		assert.InDelta(time.Second, first, float64(10*time.Millisecond))
+		assert.InDelta(2*time.Second, second, float64(10*time.Millisecond))
+		assert.InDelta(4*time.Second, third, float64(10*time.Millisecond))
+	})
+
+	t.Run("Success: account locked after too many failures", func(t *testing.T) {
+		wait := policy.AccountRetryAfter(failures(now, policy.AccountLockout), now)
+
+		// asserts
+		assert.InDelta(policy.LockoutDuration, wait, float64(10*time.Millisecond))
+	})
+
+	t.Run("Success: delay over once the last failure is old enough", func(t *testing.T) {
+		// asserts
+		assert.Zero(policy.AccountRetryAfter(failures(now.Add(-time.Minute), 4), now))
+	})
+}
+
+func TestIPRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := auth.DefaultLoginPolicy
+	now := time.Now()
+
+	// asserts
+	assert.InDelta(policy.LockoutDuration, policy.IPRetryAfter(failures(now, policy.IPLockout), now), float64(10*time.Millisecond))
+	assert.InDelta(policy.MaxDelay, policy.IPRetryAfter(failures(now, policy.IPLockout-1), now), float64(10*time.Millisecond))
+}
+
+func TestEmailRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := auth.DefaultLoginPolicy
+	now := time.Now()
+
+	// asserts
+	assert.Zero(policy.EmailRetryAfter(failures(now, policy.EmailFreeAttempts-1), now))
+	assert.InDelta(policy.BaseDelay, policy.EmailRetryAfter(failures(now, policy.EmailFreeAttempts), now), float64(10*time.Millisecond))
+	assert.InDelta(policy.LockoutDuration, policy.EmailRetryAfter(failures(now, policy.EmailLockout), now), float64(10*time.Millisecond))
+}
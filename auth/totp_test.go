@@ -0,0 +1,66 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	assert := assert.New(t)
+
+	// RFC 6238 test secret "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	now := time.Unix(59, 0)
+
+	t.Run("Success: RFC 6238 test vector", func(t *testing.T) {
+		code, err := auth.TOTPCode(secret, now)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal("287082", code)
+	})
+
+	t.Run("Success: code of the previous period is accepted", func(t *testing.T) {
+		step, ok := auth.ValidateTOTP(secret, "287082", now.Add(30*time.Second))
+
+		// asserts
+		assert.True(ok)
+		assert.Equal(int64(1), step)
+	})
+
+	t.Run("Failed: expired or wrong code", func(t *testing.T) {
+		_, expired := auth.ValidateTOTP(secret, "287082", now.Add(90*time.Second))
+		_, wrong := auth.ValidateTOTP(secret, "000000", now)
+
+		// asserts
+		assert.False(expired)
+		assert.False(wrong)
+	})
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	assert := assert.New(t)
+
+	uri := auth.TOTPProvisioningURI("JBSWY3DPEHPK3PXP", "tech@gtasks.com")
+
+	// asserts
+	assert.True(strings.HasPrefix(uri, "otpauth://totp/gtasks:tech@gtasks.com?"))
+	assert.Contains(uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(uri, "issuer=gtasks")
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	assert := assert.New(t)
+
+	codes, err := auth.GenerateRecoveryCodes(10)
+
+	// asserts
+	assert.Nil(err)
+	assert.Len(codes, 10)
+	assert.Len(codes[0], 11)
+	assert.Equal(strings.ReplaceAll(codes[0], "-", ""), auth.NormalizeRecoveryCode(" "+strings.ToUpper(codes[0])))
+}
@@ -0,0 +1,108 @@
+// Package broker defines how gtasks publishes and consumes messages. The
+// services depend on the Publisher and Subscriber interfaces: the rabbitmq
+// package implements them over AMQP and Memory in process, for tests and for
+// running without RabbitMQ.
+package broker
+
+import (
+	"context"
+	"errors"
+)
+
+// Queues of gtasks
+const (
+	QueueTasks         = "tasks"
+	QueueUsers         = "users"
+	QueueNotifications = "notifications"
+	QueueWebhooks      = "webhooks"
+	QueueEmails        = "emails"
+	QueueWatchers      = "watchers"
+	QueueResources     = "resources"
+	QueueStream        = "stream" // prefix of the transient queue of each instance
+)
+
+// ErrNotStarted is returned when publishing before the broker is set up
+var ErrNotStarted = errors.New("message broker not started")
+
+// ErrUnroutable is returned when a published message matched no queue
+var ErrUnroutable = errors.New("message not routed to any queue")
+
+// ErrNacked is returned when the broker did not take charge of a published message
+var ErrNacked = errors.New("message nacked by the broker")
+
+// Message is a message published to a queue, or to an exchange with a
+// routing key. Consumed messages have the queue they were consumed from.
+type Message struct {
+	Queue         string
+	Exchange      string
+	RoutingKey    string
+	Body          []byte
+	ContentType   string
+	MessageId     string
+	CorrelationId string
+	Headers       map[string]interface{}
+}
+
+// Handler processes a consumed message. Returning an error retries the
+// message, returning a Permanent error gives up at once.
+type Handler func(ctx context.Context, msg Message) error
+
+// Publisher publishes messages. Publish returns once the broker took charge
+// of the message, an error when it was refused, could not be routed to a
+// queue or the context ended first.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Subscriber declares the queue of a consumer with its bindings and consumes
+// its messages with the handler until the context is done
+type Subscriber interface {
+	Subscribe(ctx context.Context, consumer Consumer) error
+}
+
+// Broker publishes and consumes messages
+type Broker interface {
+	Publisher
+	Subscriber
+}
+
+var BrokerServices Broker
+
+// PublisherFunc adapts a function to the Publisher interface
+type PublisherFunc func(ctx context.Context, msg Message) error
+
+func (f PublisherFunc) Publish(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error of a message that fails whatever the attempt,
+// e.g. a body that cannot be decoded. The message is not retried.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether the error was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// publish publishes with the broker of the service
+func publish(ctx context.Context, msg Message) error {
+	if BrokerServices == nil {
+		return ErrNotStarted
+	}
+	return BrokerServices.Publish(ctx, msg)
+}
@@ -0,0 +1,67 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Consumer is a queue, with its bindings, consumed by a handler. Each consumed
+// queue has its retries and its dead-letter queue.
+type Consumer struct {
+	Queue
+	Handler Handler
+}
+
+// Consumers lists the queues consumed by the services
+var Consumers = []Consumer{
+	{Queue: Queue{Name: QueueTasks, Bindings: []string{"task.#"}}, Handler: HandleTaskEvent},
+}
+
+// StartConsumers subscribes the consumers, with the bindings configured for
+// their queue. The handlers skip the messages already processed, recorded in
+// ProcessedStoreServices.
+func StartConsumers(ctx context.Context, subscriber Subscriber) error {
+	ttl := DeduplicationTTL()
+	for _, consumer := range Consumers {
+		consumer.Bindings = Bindings(consumer.Queue)
+		if !consumer.Transient {
+			consumer.Handler = Deduplicate(consumer.Name, ProcessedStoreServices, ttl, consumer.Handler)
+		}
+		if err := subscriber.Subscribe(ctx, consumer); err != nil {
+			return err
+		}
+	}
+
+	if ProcessedStoreServices != nil {
+		go cleanupProcessed(ctx, ProcessedStoreServices)
+	}
+
+	return nil
+}
+
+// ConsumedQueues lists the consumed queues with a dead-letter queue, i.e. not
+// transient, in order
+func ConsumedQueues() []string {
+	queues := make([]string, 0, len(Consumers))
+	for _, consumer := range Consumers {
+		if !consumer.Transient {
+			queues = append(queues, consumer.Name)
+		}
+	}
+	sort.Strings(queues)
+
+	return queues
+}
+
+// HandleTaskEvent handles the events of the tasks queue
+func HandleTaskEvent(ctx context.Context, msg Message) error {
+	event, err := DecodeEvent(msg)
+	if err != nil {
+		return Permanent(fmt.Errorf("error decoding event: %w", err))
+	}
+
+	log.Printf("received event %s %s of %s (correlation %s)\n", event.ID, event.Type, event.Subject, event.CorrelationId)
+	return nil
+}
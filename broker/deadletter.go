@@ -0,0 +1,14 @@
+package broker
+
+import (
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// IDeadLetters inspects, replays and purges the dead-letter queues of the consumed queues
+type IDeadLetters interface {
+	InspectDeadLetters(queue string, limit int) ([]models.DeadLetter, error)
+	ReplayDeadLetters(queue string, limit int) (int, error)
+	PurgeDeadLetters(queue string) (int, error)
+}
+
+var DeadLetterServices IDeadLetters
@@ -0,0 +1,160 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// ProcessedStore records the messages processed by the consumers, e.g.
+// repository.IProcessedMessageRepository. ClaimMessage returns one of
+// models.MessageClaimed, models.MessageInProgress and models.MessageProcessed.
+type ProcessedStore interface {
+	ClaimMessage(queue string, messageId string, leaseUntil time.Time) (string, error)
+	CompleteMessage(queue string, messageId string, expiresAt time.Time) error
+	ReleaseMessage(queue string, messageId string) error
+	DeleteExpiredMessages(before time.Time) (int64, error)
+}
+
+var ProcessedStoreServices ProcessedStore
+
+// processingLease is how long a consumer holds a message it processes, a
+// redelivery after it processes the message again
+const processingLease = 5 * time.Minute
+
+// processedCleanupInterval is how often the expired records are deleted
+const processedCleanupInterval = time.Hour
+
+// ErrInProgress is returned for a delivery of a message another consumer is
+// processing, so it is retried later and skipped once processed
+var ErrInProgress = errors.New("message is being processed by another consumer")
+
+// DeduplicationTTL is how long a processed message id is kept,
+// MESSAGE_DEDUP_TTL_HOURS (24 by default)
+func DeduplicationTTL() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("MESSAGE_DEDUP_TTL_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// Deduplicate wraps the handler of a queue so a message id is processed once
+// while its record lasts: duplicates are skipped without error, so they are
+// acked. Messages without id are always handled. Without store the messages
+// are only counted in Stats.
+func Deduplicate(queue string, store ProcessedStore, ttl time.Duration, handler Handler) Handler {
+	return func(ctx context.Context, msg Message) error {
+		if store == nil || msg.MessageId == "" {
+			return countHandled(queue, handler(ctx, msg))
+		}
+
+		claim, err := store.ClaimMessage(queue, msg.MessageId, time.Now().Add(processingLease))
+		if err != nil {
+			return countHandled(queue, err)
+		}
+		switch claim {
+		case models.MessageProcessed:
+			log.Printf("skipping duplicate message %s of %s\n", msg.MessageId, queue)
+			countDuplicate(queue)
+			return nil
+		case models.MessageInProgress:
+			return countHandled(queue, ErrInProgress)
+		}
+
+		if err := handler(ctx, msg); err != nil {
+			if err := store.ReleaseMessage(queue, msg.MessageId); err != nil {
+				log.Printf("error releasing message %s of %s: %s\n", msg.MessageId, queue, err)
+			}
+			return countHandled(queue, err)
+		}
+
+		if err := store.CompleteMessage(queue, msg.MessageId, time.Now().Add(ttl)); err != nil {
+			log.Printf("error recording processed message %s of %s: %s\n", msg.MessageId, queue, err)
+		}
+		return countHandled(queue, nil)
+	}
+}
+
+// cleanupProcessed deletes the expired records of the store until the context is done
+func cleanupProcessed(ctx context.Context, store ProcessedStore) {
+	ticker := time.NewTicker(processedCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := store.DeleteExpiredMessages(time.Now())
+			if err != nil {
+				log.Printf("error deleting expired processed messages: %s\n", err)
+			} else if deleted > 0 {
+				log.Printf("deleted %d expired processed messages\n", deleted)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// consumerStats counts the messages handled by the consumers since the start
+var consumerStats = struct {
+	sync.Mutex
+	queues map[string]*models.ConsumerStats
+}{queues: make(map[string]*models.ConsumerStats)}
+
+func queueStats(queue string) *models.ConsumerStats {
+	stats := consumerStats.queues[queue]
+	if stats == nil {
+		stats = &models.ConsumerStats{Queue: queue}
+		consumerStats.queues[queue] = stats
+	}
+	return stats
+}
+
+// countHandled counts a message processed, or failed, and returns its error
+func countHandled(queue string, err error) error {
+	consumerStats.Lock()
+	defer consumerStats.Unlock()
+
+	if err != nil {
+		queueStats(queue).Failed++
+	} else {
+		queueStats(queue).Processed++
+	}
+	return err
+}
+
+func countDuplicate(queue string) {
+	consumerStats.Lock()
+	defer consumerStats.Unlock()
+
+	queueStats(queue).Duplicates++
+}
+
+// Stats returns the counters of the consumed queues by name, the duplicate
+// rate is the share of duplicates among the deliveries that succeeded
+func Stats() []models.ConsumerStats {
+	consumerStats.Lock()
+	defer consumerStats.Unlock()
+
+	stats := make([]models.ConsumerStats, 0, len(consumerStats.queues))
+	for _, queue := range consumerStats.queues {
+		s := *queue
+		if total := s.Processed + s.Duplicates; total > 0 {
+			s.DuplicateRate = float64(s.Duplicates) / float64(total)
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Queue < stats[j].Queue
+	})
+
+	return stats
+}
@@ -0,0 +1,127 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func queueStats(queue string) models.ConsumerStats {
+	for _, stats := range broker.Stats() {
+		if stats.Queue == queue {
+			return stats
+		}
+	}
+	return models.ConsumerStats{Queue: queue}
+}
+
+func TestDeduplicate(t *testing.T) {
+	assert := assert.New(t)
+
+	msg := broker.Message{Queue: "tasks", MessageId: "message-1"}
+
+	t.Run("Success: first delivery is handled and recorded", func(t *testing.T) {
+		iProcessedMock := new(taskMock.IProcessedMessageRepository)
+		iProcessedMock.On("ClaimMessage", "dedup.first", "message-1", tmock.Anything).Return(models.MessageClaimed, nil)
+		iProcessedMock.On("CompleteMessage", "dedup.first", "message-1", tmock.Anything).Return(nil)
+
+		handled := 0
+		handler := broker.Deduplicate("dedup.first", iProcessedMock, time.Hour, func(ctx context.Context, msg broker.Message) error {
+			handled++
+			return nil
+		})
+
+		start := time.Now()
+		err := handler(context.Background(), msg)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(1, handled)
+		iProcessedMock.AssertCalled(t, "CompleteMessage", "dedup.first", "message-1", tmock.MatchedBy(func(expiresAt time.Time) bool {
+			return !expiresAt.Before(start.Add(time.Hour))
+		}))
+		assert.Equal(uint64(1), queueStats("dedup.first").Processed)
+	})
+
+	t.Run("Success: duplicate is skipped and acked", func(t *testing.T) {
+		iProcessedMock := new(taskMock.IProcessedMessageRepository)
+		iProcessedMock.On("ClaimMessage", "dedup.duplicate", "message-1", tmock.Anything).Return(models.MessageClaimed, nil).Once()
+		iProcessedMock.On("CompleteMessage", "dedup.duplicate", "message-1", tmock.Anything).Return(nil)
+		iProcessedMock.On("ClaimMessage", "dedup.duplicate", "message-1", tmock.Anything).Return(models.MessageProcessed, nil)
+
+		handled := 0
+		handler := broker.Deduplicate("dedup.duplicate", iProcessedMock, time.Hour, func(ctx context.Context, msg broker.Message) error {
+			handled++
+			return nil
+		})
+
+		handler(context.Background(), msg)
+		err := handler(context.Background(), msg)
+
+		stats := queueStats("dedup.duplicate")
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(1, handled)
+		assert.Equal(uint64(1), stats.Processed)
+		assert.Equal(uint64(1), stats.Duplicates)
+		assert.Equal(0.5, stats.DuplicateRate)
+	})
+
+	t.Run("Failed: message processed by another consumer is retried", func(t *testing.T) {
+		iProcessedMock := new(taskMock.IProcessedMessageRepository)
+		iProcessedMock.On("ClaimMessage", "dedup.busy", "message-1", tmock.Anything).Return(models.MessageInProgress, nil)
+
+		handler := broker.Deduplicate("dedup.busy", iProcessedMock, time.Hour, func(ctx context.Context, msg broker.Message) error {
+			t.Fatal("handler called")
+			return nil
+		})
+
+		err := handler(context.Background(), msg)
+
+		// asserts
+		assert.ErrorIs(err, broker.ErrInProgress)
+		assert.False(broker.IsPermanent(err))
+	})
+
+	t.Run("Failed: failed message is released to be processed again", func(t *testing.T) {
+		iProcessedMock := new(taskMock.IProcessedMessageRepository)
+		iProcessedMock.On("ClaimMessage", "dedup.failed", "message-1", tmock.Anything).Return(models.MessageClaimed, nil)
+		iProcessedMock.On("ReleaseMessage", "dedup.failed", "message-1").Return(nil)
+
+		handler := broker.Deduplicate("dedup.failed", iProcessedMock, time.Hour, func(ctx context.Context, msg broker.Message) error {
+			return errors.New("database is down")
+		})
+
+		err := handler(context.Background(), msg)
+
+		// asserts
+		assert.EqualError(err, "database is down")
+		iProcessedMock.AssertCalled(t, "ReleaseMessage", "dedup.failed", "message-1")
+		iProcessedMock.AssertNotCalled(t, "CompleteMessage", tmock.Anything, tmock.Anything, tmock.Anything)
+		assert.Equal(uint64(1), queueStats("dedup.failed").Failed)
+	})
+
+	t.Run("Success: message without id is handled without the store", func(t *testing.T) {
+		iProcessedMock := new(taskMock.IProcessedMessageRepository)
+
+		handled := 0
+		handler := broker.Deduplicate("dedup.anonymous", iProcessedMock, time.Hour, func(ctx context.Context, msg broker.Message) error {
+			handled++
+			return nil
+		})
+
+		handler(context.Background(), broker.Message{Queue: "tasks"})
+
+		// asserts
+		assert.Equal(1, handled)
+		iProcessedMock.AssertNotCalled(t, "ClaimMessage", tmock.Anything, tmock.Anything, tmock.Anything)
+	})
+}
@@ -0,0 +1,213 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// memoryQueueSize is the number of messages a consumed queue of Memory buffers
+const memoryQueueSize = 256
+
+// Memory is an in-process broker: the consumed queues are channels and every
+// published message is kept, so tests can assert what was published. Messages
+// published to an exchange are routed to Queues and the subscribed queues with
+// a matching binding. Failed messages are retried at once, up to MaxAttempts,
+// then dead-lettered, except in transient queues.
+type Memory struct {
+	MaxAttempts int
+
+	mu          sync.Mutex
+	published   []Message
+	queues      map[string]chan Message
+	bindings    []Queue
+	deadLetters map[string][]memoryDeadLetter
+}
+
+// memoryDeadLetter keeps the original message to replay it
+type memoryDeadLetter struct {
+	models.DeadLetter
+	msg Message
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		MaxAttempts: 3,
+		queues:      make(map[string]chan Message),
+		deadLetters: make(map[string][]memoryDeadLetter),
+	}
+}
+
+// Publish keeps the message once per queue it is routed to, with the queue
+// set, and sends it to the queues that are consumed. A message routed to no
+// queue is kept too and returns ErrUnroutable.
+func (m *Memory) Publish(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	names := []string{msg.Queue}
+	if msg.Exchange != "" {
+		names = Route(append(append([]Queue{}, Queues...), m.bindings...), msg.RoutingKey)
+	}
+	if len(names) == 0 {
+		m.published = append(m.published, msg)
+		m.mu.Unlock()
+		return ErrUnroutable
+	}
+
+	routed := make([]Message, 0, len(names))
+	queues := make([]chan Message, 0, len(names))
+	for _, name := range names {
+		queued := msg
+		queued.Queue = name
+		m.published = append(m.published, queued)
+		routed = append(routed, queued)
+		queues = append(queues, m.queues[name])
+	}
+	m.mu.Unlock()
+
+	for i, queue := range queues {
+		if queue == nil {
+			continue
+		}
+		select {
+		case queue <- routed[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Subscribe consumes the queue in a goroutine until the context is done. The
+// subscribers of a queue share its messages.
+func (m *Memory) Subscribe(ctx context.Context, consumer Consumer) error {
+	queue := consumer.Name
+
+	m.mu.Lock()
+	messages := m.queues[queue]
+	if messages == nil {
+		messages = make(chan Message, memoryQueueSize)
+		m.queues[queue] = messages
+	}
+	m.bindings = append(m.bindings, consumer.Queue)
+	m.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case msg := <-messages:
+				m.deliver(ctx, consumer.Queue, consumer.Handler, msg)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (m *Memory) deliver(ctx context.Context, consumed Queue, handler Handler, msg Message) {
+	queue := consumed.Name
+	if consumed.Transient {
+		if err := handler(ctx, msg); err != nil {
+			log.Printf("dropping message %s of %s: %s\n", msg.MessageId, queue, err)
+		}
+		return
+	}
+
+	var err error
+	attempts := 0
+	for attempts < m.MaxAttempts || attempts == 0 {
+		attempts++
+		if err = handler(ctx, msg); err == nil || IsPermanent(err) {
+			break
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	log.Printf("dead-lettering message %s of %s after %d attempts: %s\n", msg.MessageId, queue, attempts, err)
+	failedAt := time.Now()
+	body := json.RawMessage(msg.Body)
+	if !json.Valid(msg.Body) {
+		body, _ = json.Marshal(string(msg.Body))
+	}
+
+	m.mu.Lock()
+	m.deadLetters[queue] = append(m.deadLetters[queue], memoryDeadLetter{
+		DeadLetter: models.DeadLetter{
+			MessageId:     msg.MessageId,
+			CorrelationId: msg.CorrelationId,
+			Queue:         queue,
+			ContentType:   msg.ContentType,
+			Attempts:      attempts,
+			LastError:     err.Error(),
+			FailedAt:      &failedAt,
+			Body:          body,
+		},
+		msg: msg,
+	})
+	m.mu.Unlock()
+}
+
+// Published returns the messages published to the queue, all of them when queue is empty
+func (m *Memory) Published(queue string) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := []Message{}
+	for _, msg := range m.published {
+		if queue == "" || msg.Queue == queue {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func (m *Memory) InspectDeadLetters(queue string, limit int) ([]models.DeadLetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deadLetters := []models.DeadLetter{}
+	for _, deadLetter := range m.deadLetters[queue] {
+		if len(deadLetters) == limit {
+			break
+		}
+		deadLetters = append(deadLetters, deadLetter.DeadLetter)
+	}
+	return deadLetters, nil
+}
+
+func (m *Memory) ReplayDeadLetters(queue string, limit int) (int, error) {
+	m.mu.Lock()
+	deadLetters := m.deadLetters[queue]
+	if len(deadLetters) > limit {
+		deadLetters = deadLetters[:limit]
+	}
+	m.deadLetters[queue] = m.deadLetters[queue][len(deadLetters):]
+	m.mu.Unlock()
+
+	for i, deadLetter := range deadLetters {
+		msg := deadLetter.msg
+		msg.Exchange, msg.RoutingKey = "", ""
+		if err := m.Publish(context.Background(), msg); err != nil {
+			return i, err
+		}
+	}
+
+	return len(deadLetters), nil
+}
+
+func (m *Memory) PurgeDeadLetters(queue string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := len(m.deadLetters[queue])
+	delete(m.deadLetters, queue)
+	return purged, nil
+}
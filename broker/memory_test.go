@@ -0,0 +1,177 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: published messages are kept", func(t *testing.T) {
+		memory := broker.NewMemory()
+
+		err := memory.Publish(context.Background(), broker.Message{Queue: broker.QueueTasks, Body: []byte(`{}`)})
+		memory.Publish(context.Background(), broker.Message{Queue: broker.QueueNotifications, Body: []byte(`{}`)})
+
+		// asserts
+		assert.Nil(err)
+		assert.Len(memory.Published(broker.QueueTasks), 1)
+		assert.Len(memory.Published(""), 2)
+	})
+
+	t.Run("Success: subscriber receives the messages of its queue", func(t *testing.T) {
+		memory := broker.NewMemory()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		received := make(chan broker.Message, 1)
+		memory.Subscribe(ctx, broker.Consumer{Queue: broker.Queue{Name: broker.QueueTasks}, Handler: func(ctx context.Context, msg broker.Message) error {
+			received <- msg
+			return nil
+		}})
+
+		memory.Publish(ctx, broker.Message{Queue: broker.QueueTasks, MessageId: "message-1"})
+
+		// asserts
+		select {
+		case msg := <-received:
+			assert.Equal("message-1", msg.MessageId)
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	})
+
+	t.Run("Failed: message is dead-lettered after the last attempt", func(t *testing.T) {
+		memory := broker.NewMemory()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var attempts int32
+		memory.Subscribe(ctx, broker.Consumer{Queue: broker.Queue{Name: broker.QueueTasks}, Handler: func(ctx context.Context, msg broker.Message) error {
+			atomic.AddInt32(&attempts, 1)
+			return errors.New("database is down")
+		}})
+
+		memory.Publish(ctx, broker.Message{Queue: broker.QueueTasks, MessageId: "message-1", Body: []byte(`{"id":"1"}`)})
+
+		// asserts
+		assert.Eventually(func() bool {
+			deadLetters, _ := memory.InspectDeadLetters(broker.QueueTasks, 10)
+			return len(deadLetters) == 1
+		}, time.Second, 10*time.Millisecond)
+		deadLetters, _ := memory.InspectDeadLetters(broker.QueueTasks, 10)
+		assert.Equal(int32(3), atomic.LoadInt32(&attempts))
+		assert.Equal(3, deadLetters[0].Attempts)
+		assert.Equal("database is down", deadLetters[0].LastError)
+		assert.JSONEq(`{"id":"1"}`, string(deadLetters[0].Body))
+	})
+
+	t.Run("Failed: permanent error is dead-lettered at once", func(t *testing.T) {
+		memory := broker.NewMemory()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		memory.Subscribe(ctx, broker.Consumer{Queue: broker.Queue{Name: broker.QueueTasks}, Handler: broker.HandleTaskEvent})
+		memory.Publish(ctx, broker.Message{Queue: broker.QueueTasks, Body: []byte("The tech performed the task")})
+
+		// asserts
+		assert.Eventually(func() bool {
+			deadLetters, _ := memory.InspectDeadLetters(broker.QueueTasks, 10)
+			return len(deadLetters) == 1 && deadLetters[0].Attempts == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Failed: message of a transient queue is dropped", func(t *testing.T) {
+		memory := broker.NewMemory()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var attempts int32
+		memory.Subscribe(ctx, broker.Consumer{Queue: broker.Queue{Name: broker.QueueStream + ".1", Transient: true}, Handler: func(ctx context.Context, msg broker.Message) error {
+			atomic.AddInt32(&attempts, 1)
+			return errors.New("client gone")
+		}})
+
+		memory.Publish(ctx, broker.Message{Queue: broker.QueueStream + ".1", MessageId: "message-1"})
+
+		// asserts
+		assert.Eventually(func() bool {
+			return atomic.LoadInt32(&attempts) == 1
+		}, time.Second, 10*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		deadLetters, _ := memory.InspectDeadLetters(broker.QueueStream+".1", 10)
+		assert.Equal(int32(1), atomic.LoadInt32(&attempts))
+		assert.Empty(deadLetters)
+	})
+
+	t.Run("Success: replay and purge dead letters", func(t *testing.T) {
+		memory := broker.NewMemory()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var fail int32 = 1
+		memory.Subscribe(ctx, broker.Consumer{Queue: broker.Queue{Name: broker.QueueTasks}, Handler: func(ctx context.Context, msg broker.Message) error {
+			if atomic.LoadInt32(&fail) == 1 {
+				return errors.New("database is down")
+			}
+			return nil
+		}})
+		memory.Publish(ctx, broker.Message{Queue: broker.QueueTasks, MessageId: "message-1"})
+		memory.Publish(ctx, broker.Message{Queue: broker.QueueTasks, MessageId: "message-2"})
+		assert.Eventually(func() bool {
+			deadLetters, _ := memory.InspectDeadLetters(broker.QueueTasks, 10)
+			return len(deadLetters) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		atomic.StoreInt32(&fail, 0)
+		replayed, err := memory.ReplayDeadLetters(broker.QueueTasks, 1)
+		purged, _ := memory.PurgeDeadLetters(broker.QueueTasks)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(1, replayed)
+		assert.Equal(1, purged)
+		assert.Equal("message-1", memory.Published(broker.QueueTasks)[2].MessageId)
+	})
+}
+
+func TestPublishEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: events are published to the queue of their aggregate", func(t *testing.T) {
+		memory := broker.NewMemory()
+		broker.BrokerServices = memory
+
+		event, _ := events.New(events.UserCreated, events.UserSubject(4), "corr-1", events.UserCreatedData{User: events.User{ID: 4}})
+		err := broker.PublishEvent(context.Background(), event)
+
+		published := memory.Published(broker.QueueUsers)
+		decoded, _ := broker.DecodeEvent(published[0])
+
+		// asserts
+		assert.Nil(err)
+		assert.Len(published, 1)
+		assert.Equal(event.ID, published[0].MessageId)
+		assert.Equal("corr-1", published[0].CorrelationId)
+		assert.Equal(events.ContentType, published[0].ContentType)
+		assert.Equal(events.UserCreated, decoded.Type)
+	})
+
+	t.Run("Failed: broker not started", func(t *testing.T) {
+		broker.BrokerServices = nil
+
+		event, _ := events.New(events.UserCreated, events.UserSubject(4), "", events.UserCreatedData{})
+		err := broker.PublishEvent(context.Background(), event)
+
+		// asserts
+		assert.ErrorIs(err, broker.ErrNotStarted)
+	})
+}
@@ -0,0 +1,68 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// EventMessage returns the message of a domain event, published to
+// EventsExchange with the routing key of the event
+func EventMessage(event events.Event) (Message, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Exchange:      EventsExchange,
+		RoutingKey:    EventRoutingKey(event),
+		Body:          body,
+		ContentType:   events.ContentType,
+		MessageId:     event.ID,
+		CorrelationId: event.CorrelationId,
+		Headers:       map[string]interface{}{"type": event.Type, "schemaversion": int32(event.SchemaVersion)},
+	}, nil
+}
+
+// PublishEvent publishes a domain event with the broker of the service
+func PublishEvent(ctx context.Context, event events.Event) error {
+	msg, err := EventMessage(event)
+	if err != nil {
+		return err
+	}
+
+	return publish(ctx, msg)
+}
+
+// PublishNotification publishes a watcher notification as JSON to the notifications queue
+func PublishNotification(ctx context.Context, notification models.Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	messageId, err := events.NewId()
+	if err != nil {
+		return err
+	}
+
+	return publish(ctx, Message{
+		Queue:       QueueNotifications,
+		Body:        body,
+		ContentType: "application/json",
+		MessageId:   messageId,
+	})
+}
+
+// DecodeEvent decodes the domain event of a message
+func DecodeEvent(msg Message) (events.Event, error) {
+	var event events.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		return events.Event{}, err
+	}
+
+	return event, nil
+}
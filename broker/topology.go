@@ -0,0 +1,99 @@
+package broker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hugohenrick/gtasks/events"
+)
+
+// EventsExchange is the durable topic exchange the domain events are published to
+const EventsExchange = "gtasks.events"
+
+// Queue is a durable queue with the patterns of the routing keys of
+// EventsExchange bound to it. The routing key of an event is its type followed
+// by its organization, e.g. task.executed.3, so task.executed.* binds the
+// completions of every organization and task.# every task event.
+//
+// A Transient queue belongs to a single instance: it is deleted when the
+// instance disconnects and its messages are neither deduplicated, retried nor
+// dead-lettered, a failed message is dropped. Every instance binding its own
+// transient queue gets a copy of the events.
+type Queue struct {
+	Name      string
+	Bindings  []string
+	Transient bool
+}
+
+// Queues lists the queues declared besides the consumed ones. The resources
+// queue keeps the events of the entities other than the tasks and users for
+// the consumers outside the service.
+var Queues = []Queue{
+	{Name: QueueUsers, Bindings: []string{"user.#"}},
+	{Name: QueueResources, Bindings: []string{"customer.#", "site.#", "project.#", "template.#", "custom_field.#", "watcher.#"}},
+	{Name: QueueNotifications},
+}
+
+// EventRoutingKey returns the routing key of a domain event, e.g. task.executed.3
+func EventRoutingKey(event events.Event) string {
+	return fmt.Sprintf("%s.%d", event.Type, event.OrganizationId)
+}
+
+// Bindings returns the bindings of the queue, replaced by the comma separated
+// patterns of BROKER_BINDINGS_<QUEUE> when set, e.g. BROKER_BINDINGS_TASKS
+func Bindings(queue Queue) []string {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(queue.Name))
+	value, ok := os.LookupEnv("BROKER_BINDINGS_" + name)
+	if !ok {
+		return queue.Bindings
+	}
+
+	bindings := []string{}
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			bindings = append(bindings, pattern)
+		}
+	}
+	return bindings
+}
+
+// Route returns the names of the queues with a binding matching the routing key
+func Route(queues []Queue, routingKey string) []string {
+	names := []string{}
+	for _, queue := range queues {
+		for _, pattern := range queue.Bindings {
+			if MatchTopic(pattern, routingKey) {
+				names = append(names, queue.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// MatchTopic reports whether the routing key matches the pattern of a topic
+// binding: * matches exactly one word and # zero or more words
+func MatchTopic(pattern string, routingKey string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+}
+
+func matchWords(pattern []string, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if matchWords(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && matchWords(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && matchWords(pattern[1:], words[1:])
+	}
+}
@@ -0,0 +1,109 @@
+package broker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchTopic(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		pattern    string
+		routingKey string
+		match      bool
+	}{
+		{"task.#", "task.executed.1", true},
+		{"task.#", "task", true},
+		{"task.executed.*", "task.executed.1", true},
+		{"task.executed.*", "task.created.1", false},
+		{"task.executed.*", "task.executed", false},
+		{"*.*.3", "user.created.3", true},
+		{"#.3", "task.executed.3", true},
+		{"#", "task.executed.3", true},
+		{"user.#", "task.executed.3", false},
+	}
+
+	// asserts
+	for _, c := range cases {
+		assert.Equal(c.match, broker.MatchTopic(c.pattern, c.routingKey), "%s %s", c.pattern, c.routingKey)
+	}
+}
+
+func TestBindings(t *testing.T) {
+	assert := assert.New(t)
+
+	queue := broker.Queue{Name: broker.QueueTasks, Bindings: []string{"task.#"}}
+
+	t.Run("Success: bindings of the declaration", func(t *testing.T) {
+		// asserts
+		assert.Equal([]string{"task.#"}, broker.Bindings(queue))
+	})
+
+	t.Run("Success: bindings of the environment", func(t *testing.T) {
+		t.Setenv("BROKER_BINDINGS_TASKS", "task.executed.*, task.created.3")
+
+		// asserts
+		assert.Equal([]string{"task.executed.*", "task.created.3"}, broker.Bindings(queue))
+	})
+}
+
+func TestRoute(t *testing.T) {
+	assert := assert.New(t)
+
+	eventTypes := []string{
+		events.UserUpdated, events.UserDeleted, events.WatcherAdded, events.WatcherRemoved,
+		events.CustomerCreated, events.SiteUpdated, events.ProjectDeleted, events.TemplateUpdated, events.CustomFieldDeleted,
+	}
+
+	// asserts
+	for _, eventType := range eventTypes {
+		assert.NotEmpty(broker.Route(broker.Queues, eventType+".1"), eventType)
+	}
+	assert.Equal([]string{broker.QueueResources}, broker.Route(broker.Queues, events.CustomFieldCreated+".1"))
+}
+
+func TestEventRouting(t *testing.T) {
+	assert := assert.New(t)
+
+	memory := broker.NewMemory()
+	broker.BrokerServices = memory
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan broker.Message, 2)
+	memory.Subscribe(ctx, broker.Consumer{
+		Queue: broker.Queue{Name: "tasks.executed", Bindings: []string{"task.executed.*"}},
+		Handler: func(ctx context.Context, msg broker.Message) error {
+			received <- msg
+			return nil
+		},
+	})
+
+	created, _ := events.New(events.TaskCreated, events.TaskSubject(1), "", events.TaskCreatedData{})
+	created.OrganizationId = 3
+	executed, _ := events.New(events.TaskExecuted, events.TaskSubject(1), "", events.TaskExecutedData{})
+	executed.OrganizationId = 3
+	errCreated := broker.PublishEvent(ctx, created)
+	errExecuted := broker.PublishEvent(ctx, executed)
+
+	// asserts
+	assert.ErrorIs(errCreated, broker.ErrUnroutable)
+	assert.Nil(errExecuted)
+	select {
+	case msg := <-received:
+		assert.Equal(executed.ID, msg.MessageId)
+		assert.Equal(broker.EventsExchange, msg.Exchange)
+		assert.Equal("task.executed.3", msg.RoutingKey)
+		assert.Equal("tasks.executed", msg.Queue)
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+	assert.Len(received, 0)
+	assert.Len(memory.Published(""), 2)
+}
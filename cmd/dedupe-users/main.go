@@ -0,0 +1,74 @@
+// Command dedupe-users reports the user accounts sharing the same email and,
+// with -merge, merges each group into a single account and creates the unique
+// index on user.email. Accounts of different organizations sharing an email
+// are only reported, they must be resolved by hand before the index is created.
+//
+//	go run ./cmd/dedupe-users          # report only
+//	go run ./cmd/dedupe-users -merge   # merge the duplicated accounts
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/hugohenrick/gtasks/database"
+)
+
+func main() {
+	merge := flag.Bool("merge", false, "merge the duplicated accounts instead of only reporting them")
+	flag.Parse()
+
+	database.Conn()
+
+	duplicates, err := database.FindDuplicateEmails(database.DB)
+	if err != nil {
+		log.Fatalf("error finding duplicated emails: %s", err)
+	}
+
+	if len(duplicates) == 0 {
+		fmt.Println("No duplicated emails found")
+	}
+
+	refused := 0
+	for _, duplicate := range duplicates {
+		keep := database.CanonicalUser(duplicate.Users)
+
+		fmt.Printf("%s: %d users, keeping #%d\n", duplicate.Email, len(duplicate.Users), keep.ID)
+		for _, user := range duplicate.Users {
+			fmt.Printf("  #%d %q active=%t verified=%t manager=%t created_at=%s\n",
+				user.ID, user.Email, user.Active, user.EmailVerified, user.IsManager, user.CreatedAt.Format("2006-01-02"))
+		}
+
+		if database.SpansOrganizations(duplicate) {
+			fmt.Println("  not merged: the accounts belong to different organizations, resolve them by hand")
+			refused++
+			continue
+		}
+
+		if !*merge {
+			continue
+		}
+
+		if _, err := database.MergeDuplicateUsers(database.DB, duplicate); err != nil {
+			log.Fatalf("error merging %s: %s", duplicate.Email, err)
+		}
+		fmt.Printf("  merged into #%d\n", keep.ID)
+	}
+
+	if !*merge {
+		if len(duplicates) > 0 {
+			fmt.Println("Run with -merge to merge the accounts")
+		}
+		return
+	}
+
+	if refused > 0 {
+		log.Fatalf("%d emails are shared across organizations, the unique index on user.email is not created", refused)
+	}
+
+	if err := database.MigrateUserEmails(database.DB); err != nil {
+		log.Fatalf("error creating the email unique index: %s", err)
+	}
+	fmt.Println("Unique index on user.email created")
+}
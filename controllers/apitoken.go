@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// CreateApiToken creates an api token for the authenticated user. The token is
+// only returned in this response, afterwards only its prefix is shown.
+func CreateApiToken(c *gin.Context) {
+	var input models.ApiTokenCreate
+	if err := c.ShouldBindWith(&input, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ApiTokenNameRequired))
+		return
+	}
+
+	scopes := []string{}
+	for _, scope := range input.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !auth.ValidApiTokenScope(scope) {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.ApiTokenScopeInvalid, scope))
+			return
+		}
+		if !utils.ContainsString(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ApiTokenScopeRequired))
+		return
+	}
+
+	userId, ok := c.Get("userId")
+	if !ok || userId == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	tokenString, prefix, hash, err := auth.NewApiToken()
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	token := models.ApiToken{
+		UserId:    userId.(uint32),
+		Name:      input.Name,
+		Prefix:    prefix,
+		TokenHash: hash,
+		Scopes:    strings.Join(scopes, ","),
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, int(input.ExpiresInDays))
+		token.ExpiresAt = &expiresAt
+	}
+
+	token, err = repository.ApiTokenRepositoryServices.CreateApiToken(token)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusCreated, gin.H{
+		"token":     tokenString,
+		"api_token": serializers.NewApiTokenResponse(token),
+	})
+}
+
+// GetApiTokens lists the api tokens of the authenticated user that were not revoked
+func GetApiTokens(c *gin.Context) {
+	userId, ok := c.Get("userId")
+	if !ok || userId == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	tokens, err := repository.ApiTokenRepositoryServices.FindApiTokensByUser(userId.(uint32))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewApiTokenListResponse(tokens))
+}
+
+// RevokeApiToken revokes an api token of the authenticated user
+func RevokeApiToken(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ApiTokenIdRequired))
+		return
+	}
+
+	userId, ok := c.Get("userId")
+	if !ok || userId == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	if _, err := repository.ApiTokenRepositoryServices.RevokeApiToken(id, userId.(uint32)); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
@@ -0,0 +1,186 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/auth"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/hugohenrick/gtasks/utils"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func TestCreateApiToken(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success: token returned once and stored hashed", func(t *testing.T) {
+		iApiTokenMock := new(taskMock.IApiTokenRepository)
+		iApiTokenMock.On("CreateApiToken", tmock.Anything).Return(func(token models.ApiToken) models.ApiToken {
+			token.ID = 1
+			return token
+		}, nil)
+		repository.ApiTokenRepositoryServices = iApiTokenMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/tokens", bytes.NewBufferString(`{"name":"ERP","scopes":["task:write","task:write","project:read"],"expires_in_days":90}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		var body struct {
+			Token    string `json:"token"`
+			ApiToken struct {
+				Prefix    string   `json:"prefix"`
+				Scopes    []string `json:"scopes"`
+				ExpiresAt string   `json:"expires_at"`
+			} `json:"api_token"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		assert.True(auth.IsApiToken(body.Token))
+		assert.Equal(body.Token[:12], body.ApiToken.Prefix)
+		assert.Equal([]string{"task:write", "project:read"}, body.ApiToken.Scopes)
+		assert.NotEmpty(body.ApiToken.ExpiresAt)
+		iApiTokenMock.AssertCalled(t, "CreateApiToken", tmock.MatchedBy(func(token models.ApiToken) bool {
+			return token.UserId == 1 && token.TokenHash == utils.HashToken(body.Token)
+		}))
+	})
+
+	t.Run("Failed: unknown scope", func(t *testing.T) {
+		expectMsgError := `{"error":"invalid api token scope: billing:read"}`
+
+		iApiTokenMock := new(taskMock.IApiTokenRepository)
+		repository.ApiTokenRepositoryServices = iApiTokenMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/tokens", bytes.NewBufferString(`{"name":"ERP","scopes":["billing:read"]}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iApiTokenMock.AssertNotCalled(t, "CreateApiToken", tmock.Anything)
+	})
+}
+
+func TestGetApiTokens(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	tokens := []models.ApiToken{
+		{ID: 1, UserId: 1, Name: "ERP", Prefix: "gtk_abcdefgh", TokenHash: "secret-hash", Scopes: "task:read"},
+	}
+
+	iApiTokenMock := new(taskMock.IApiTokenRepository)
+	iApiTokenMock.On("FindApiTokensByUser", uint32(1)).Return(tokens, nil)
+	repository.ApiTokenRepositoryServices = iApiTokenMock
+
+	w := httptest.NewRecorder()
+	c, router := gin.CreateTestContext(w)
+	router.Use(func(c *gin.Context) {
+		c.Set("isManager", false)
+		c.Set("userId", uint32(1))
+	})
+
+	routes.AddUserRoutes(router)
+
+	// creating a request to send on endpoint call
+	c.Request, _ = http.NewRequest(http.MethodGet, "/user/me/tokens", nil)
+
+	// endpoint call
+	router.ServeHTTP(w, c.Request)
+
+	// asserts
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Contains(w.Body.String(), `"prefix":"gtk_abcdefgh"`)
+	assert.Contains(w.Body.String(), `"scopes":["task:read"]`)
+	assert.NotContains(w.Body.String(), "secret-hash")
+}
+
+func TestRevokeApiToken(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		iApiTokenMock := new(taskMock.IApiTokenRepository)
+		iApiTokenMock.On("RevokeApiToken", "1", uint32(1)).Return(models.ApiToken{ID: 1}, nil)
+		repository.ApiTokenRepositoryServices = iApiTokenMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/user/me/tokens/1", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+	})
+
+	t.Run("Failed: token of another user", func(t *testing.T) {
+		expectMsgError := `{"error":"api token not found"}`
+
+		iApiTokenMock := new(taskMock.IApiTokenRepository)
+		iApiTokenMock.On("RevokeApiToken", "2", uint32(1)).Return(models.ApiToken{}, errors.New(utils.ApiTokenNotFound))
+		repository.ApiTokenRepositoryServices = iApiTokenMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/user/me/tokens/2", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+}
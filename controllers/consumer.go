@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// GetConsumerStats returns the counters of the consumed queues since the start
+// of the instance: processed, failed and duplicate messages
+func GetConsumerStats(c *gin.Context) {
+	if !isAdmin(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, broker.Stats())
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+func GetCustomers(c *gin.Context) {
+	customers, err := repository.CustomerRepositoryServices.FindCustomers(organizationIdFromContext(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, customers)
+}
+
+func GetCustomerById(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerIdRequired))
+		return
+	}
+
+	customer, err := repository.CustomerRepositoryServices.FindCustomerById(id)
+	if err != nil || customer.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerNotFound))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, customer)
+}
+
+func CreateCustomer(c *gin.Context) {
+	var customer models.Customer
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	if err := c.ShouldBindWith(&customer, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	if customer.Name == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerNameRequired))
+		return
+	}
+
+	customer.ID = 0
+	customer.OrganizationId = organizationIdFromContext(c)
+
+	customer, err := repository.CustomerRepositoryServices.CreateCustomer(customer, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, customer)
+}
+
+func UpdateCustomer(c *gin.Context) {
+	var customer models.Customer
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&customer, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	if customer.Name == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerNameRequired))
+		return
+	}
+
+	current, err := repository.CustomerRepositoryServices.FindCustomerById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerNotFound))
+		return
+	}
+
+	customer, err = repository.CustomerRepositoryServices.UpdateCustomer(id, customer, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, customer)
+}
+
+func DeleteCustomer(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerIdRequired))
+		return
+	}
+
+	current, err := repository.CustomerRepositoryServices.FindCustomerById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerNotFound))
+		return
+	}
+
+	_, err = repository.CustomerRepositoryServices.DeleteCustomer(id, eventMeta(c))
+	if errors.Is(err, utils.ErrCustomerHasSites) {
+		utils.SendJSONError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+func GetCustomFields(c *gin.Context) {
+	fields, err := repository.CustomFieldRepositoryServices.FindCustomFields(organizationIdFromContext(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, fields)
+}
+
+func CreateCustomField(c *gin.Context) {
+	var field models.CustomFieldSchema
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	if err := c.ShouldBindWith(&field, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	field.Key = strings.TrimSpace(field.Key)
+	field.OrganizationId = organizationIdFromContext(c)
+
+	if err := utils.ValidateCustomFieldSchema(field); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	field, err := repository.CustomFieldRepositoryServices.CreateCustomField(field, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, field)
+}
+
+func UpdateCustomField(c *gin.Context) {
+	var field models.CustomFieldSchema
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomFieldIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&field, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	current, err := repository.CustomFieldRepositoryServices.FindCustomFieldById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomFieldNotFound))
+		return
+	}
+
+	// the key identifies the values already stored on tasks, so it cannot change
+	field.Key = current.Key
+
+	if err := utils.ValidateCustomFieldSchema(field); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	field, err = repository.CustomFieldRepositoryServices.UpdateCustomField(id, field, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, field)
+}
+
+func DeleteCustomField(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomFieldIdRequired))
+		return
+	}
+
+	current, err := repository.CustomFieldRepositoryServices.FindCustomFieldById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomFieldNotFound))
+		return
+	}
+
+	_, err = repository.CustomFieldRepositoryServices.DeleteCustomField(id, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// defaultDeadLetterLimit is the number of dead letters inspected or replayed by default
+const defaultDeadLetterLimit = 20
+
+// GetDeadLetters lists the messages of the dead-letter queue of a queue without removing them
+func GetDeadLetters(c *gin.Context) {
+	queue, ok := deadLetterQueue(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeadLetterLimit
+	if value, err := queryUint32(c, "limit"); err != nil || value > 100 {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.DeadLetterLimitInvalid))
+		return
+	} else if value > 0 {
+		limit = int(value)
+	}
+
+	deadLetters, err := broker.DeadLetterServices.InspectDeadLetters(queue, limit)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadGateway, err)
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, deadLetters)
+}
+
+// ReplayDeadLetters publishes dead-lettered messages to their queue again
+func ReplayDeadLetters(c *gin.Context) {
+	var replay models.DeadLetterReplay
+
+	queue, ok := deadLetterQueue(c)
+	if !ok {
+		return
+	}
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindWith(&replay, binding.JSON); err != nil {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+			return
+		}
+	}
+
+	if replay.Limit == 0 {
+		replay.Limit = defaultDeadLetterLimit
+	}
+	if replay.Limit < 0 || replay.Limit > 100 {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.DeadLetterLimitInvalid))
+		return
+	}
+
+	replayed, err := broker.DeadLetterServices.ReplayDeadLetters(queue, replay.Limit)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadGateway, fmt.Errorf("replayed %d messages: %v", replayed, err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{"replayed": replayed})
+}
+
+// PurgeDeadLetters deletes the messages of the dead-letter queue of a queue
+func PurgeDeadLetters(c *gin.Context) {
+	queue, ok := deadLetterQueue(c)
+	if !ok {
+		return
+	}
+
+	purged, err := broker.DeadLetterServices.PurgeDeadLetters(queue)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadGateway, err)
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{"purged": purged})
+}
+
+// deadLetterQueue checks the access of the administrator and returns the
+// consumed queue of the request
+func deadLetterQueue(c *gin.Context) (string, bool) {
+	if !isAdmin(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return "", false
+	}
+
+	queue := strings.TrimSpace(c.Param("queue"))
+	if !utils.ContainsString(broker.ConsumedQueues(), queue) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.DeadLetterQueueInvalid))
+		return "", false
+	}
+
+	return queue, true
+}
@@ -0,0 +1,268 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/broker"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func adminRouter(w *httptest.ResponseRecorder, isManager bool, organizationId uint32) (*gin.Context, *gin.Engine) {
+	c, router := gin.CreateTestContext(w)
+	router.Use(func(c *gin.Context) {
+		c.Set("isManager", isManager)
+		c.Set("userId", uint32(1))
+		c.Set("organizationId", organizationId)
+	})
+
+	routes.AddAdminRoutes(router)
+
+	return c, router
+}
+
+func TestGetDeadLetters(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_ORGANIZATION_ID", "1")
+
+	t.Run("Success: list dead letters of the tasks queue", func(t *testing.T) {
+		iDeadLetterMock := new(taskMock.IDeadLetters)
+		iDeadLetterMock.On("InspectDeadLetters", "tasks", 5).Return([]models.DeadLetter{
+			{MessageId: "message-1", Queue: "tasks", Attempts: 5, LastError: "database is down", Body: json.RawMessage(`{"type":"task.executed"}`)},
+		}, nil)
+		broker.DeadLetterServices = iDeadLetterMock
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/admin/dead-letters/tasks?limit=5", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		var body []models.DeadLetter
+		json.Unmarshal(w.Body.Bytes(), &body)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Len(body, 1)
+		assert.Equal("database is down", body[0].LastError)
+		assert.JSONEq(`{"type":"task.executed"}`, string(body[0].Body))
+	})
+
+	t.Run("Failed: manager of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"user without access permission"}`
+
+		iDeadLetterMock := new(taskMock.IDeadLetters)
+		broker.DeadLetterServices = iDeadLetterMock
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 2)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/admin/dead-letters/tasks", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iDeadLetterMock.AssertNotCalled(t, "InspectDeadLetters", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Failed: no admin organization configured", func(t *testing.T) {
+		expectMsgError := `{"error":"user without access permission"}`
+
+		t.Setenv("ADMIN_ORGANIZATION_ID", "")
+
+		iDeadLetterMock := new(taskMock.IDeadLetters)
+		broker.DeadLetterServices = iDeadLetterMock
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/admin/dead-letters/tasks", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iDeadLetterMock.AssertNotCalled(t, "InspectDeadLetters", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Failed: queue without dead-letter queue", func(t *testing.T) {
+		expectMsgError := `{"error":"queue has no dead-letter queue"}`
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/admin/dead-letters/notifications", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Failed: limit too big", func(t *testing.T) {
+		expectMsgError := `{"error":"dead letter limit must be between 1 and 100"}`
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/admin/dead-letters/tasks?limit=1000", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_ORGANIZATION_ID", "1")
+
+	t.Run("Success: replay dead letters", func(t *testing.T) {
+		iDeadLetterMock := new(taskMock.IDeadLetters)
+		iDeadLetterMock.On("ReplayDeadLetters", "tasks", 2).Return(2, nil)
+		broker.DeadLetterServices = iDeadLetterMock
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/admin/dead-letters/tasks/replay", bytes.NewBufferString(`{"limit":2}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(`{"replayed":2}`, w.Body.String())
+	})
+
+	t.Run("Success: replay with the default limit", func(t *testing.T) {
+		iDeadLetterMock := new(taskMock.IDeadLetters)
+		iDeadLetterMock.On("ReplayDeadLetters", "tasks", 20).Return(0, nil)
+		broker.DeadLetterServices = iDeadLetterMock
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/admin/dead-letters/tasks/replay", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iDeadLetterMock.AssertExpectations(t)
+	})
+}
+
+func TestPurgeDeadLetters(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_ORGANIZATION_ID", "1")
+
+	t.Run("Success: purge dead letters", func(t *testing.T) {
+		iDeadLetterMock := new(taskMock.IDeadLetters)
+		iDeadLetterMock.On("PurgeDeadLetters", "tasks").Return(3, nil)
+		broker.DeadLetterServices = iDeadLetterMock
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/admin/dead-letters/tasks", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(`{"purged":3}`, w.Body.String())
+	})
+
+	t.Run("Failed: technician", func(t *testing.T) {
+		iDeadLetterMock := new(taskMock.IDeadLetters)
+		broker.DeadLetterServices = iDeadLetterMock
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, false, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/admin/dead-letters/tasks", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		iDeadLetterMock.AssertNotCalled(t, "PurgeDeadLetters", tmock.Anything)
+	})
+}
+
+func TestGetConsumerStats(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_ORGANIZATION_ID", "1")
+
+	t.Run("Success: counters of the consumed queues", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/admin/consumers", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		var body []models.ConsumerStats
+		err := json.Unmarshal(w.Body.Bytes(), &body)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Nil(err)
+	})
+
+	t.Run("Failed: manager of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"user without access permission"}`
+
+		w := httptest.NewRecorder()
+		c, router := adminRouter(w, true, 2)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/admin/consumers", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+}
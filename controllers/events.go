@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/events"
+)
+
+// eventMeta returns the actor and correlation of the events published by the request
+func eventMeta(c *gin.Context) events.Meta {
+	return events.Meta{
+		ActorId:       actorIdFromContext(c),
+		CorrelationId: correlationIdFromContext(c),
+	}
+}
+
+// correlationIdFromContext returns the correlation id of the request, a new
+// one when the request didn't go through the CorrelationId middleware
+func correlationIdFromContext(c *gin.Context) string {
+	if correlationId := c.GetString("correlationId"); correlationId != "" {
+		return correlationId
+	}
+
+	correlationId, _ := events.NewId()
+	c.Set("correlationId", correlationId)
+	return correlationId
+}
+
+// actorIdFromContext returns the id of the authenticated user, 0 when anonymous
+func actorIdFromContext(c *gin.Context) uint32 {
+	userIdRaw, _ := c.Get("userId")
+	userId, _ := userIdRaw.(uint32)
+	return userId
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// sendSerialized sends a serialized response keeping only the fields asked in ?fields=
+func sendSerialized(c *gin.Context, statusCode int, data interface{}) {
+	selected, err := serializers.SelectFields(data, serializers.ParseFields(c.Query("fields")))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidFieldSelection, err))
+		return
+	}
+
+	utils.SendJSONResponse(c, statusCode, selected)
+}
+
+// organizationIdFromContext returns the organization of the authenticated user, or 0 when unknown
+func organizationIdFromContext(c *gin.Context) uint32 {
+	organizationIdRaw, ok := c.Get("organizationId")
+	if !ok || organizationIdRaw == nil {
+		return 0
+	}
+	return organizationIdRaw.(uint32)
+}
+
+// isManager reports whether the authenticated user is a manager. Managers
+// administer the settings of their organization.
+func isManager(c *gin.Context) bool {
+	isManagerRaw, ok := c.Get("isManager")
+	if !ok || isManagerRaw == nil {
+		return false
+	}
+	return isManagerRaw.(bool)
+}
+
+// isAdmin reports whether the user is a manager of the organization that
+// operates gtasks, ADMIN_ORGANIZATION_ID. Nobody is admin when it is unset: the
+// administration endpoints act on data of every organization.
+func isAdmin(c *gin.Context) bool {
+	adminOrganizationId, err := strconv.ParseUint(os.Getenv("ADMIN_ORGANIZATION_ID"), 10, 32)
+	if err != nil || adminOrganizationId == 0 {
+		return false
+	}
+
+	return isManager(c) && organizationIdFromContext(c) == uint32(adminOrganizationId)
+}
+
+// canSeeTask applies the visibility of GetTasks to a task: managers see the
+// tasks of their organization, the other users the tasks assigned to them
+func canSeeTask(c *gin.Context, task models.Task) bool {
+	if isManager(c) {
+		return task.OrganizationId == organizationIdFromContext(c)
+	}
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		return false
+	}
+	return task.UserId == userIdRaw.(uint32)
+}
+
+// queryUint32 reads an optional numeric id from the query string, 0 when absent
+func queryUint32(c *gin.Context, name string) (uint32, error) {
+	value := strings.TrimSpace(c.Query(name))
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %v", utils.InvalidQueryParameter, name)
+	}
+
+	return uint32(id), nil
+}
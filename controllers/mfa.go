@@ -0,0 +1,323 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/golang-jwt/jwt"
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+const (
+	mfaChallengeType  = "mfa_challenge"
+	mfaChallengeTTL   = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// EnrollMfa starts the TOTP enrollment of the authenticated user. The secret
+// is returned along with the otpauth:// URI to be shown as a QR code, MFA is
+// only enabled by ConfirmMfa.
+func EnrollMfa(c *gin.Context) {
+	user, ok := authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	if user.MfaEnabled {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaAlreadyEnabled))
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	if err := repository.MfaRepositoryServices.SaveMfaSecret(user.ID, secret); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": auth.TOTPProvisioningURI(secret, user.Email),
+	})
+}
+
+// ConfirmMfa enables MFA with a code of the enrolled secret and returns the
+// recovery codes, they are only shown this time. Wrong codes are throttled like the login.
+func ConfirmMfa(c *gin.Context) {
+	var code models.MfaCode
+	if err := c.ShouldBindWith(&code, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	user, ok := authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	if user.MfaEnabled {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaAlreadyEnabled))
+		return
+	}
+	if user.MfaSecret == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaNotEnrolled))
+		return
+	}
+
+	if !checkLoginThrottle(c, user.Email) {
+		return
+	}
+
+	if !verifyTotp(user, code.Code) {
+		recordLoginAttempt(c, user.Email, &user.ID, models.LoginInvalidMfaCode)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaCodeInvalid))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	if err := repository.MfaRepositoryServices.EnableMfa(user.ID, hashes); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	// the enrollment token is replaced by a token with full access
+	user.MfaEnabled = true
+	tokenString, err := generateToken(user)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.UserFailedGetToken, err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"recovery_codes": codes,
+		"token":          tokenString,
+	})
+}
+
+// DisableMfa turns MFA off with a TOTP or recovery code. Users the policy
+// requires MFA from can't disable it. Wrong codes are throttled like the login. The tokens already issued are
+// invalidated and a new token is returned.
+func DisableMfa(c *gin.Context) {
+	var code models.MfaCode
+	if err := c.ShouldBindWith(&code, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	user, ok := authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	if !user.MfaEnabled {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaNotEnrolled))
+		return
+	}
+	if auth.MfaRequired(user) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaRequired))
+		return
+	}
+
+	if !checkLoginThrottle(c, user.Email) {
+		return
+	}
+
+	if !verifyMfaCode(user, code.Code) {
+		recordLoginAttempt(c, user.Email, &user.ID, models.LoginInvalidMfaCode)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaCodeInvalid))
+		return
+	}
+
+	user, err := repository.MfaRepositoryServices.DisableMfa(user.ID)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	tokenString, err := generateToken(user)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.UserFailedGetToken, err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+		"token":   tokenString,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user, a TOTP code
+// is required. Wrong codes are throttled like the login.
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var code models.MfaCode
+	if err := c.ShouldBindWith(&code, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	user, ok := authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	if !user.MfaEnabled {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaNotEnrolled))
+		return
+	}
+
+	if !checkLoginThrottle(c, user.Email) {
+		return
+	}
+
+	if !verifyTotp(user, code.Code) {
+		recordLoginAttempt(c, user.Email, &user.ID, models.LoginInvalidMfaCode)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaCodeInvalid))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	if err := repository.MfaRepositoryServices.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// LoginMfa is the second step of the login of users with MFA: the challenge
+// token returned by LoginUser and a TOTP or recovery code are exchanged for the JWT
+func LoginMfa(c *gin.Context) {
+	var login models.MfaLogin
+	if err := c.ShouldBindWith(&login, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	userId, version, err := parseMfaChallenge(login.MfaToken)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaTokenInvalid))
+		return
+	}
+
+	user, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(userId))
+	if err != nil || !user.Active || !user.MfaEnabled || user.TokenVersion != version {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaTokenInvalid))
+		return
+	}
+
+	if !checkLoginThrottle(c, user.Email) {
+		return
+	}
+
+	if !verifyMfaCode(user, login.Code) {
+		recordLoginAttempt(c, user.Email, &user.ID, models.LoginInvalidMfaCode)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserMfaCodeInvalid))
+		return
+	}
+
+	sendLoginToken(c, user.Email, user)
+}
+
+// authenticatedUser loads the authenticated user, sending the error response when it fails
+func authenticatedUser(c *gin.Context) (models.User, bool) {
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return models.User{}, false
+	}
+
+	user, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(userIdRaw))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return models.User{}, false
+	}
+
+	return user, true
+}
+
+// verifyTotp checks a TOTP code of the user, each code is accepted only once
+func verifyTotp(user models.User, code string) bool {
+	step, ok := auth.ValidateTOTP(user.MfaSecret, code, time.Now())
+	if !ok {
+		return false
+	}
+
+	used, err := repository.MfaRepositoryServices.UseTotpStep(user.ID, step)
+	return err == nil && used
+}
+
+// verifyMfaCode accepts a TOTP code or consumes a recovery code
+func verifyMfaCode(user models.User, code string) bool {
+	if verifyTotp(user, code) {
+		return true
+	}
+
+	used, err := repository.MfaRepositoryServices.UseRecoveryCode(user.ID, utils.HashToken(auth.NormalizeRecoveryCode(code)))
+	return err == nil && used
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, utils.HashToken(auth.NormalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+func generateMfaChallenge(user models.User) (string, error) {
+	return signToken(jwt.MapClaims{
+		"typ": mfaChallengeType,
+		"sub": user.ID,
+		"ver": user.TokenVersion,
+		"exp": time.Now().Add(mfaChallengeTTL).Unix(),
+	})
+}
+
+func parseMfaChallenge(tokenString string) (uint32, uint32, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(os.Getenv("SECRET")), nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != mfaChallengeType {
+		return 0, 0, fmt.Errorf("%v", utils.UserMfaTokenInvalid)
+	}
+
+	userId, _ := claims["sub"].(float64)
+	version, _ := claims["ver"].(float64)
+	return uint32(userId), uint32(version), nil
+}
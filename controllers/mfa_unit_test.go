@@ -0,0 +1,406 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/hugohenrick/gtasks/auth"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const mfaTestSecret = "JBSWY3DPEHPK3PXP"
+
+func mockLoginAttempts() *taskMock.ILoginAttemptRepository {
+	iAttemptMock := new(taskMock.ILoginAttemptRepository)
+	iAttemptMock.On("CountAccountFailures", tmock.Anything, tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+	iAttemptMock.On("CountEmailFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+	iAttemptMock.On("CountIPFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+	iAttemptMock.On("CreateLoginAttempt", tmock.Anything).Return(models.LoginAttempt{}, nil)
+	return iAttemptMock
+}
+
+func TestLoginMfa(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	userModel := models.User{ID: 2, Email: "manager@gtasks.com", Password: string(hash), IsManager: true, Active: true, MfaEnabled: true, MfaSecret: mfaTestSecret}
+
+	loginChallenge := func(router *gin.Engine) string {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"manager@gtasks.com","password":"secret"}`))
+		router.ServeHTTP(w, request)
+
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(true, body["mfa_required"])
+		assert.Nil(body["token"])
+
+		mfaToken, _ := body["mfa_token"].(string)
+		return mfaToken
+	}
+
+	t.Run("Success: password then TOTP code", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "manager@gtasks.com").Return(userModel, nil)
+		iUserMock.On("UpdatePasswordHash", "2", tmock.Anything).Return(nil)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iMfaMock := new(taskMock.IMfaRepository)
+		iMfaMock.On("UseTotpStep", uint32(2), tmock.Anything).Return(true, nil)
+		repository.MfaRepositoryServices = iMfaMock
+
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		mfaToken := loginChallenge(router)
+		code, _ := auth.TOTPCode(mfaTestSecret, time.Now())
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login/mfa", bytes.NewBufferString(`{"mfa_token":"`+mfaToken+`","code":"`+code+`"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		assert.Contains(w.Body.String(), `"token":`)
+	})
+
+	t.Run("Failed: wrong code is recorded as a failed attempt", func(t *testing.T) {
+		expectMsgError := `{"error":"two-factor authentication code is invalid"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "manager@gtasks.com").Return(userModel, nil)
+		iUserMock.On("UpdatePasswordHash", "2", tmock.Anything).Return(nil)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iMfaMock := new(taskMock.IMfaRepository)
+		iMfaMock.On("UseRecoveryCode", uint32(2), tmock.Anything).Return(false, nil)
+		repository.MfaRepositoryServices = iMfaMock
+
+		iAttemptMock := mockLoginAttempts()
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		mfaToken := loginChallenge(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login/mfa", bytes.NewBufferString(`{"mfa_token":"`+mfaToken+`","code":"abcde-fghij"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iAttemptMock.AssertCalled(t, "CreateLoginAttempt", tmock.MatchedBy(func(attempt models.LoginAttempt) bool {
+			return attempt.Result == models.LoginInvalidMfaCode
+		}))
+	})
+
+	t.Run("Failed: challenge token is not a login token", func(t *testing.T) {
+		expectMsgError := `{"error":"mfa token is invalid or expired"}`
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login/mfa", bytes.NewBufferString(`{"mfa_token":"invalid","code":"123456"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+}
+
+func TestConfirmMfa(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	userModel := models.User{ID: 2, Email: "manager@gtasks.com", IsManager: true, Active: true, MfaSecret: mfaTestSecret}
+
+	t.Run("Success: MFA enabled and recovery codes returned", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iMfaMock := new(taskMock.IMfaRepository)
+		iMfaMock.On("UseTotpStep", uint32(2), tmock.Anything).Return(true, nil)
+		iMfaMock.On("EnableMfa", uint32(2), tmock.Anything).Return(nil)
+		repository.MfaRepositoryServices = iMfaMock
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+		})
+
+		routes.AddUserRoutes(router)
+
+		code, _ := auth.TOTPCode(mfaTestSecret, time.Now())
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/mfa/confirm", bytes.NewBufferString(`{"code":"`+code+`"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		var body struct {
+			RecoveryCodes []string `json:"recovery_codes"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Len(body.RecoveryCodes, 10)
+		iMfaMock.AssertCalled(t, "EnableMfa", uint32(2), tmock.MatchedBy(func(hashes []string) bool {
+			return len(hashes) == 10 && hashes[0] != body.RecoveryCodes[0]
+		}))
+	})
+
+	t.Run("Failed: code already used", func(t *testing.T) {
+		expectMsgError := `{"error":"two-factor authentication code is invalid"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iMfaMock := new(taskMock.IMfaRepository)
+		iMfaMock.On("UseTotpStep", uint32(2), tmock.Anything).Return(false, nil)
+		repository.MfaRepositoryServices = iMfaMock
+
+		iAttemptMock := mockLoginAttempts()
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+		})
+
+		routes.AddUserRoutes(router)
+
+		code, _ := auth.TOTPCode(mfaTestSecret, time.Now())
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/mfa/confirm", bytes.NewBufferString(`{"code":"`+code+`"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iMfaMock.AssertNotCalled(t, "EnableMfa", tmock.Anything, tmock.Anything)
+		iAttemptMock.AssertCalled(t, "CreateLoginAttempt", tmock.MatchedBy(func(attempt models.LoginAttempt) bool {
+			return attempt.Email == "manager@gtasks.com" && attempt.Result == models.LoginInvalidMfaCode
+		}))
+	})
+}
+
+func TestDisableMfa(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	userModel := models.User{ID: 3, Email: "tech@gtasks.com", Active: true, MfaEnabled: true, MfaSecret: mfaTestSecret, TokenVersion: 1}
+
+	t.Run("Success: MFA disabled and a token of the new version returned", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "3").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		disabled := userModel
+		disabled.MfaEnabled = false
+		disabled.MfaSecret = ""
+		disabled.TokenVersion = 2
+
+		iMfaMock := new(taskMock.IMfaRepository)
+		iMfaMock.On("UseTotpStep", uint32(3), tmock.Anything).Return(true, nil)
+		iMfaMock.On("DisableMfa", uint32(3)).Return(disabled, nil)
+		repository.MfaRepositoryServices = iMfaMock
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(3))
+		})
+
+		routes.AddUserRoutes(router)
+
+		code, _ := auth.TOTPCode(mfaTestSecret, time.Now())
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/mfa/disable", bytes.NewBufferString(`{"code":"`+code+`"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+
+		token, _ := jwt.Parse(body["token"].(string), func(token *jwt.Token) (interface{}, error) {
+			return []byte(os.Getenv("SECRET")), nil
+		})
+		claims, _ := token.Claims.(jwt.MapClaims)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(float64(2), claims["ver"])
+		iMfaMock.AssertCalled(t, "DisableMfa", uint32(3))
+	})
+
+	t.Run("Failed: too many wrong codes", func(t *testing.T) {
+		expectMsgError := `{"error":"too many login attempts, try again later"}`
+
+		lastFailure := time.Now()
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "3").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iMfaMock := new(taskMock.IMfaRepository)
+		repository.MfaRepositoryServices = iMfaMock
+
+		iAttemptMock := new(taskMock.ILoginAttemptRepository)
+		iAttemptMock.On("CountAccountFailures", "tech@gtasks.com", tmock.Anything, tmock.Anything).Return(models.LoginFailures{Count: 10, LastAt: &lastFailure}, nil)
+		iAttemptMock.On("CountEmailFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CountIPFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(3))
+		})
+
+		routes.AddUserRoutes(router)
+
+		code, _ := auth.TOTPCode(mfaTestSecret, time.Now())
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/mfa/disable", bytes.NewBufferString(`{"code":"`+code+`"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusTooManyRequests, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iMfaMock.AssertNotCalled(t, "UseTotpStep", tmock.Anything, tmock.Anything)
+		iMfaMock.AssertNotCalled(t, "DisableMfa", tmock.Anything)
+	})
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	userModel := models.User{ID: 3, Email: "tech@gtasks.com", Active: true, MfaEnabled: true, MfaSecret: mfaTestSecret}
+
+	t.Run("Failed: wrong code is recorded as a failed attempt", func(t *testing.T) {
+		expectMsgError := `{"error":"two-factor authentication code is invalid"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "3").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iMfaMock := new(taskMock.IMfaRepository)
+		repository.MfaRepositoryServices = iMfaMock
+
+		iAttemptMock := mockLoginAttempts()
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(3))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/mfa/recovery-codes", bytes.NewBufferString(`{"code":"000000x"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iMfaMock.AssertNotCalled(t, "ReplaceRecoveryCodes", tmock.Anything, tmock.Anything)
+		iAttemptMock.AssertCalled(t, "CreateLoginAttempt", tmock.MatchedBy(func(attempt models.LoginAttempt) bool {
+			return attempt.Email == "tech@gtasks.com" && attempt.Result == models.LoginInvalidMfaCode
+		}))
+	})
+}
+
+func TestLoginMfaPolicy(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	userModel := models.User{ID: 2, Email: "manager@gtasks.com", Password: string(hash), IsManager: true, Active: true}
+
+	auth.RequireMfaForManagers = true
+	defer func() { auth.RequireMfaForManagers = false }()
+
+	iUserMock := new(taskMock.IUserRepository)
+	iUserMock.On("FindUserByEmail", "manager@gtasks.com").Return(userModel, nil)
+	iUserMock.On("UpdatePasswordHash", "2", tmock.Anything).Return(nil)
+	repository.UserRepositoryServices = iUserMock
+
+	repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+	w := httptest.NewRecorder()
+	c, router := gin.CreateTestContext(w)
+
+	routes.AddUserRoutes(router)
+
+	// creating a request to send on endpoint call
+	c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"manager@gtasks.com","password":"secret"}`))
+
+	// endpoint call
+	router.ServeHTTP(w, c.Request)
+
+	// asserts
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.Contains(w.Body.String(), `"mfa_enrollment_required":true`)
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+func GetNotificationPreferences(c *gin.Context) {
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	userId := userIdRaw.(uint32)
+
+	preferences, err := repository.NotificationRepositoryServices.FindPreferences([]uint32{userId})
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	// events without a stored preference are enabled by default
+	enabled := make(map[string]bool, len(models.NotificationEvents))
+	for _, event := range models.NotificationEvents {
+		enabled[event] = true
+	}
+	for _, preference := range preferences {
+		enabled[preference.Event] = preference.Enabled
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, enabled)
+}
+
+func UpdateNotificationPreferences(c *gin.Context) {
+	var enabled map[string]bool
+	if err := c.ShouldBindWith(&enabled, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	userId := userIdRaw.(uint32)
+
+	for event := range enabled {
+		if !utils.ContainsString(models.NotificationEvents, event) {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.NotificationEventInvalid, event))
+			return
+		}
+	}
+
+	for event, value := range enabled {
+		_, err := repository.NotificationRepositoryServices.SavePreference(models.NotificationPreference{
+			UserId:  userId,
+			Event:   event,
+			Enabled: value,
+		})
+		if err != nil {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+			return
+		}
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
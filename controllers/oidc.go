@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+const (
+	oidcCookieName = "gtasks_oidc"
+	oidcCookiePath = "/user/login/oidc"
+	oidcFlowType   = "oidc_login"
+	oidcFlowTTL    = 10 * time.Minute
+)
+
+// LoginOidc starts the single sign-on by redirecting the user to the identity
+// provider. The state, nonce and PKCE verifier are kept in a signed cookie
+// until the callback.
+func LoginOidc(c *gin.Context) {
+	provider := auth.OIDCProviderServices
+	if provider == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserSsoNotConfigured))
+		return
+	}
+
+	state, _, err := utils.NewToken()
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+	nonce, _, err := utils.NewToken()
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+	verifier, challenge, err := auth.NewPKCE()
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	flow, err := signToken(jwt.MapClaims{
+		"typ":      oidcFlowType,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      time.Now().Add(oidcFlowTTL).Unix(),
+	})
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, challenge)
+	if err != nil {
+		log.Printf("error starting single sign-on: %s\n", err)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserSsoFailed))
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcCookieName, flow, int(oidcFlowTTL.Seconds()), oidcCookiePath, "", secureCookie(), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// LoginOidcCallback finishes the single sign-on: the authorization code is
+// exchanged for the identity of the user, who is created on the first login.
+// The role follows the groups of the identity provider on every login.
+func LoginOidcCallback(c *gin.Context) {
+	provider := auth.OIDCProviderServices
+	if provider == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserSsoNotConfigured))
+		return
+	}
+
+	if idpError := c.Query("error"); idpError != "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.UserSsoFailed, idpError))
+		return
+	}
+
+	flow, _ := c.Cookie(oidcCookieName)
+	state, nonce, verifier, err := parseOidcFlow(flow)
+	if err != nil || subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserSsoStateInvalid))
+		return
+	}
+
+	// the flow cookie is single use
+	c.SetCookie(oidcCookieName, "", -1, oidcCookiePath, "", secureCookie(), true)
+
+	identity, err := provider.Exchange(c.Request.Context(), c.Query("code"), verifier, nonce)
+	if err != nil {
+		log.Printf("error finishing single sign-on: %s\n", err)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserSsoFailed))
+		return
+	}
+
+	email := utils.NormalizeEmail(identity.Email)
+
+	isManager, allowed := provider.Config().Role(identity.Groups)
+	if !allowed {
+		recordLoginAttempt(c, email, nil, models.LoginGroupNotAllowed)
+		utils.SendJSONError(c, http.StatusForbidden, fmt.Errorf("%v", utils.UserSsoGroupNotAllowed))
+		return
+	}
+
+	user, err := provisionOidcUser(c, provider.Config(), identity, email, isManager)
+	if errors.Is(err, utils.ErrEmailTaken) || errors.Is(err, utils.ErrSsoLinkRefused) {
+		utils.SendJSONError(c, http.StatusConflict, fmt.Errorf("%v", err))
+		return
+	}
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	if !user.Active {
+		recordLoginAttempt(c, user.Email, &user.ID, models.LoginUserDeactivated)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserDeactivated))
+		return
+	}
+
+	// two-factor authentication is left to the identity provider
+	sendLoginToken(c, user.Email, user)
+}
+
+// provisionOidcUser returns the user of the identity. Users are found by
+// subject, then linked by verified email when the local account has a verified
+// email in the organization of the provider, and created when they don't exist.
+func provisionOidcUser(c *gin.Context, config auth.OIDCConfig, identity auth.OIDCIdentity, email string, isManager bool) (models.User, error) {
+	user, err := repository.UserRepositoryServices.FindUserByOidcSubject(identity.Subject)
+	if err == nil {
+		if !user.Active {
+			return user, nil
+		}
+		return repository.UserRepositoryServices.SaveOidcLogin(fmt.Sprint(user.ID), identity.Subject, isManager, eventMeta(c))
+	}
+
+	if !identity.EmailVerified || !utils.ValidEmail(email) {
+		return models.User{}, errors.New(utils.UserSsoEmailRequired)
+	}
+
+	// a local account is only linked when its owner proved the email and it
+	// belongs to the organization of the identity provider, otherwise whoever
+	// registered the email first would keep a password login to the identity
+	user, err = repository.UserRepositoryServices.FindUserByEmail(email)
+	if err == nil {
+		if !user.EmailVerified || user.OrganizationId != config.OrganizationId {
+			return models.User{}, utils.ErrSsoLinkRefused
+		}
+		if !user.Active {
+			return user, nil
+		}
+		return repository.UserRepositoryServices.SaveOidcLogin(fmt.Sprint(user.ID), identity.Subject, isManager, eventMeta(c))
+	}
+
+	// users of the identity provider have no password, a random one is stored
+	password, _, err := utils.NewToken()
+	if err != nil {
+		return models.User{}, err
+	}
+	hashPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = email
+	}
+	subject := identity.Subject
+
+	user, err = repository.UserRepositoryServices.CreateUser(models.User{
+		Name:           name,
+		Email:          email,
+		EmailVerified:  true,
+		Password:       hashPassword,
+		IsManager:      isManager,
+		OrganizationId: config.OrganizationId,
+		Active:         true,
+		OidcSubject:    &subject,
+	}, eventMeta(c))
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func parseOidcFlow(tokenString string) (string, string, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(os.Getenv("SECRET")), nil
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != oidcFlowType {
+		return "", "", "", fmt.Errorf("%v", utils.UserSsoStateInvalid)
+	}
+
+	state, _ := claims["state"].(string)
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	if state == "" || nonce == "" || verifier == "" {
+		return "", "", "", fmt.Errorf("%v", utils.UserSsoStateInvalid)
+	}
+	return state, nonce, verifier, nil
+}
+
+// secureCookie reports whether cookies must only be sent over https
+func secureCookie() bool {
+	return strings.HasPrefix(appURL(), "https://")
+}
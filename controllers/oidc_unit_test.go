@@ -0,0 +1,268 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/auth/oidctest"
+	"github.com/hugohenrick/gtasks/events"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// oidcLogin runs the single sign-on against the provider: the login redirects
+// to the provider, which redirects back to the callback with the code
+func oidcLogin(t *testing.T, router *gin.Engine, tamperState bool) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodGet, "/user/login/oidc", nil)
+	router.ServeHTTP(w, request)
+	if w.Code != http.StatusFound {
+		t.Fatalf("login returned %d: %s", w.Code, w.Body.String())
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	response, err := client.Get(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	callback, _ := url.Parse(response.Header.Get("Location"))
+	if tamperState {
+		query := callback.Query()
+		query.Set("state", "tampered")
+		callback.RawQuery = query.Encode()
+	}
+
+	callbackRecorder := httptest.NewRecorder()
+	request, _ = http.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	for _, cookie := range w.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	router.ServeHTTP(callbackRecorder, request)
+
+	return callbackRecorder
+}
+
+func TestLoginOidc(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	idp := oidctest.NewServer("gtasks", "client-secret")
+	defer idp.Close()
+
+	auth.OIDCProviderServices = auth.NewOIDCProvider(auth.OIDCConfig{
+		Issuer:           idp.URL,
+		ClientID:         "gtasks",
+		ClientSecret:     "client-secret",
+		RedirectURL:      "http://localhost:8080/user/login/oidc/callback",
+		Scopes:           []string{"openid", "email", "profile", "groups"},
+		GroupsClaim:      "groups",
+		ManagerGroups:    []string{"gtasks-managers"},
+		TechnicianGroups: []string{"gtasks-technicians"},
+		OrganizationId:   1,
+	})
+	defer func() { auth.OIDCProviderServices = nil }()
+
+	t.Run("Success: user provisioned on the first login", func(t *testing.T) {
+		idp.SetUser(oidctest.User{Subject: "idp-1", Email: "Manager@Corp.com", EmailVerified: true, Name: "Manager", Groups: []string{"gtasks-managers"}})
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByOidcSubject", "idp-1").Return(models.User{}, errors.New("user not found"))
+		iUserMock.On("FindUserByEmail", "manager@corp.com").Return(models.User{}, errors.New("user not found"))
+		iUserMock.On("CreateUser", tmock.Anything, tmock.Anything).Return(func(user models.User, meta events.Meta) models.User {
+			user.ID = 5
+			return user
+		}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		_, router := gin.CreateTestContext(httptest.NewRecorder())
+
+		routes.AddUserRoutes(router)
+
+		// endpoint call
+		w := oidcLogin(t, router, false)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		assert.Contains(w.Body.String(), `"token":`)
+		iUserMock.AssertCalled(t, "CreateUser", tmock.MatchedBy(func(user models.User) bool {
+			return user.Email == "manager@corp.com" && user.Name == "Manager" && user.IsManager &&
+				user.OrganizationId == 1 && user.OidcSubject != nil && *user.OidcSubject == "idp-1" && user.Password != ""
+		}), tmock.Anything)
+	})
+
+	t.Run("Success: role of a known user follows its groups", func(t *testing.T) {
+		idp.SetUser(oidctest.User{Subject: "idp-1", Email: "manager@corp.com", EmailVerified: true, Groups: []string{"gtasks-technicians"}})
+
+		subject := "idp-1"
+		userModel := models.User{ID: 5, Email: "manager@corp.com", IsManager: true, Active: true, OrganizationId: 1, OidcSubject: &subject}
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByOidcSubject", "idp-1").Return(userModel, nil)
+		iUserMock.On("SaveOidcLogin", "5", "idp-1", false, tmock.Anything).Return(models.User{ID: 5, Email: "manager@corp.com", Active: true, OrganizationId: 1}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		_, router := gin.CreateTestContext(httptest.NewRecorder())
+
+		routes.AddUserRoutes(router)
+
+		// endpoint call
+		w := oidcLogin(t, router, false)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		iUserMock.AssertCalled(t, "SaveOidcLogin", "5", "idp-1", false, tmock.Anything)
+		iUserMock.AssertNotCalled(t, "CreateUser", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: verified local account of the organization linked", func(t *testing.T) {
+		idp.SetUser(oidctest.User{Subject: "idp-3", Email: "tech@corp.com", EmailVerified: true, Groups: []string{"gtasks-technicians"}})
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByOidcSubject", "idp-3").Return(models.User{}, errors.New("user not found"))
+		iUserMock.On("FindUserByEmail", "tech@corp.com").Return(models.User{ID: 6, Email: "tech@corp.com", EmailVerified: true, Active: true, OrganizationId: 1}, nil)
+		iUserMock.On("SaveOidcLogin", "6", "idp-3", false, tmock.Anything).Return(models.User{ID: 6, Email: "tech@corp.com", Active: true, OrganizationId: 1}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		_, router := gin.CreateTestContext(httptest.NewRecorder())
+
+		routes.AddUserRoutes(router)
+
+		// endpoint call
+		w := oidcLogin(t, router, false)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		iUserMock.AssertCalled(t, "SaveOidcLogin", "6", "idp-3", false, tmock.Anything)
+	})
+
+	t.Run("Failed: unverified or foreign local account not linked", func(t *testing.T) {
+		expectMsgError := `{"error":"email already registered to an account that cannot be linked to the single sign-on"}`
+
+		for _, local := range []models.User{
+			{ID: 7, Email: "victim@corp.com", EmailVerified: false, Active: true, OrganizationId: 1},
+			{ID: 7, Email: "victim@corp.com", EmailVerified: true, Active: true, OrganizationId: 2},
+		} {
+			idp.SetUser(oidctest.User{Subject: "idp-4", Email: "victim@corp.com", EmailVerified: true, Groups: []string{"gtasks-managers"}})
+
+			iUserMock := new(taskMock.IUserRepository)
+			iUserMock.On("FindUserByOidcSubject", "idp-4").Return(models.User{}, errors.New("user not found"))
+			iUserMock.On("FindUserByEmail", "victim@corp.com").Return(local, nil)
+			repository.UserRepositoryServices = iUserMock
+
+			repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+			_, router := gin.CreateTestContext(httptest.NewRecorder())
+
+			routes.AddUserRoutes(router)
+
+			// endpoint call
+			w := oidcLogin(t, router, false)
+
+			// asserts
+			assert.Equal(http.StatusConflict, w.Code)
+			assert.Equal(expectMsgError, w.Body.String())
+			iUserMock.AssertNotCalled(t, "SaveOidcLogin", tmock.Anything, tmock.Anything, tmock.Anything)
+		}
+	})
+
+	t.Run("Failed: groups not allowed", func(t *testing.T) {
+		expectMsgError := `{"error":"user groups are not allowed to access gtasks"}`
+
+		idp.SetUser(oidctest.User{Subject: "idp-2", Email: "guest@corp.com", EmailVerified: true, Groups: []string{"sales"}})
+
+		iUserMock := new(taskMock.IUserRepository)
+		repository.UserRepositoryServices = iUserMock
+
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		_, router := gin.CreateTestContext(httptest.NewRecorder())
+
+		routes.AddUserRoutes(router)
+
+		// endpoint call
+		w := oidcLogin(t, router, false)
+
+		// asserts
+		assert.Equal(http.StatusForbidden, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "CreateUser", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Failed: state does not match", func(t *testing.T) {
+		expectMsgError := `{"error":"single sign-on state is invalid or expired"}`
+
+		idp.SetUser(oidctest.User{Subject: "idp-1", Email: "manager@corp.com", EmailVerified: true, Groups: []string{"gtasks-managers"}})
+
+		_, router := gin.CreateTestContext(httptest.NewRecorder())
+
+		routes.AddUserRoutes(router)
+
+		// endpoint call
+		w := oidcLogin(t, router, true)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+}
+
+func TestLoginUserPasswordDisabled(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	expectMsgError := `{"error":"password login is disabled for the organization, sign in with single sign-on"}`
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	userModel := models.User{ID: 2, Email: "tech@gtasks.com", Password: string(hash), OrganizationId: 1, Active: true}
+
+	iUserMock := new(taskMock.IUserRepository)
+	iUserMock.On("FindUserByEmail", "tech@gtasks.com").Return(userModel, nil)
+	iUserMock.On("UpdatePasswordHash", "2", tmock.Anything).Return(nil)
+	repository.UserRepositoryServices = iUserMock
+
+	iOrganizationMock := new(taskMock.IOrganizationRepository)
+	iOrganizationMock.On("FindOrganizationById", uint32(1)).Return(models.Organization{ID: 1, PasswordLoginDisabled: true}, nil)
+	repository.OrganizationRepositoryServices = iOrganizationMock
+
+	iAttemptMock := mockLoginAttempts()
+	repository.LoginAttemptRepositoryServices = iAttemptMock
+
+	w := httptest.NewRecorder()
+	c, router := gin.CreateTestContext(w)
+
+	routes.AddUserRoutes(router)
+
+	// creating a request to send on endpoint call
+	c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"tech@gtasks.com","password":"secret"}`))
+
+	// endpoint call
+	router.ServeHTTP(w, c.Request)
+
+	// asserts
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Equal(expectMsgError, w.Body.String())
+	iAttemptMock.AssertCalled(t, "CreateLoginAttempt", tmock.MatchedBy(func(attempt models.LoginAttempt) bool {
+		return attempt.Result == models.LoginPasswordDisabled
+	}))
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// GetOrganization returns the organization of the authenticated user
+func GetOrganization(c *gin.Context) {
+	organization, err := repository.OrganizationRepositoryServices.FindOrganizationById(organizationIdFromContext(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.OrganizationNotFound))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, organization)
+}
+
+// UpdateOrganization changes the settings of the organization of the manager
+func UpdateOrganization(c *gin.Context) {
+	var update models.OrganizationUpdate
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	if err := c.ShouldBindWith(&update, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	organizationId := organizationIdFromContext(c)
+	organization, err := repository.OrganizationRepositoryServices.FindOrganizationById(organizationId)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.OrganizationNotFound))
+		return
+	}
+
+	if update.Name != nil {
+		organization.Name = strings.TrimSpace(*update.Name)
+		if organization.Name == "" {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.OrganizationNameRequired))
+			return
+		}
+	}
+	if update.PasswordLoginDisabled != nil {
+		// without single sign-on nobody of the organization could log in
+		if *update.PasswordLoginDisabled && auth.OIDCProviderServices == nil {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.OrganizationSsoRequired))
+			return
+		}
+		organization.PasswordLoginDisabled = *update.PasswordLoginDisabled
+	}
+
+	organization, err = repository.OrganizationRepositoryServices.UpdateOrganization(organizationId, organization)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, organization)
+}
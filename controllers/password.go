@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/mailer"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 24 * time.Hour
+	userTokenSendTimeout = 30 * time.Second
+)
+
+// ForgotPassword emails a password reset link. The response is the same whether
+// the email is registered or not, and the link is sent after the response so
+// the response time doesn't tell either: it can't be used to find accounts.
+func ForgotPassword(c *gin.Context) {
+	var forgot models.PasswordForgot
+	if err := c.ShouldBindWith(&forgot, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	user, err := repository.UserRepositoryServices.FindUserByEmail(utils.NormalizeEmail(forgot.Email))
+	if err == nil && user.Active {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), userTokenSendTimeout)
+			defer cancel()
+
+			if err := sendUserToken(ctx, user, models.UserTokenPasswordReset, passwordResetTTL); err != nil {
+				log.Printf("error sending password reset email: %s\n", err)
+			}
+		}()
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"message": utils.UserPasswordResetRequested,
+	})
+}
+
+// ResetPassword sets a new password with a token sent by ForgotPassword. The
+// tokens already issued to the user stop working.
+func ResetPassword(c *gin.Context) {
+	var reset models.PasswordReset
+	if err := c.ShouldBindWith(&reset, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	// a refused password doesn't consume the token
+	if err := auth.DefaultPasswordPolicy.Validate(reset.Password); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := repository.UserTokenRepositoryServices.ConsumeToken(utils.HashToken(reset.Token), models.UserTokenPasswordReset)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserTokenInvalid))
+		return
+	}
+
+	hashPassword, err := utils.HashPassword(reset.Password)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	_, err = repository.UserRepositoryServices.UpdatePassword(fmt.Sprint(token.UserId), hashPassword)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"message": utils.UserPasswordResetSuccess,
+	})
+}
+
+func VerifyEmail(c *gin.Context) {
+	var verification models.EmailVerification
+	if err := c.ShouldBindWith(&verification, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	token, err := repository.UserTokenRepositoryServices.ConsumeToken(utils.HashToken(verification.Token), models.UserTokenEmailVerification)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserTokenInvalid))
+		return
+	}
+
+	_, err = repository.UserRepositoryServices.VerifyEmail(fmt.Sprint(token.UserId))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"message": utils.UserEmailVerified,
+	})
+}
+
+// sendUserToken issues a single-use token for purpose and emails its link to the user
+func sendUserToken(ctx context.Context, user models.User, purpose string, ttl time.Duration) error {
+	plain, hash, err := utils.NewToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = repository.UserTokenRepositoryServices.CreateToken(models.UserToken{
+		UserId:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	template, path := mailer.TemplatePasswordReset, "/reset-password"
+	if purpose == models.UserTokenEmailVerification {
+		template, path = mailer.TemplateEmailVerification, "/verify-email"
+	}
+
+	message, err := mailer.Render(template, user.Email, map[string]interface{}{
+		"Name":      user.Name,
+		"Link":      appURL() + path + "?token=" + url.QueryEscape(plain),
+		"ExpiresIn": formatTTL(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	return mailer.MailerServices.Send(ctx, message)
+}
+
+// appURL is the address of the front-end that receives the links sent by email
+func appURL() string {
+	if value := os.Getenv("APP_URL"); value != "" {
+		return strings.TrimRight(value, "/")
+	}
+	return "http://localhost:8080"
+}
+
+func formatTTL(ttl time.Duration) string {
+	if hours := int(ttl.Hours()); hours != 1 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return "1 hour"
+}
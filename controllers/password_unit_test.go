@@ -0,0 +1,172 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/mailer"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/hugohenrick/gtasks/utils"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func TestForgotPassword(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	expectMsg := `{"message":"if the email is registered, a password reset link was sent"}`
+
+	t.Run("Success: unknown email gets the same response", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "unknown@gtasks.com").Return(models.User{}, errors.New("user not found"))
+		repository.UserRepositoryServices = iUserMock
+
+		iMailerMock := new(taskMock.IMailer)
+		mailer.MailerServices = iMailerMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/password/forgot", bytes.NewBufferString(`{"email":"unknown@gtasks.com"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(expectMsg, w.Body.String())
+		iMailerMock.AssertNotCalled(t, "Send", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: reset link sent with a hashed single-use token", func(t *testing.T) {
+		userModel := models.User{ID: 2, Name: "Tech", Email: "tech@gtasks.com", Active: true}
+
+		var stored models.UserToken
+		var sent mailer.Message
+		done := make(chan bool)
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "tech@gtasks.com").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iTokenMock := new(taskMock.IUserTokenRepository)
+		iTokenMock.On("CreateToken", tmock.Anything).Run(func(args tmock.Arguments) {
+			stored = args.Get(0).(models.UserToken)
+		}).Return(models.UserToken{ID: 1}, nil)
+		repository.UserTokenRepositoryServices = iTokenMock
+
+		iMailerMock := new(taskMock.IMailer)
+		iMailerMock.On("Send", tmock.Anything, tmock.Anything).Run(func(args tmock.Arguments) {
+			sent = args.Get(1).(mailer.Message)
+			close(done)
+		}).Return(nil)
+		mailer.MailerServices = iMailerMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/password/forgot", bytes.NewBufferString(`{"email":"tech@gtasks.com"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// the link is sent after the response
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("reset link not sent")
+		}
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(expectMsg, w.Body.String())
+		assert.Equal(models.UserTokenPasswordReset, stored.Purpose)
+		assert.Equal(uint32(2), stored.UserId)
+		assert.Equal("tech@gtasks.com", sent.To)
+
+		link := sent.Text[strings.Index(sent.Text, "http"):]
+		link = strings.TrimSpace(link[:strings.Index(link, "\n")])
+		parsed, _ := url.Parse(link)
+		token := parsed.Query().Get("token")
+		assert.NotEmpty(token)
+		assert.NotEqual(token, stored.TokenHash)
+		assert.Equal(utils.HashToken(token), stored.TokenHash)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Failed: invalid or expired token", func(t *testing.T) {
+		expectMsgError := `{"error":"token is invalid or expired"}`
+
+		iTokenMock := new(taskMock.IUserTokenRepository)
+		iTokenMock.On("ConsumeToken", utils.HashToken("used"), models.UserTokenPasswordReset).Return(models.UserToken{}, errors.New(utils.UserTokenInvalid))
+		repository.UserTokenRepositoryServices = iTokenMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/password/reset", bytes.NewBufferString(`{"token":"used","password":"new-secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "UpdatePassword", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: password reset", func(t *testing.T) {
+		expectMsg := `{"message":"password changed successfully"}`
+
+		iTokenMock := new(taskMock.IUserTokenRepository)
+		iTokenMock.On("ConsumeToken", utils.HashToken("valid"), models.UserTokenPasswordReset).Return(models.UserToken{ID: 1, UserId: 2}, nil)
+		repository.UserTokenRepositoryServices = iTokenMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("UpdatePassword", "2", tmock.Anything).Return(models.User{ID: 2, TokenVersion: 1}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/password/reset", bytes.NewBufferString(`{"token":"valid","password":"new-secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(expectMsg, w.Body.String())
+		iUserMock.AssertCalled(t, "UpdatePassword", "2", tmock.Anything)
+	})
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+func GetProjects(c *gin.Context) {
+	siteId, err := queryUint32(c, "site_id")
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	projects, err := repository.ProjectRepositoryServices.FindProjects(organizationIdFromContext(c), siteId)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, projects)
+}
+
+func GetProjectById(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectIdRequired))
+		return
+	}
+
+	project, err := repository.ProjectRepositoryServices.FindProjectById(id)
+	if err != nil || project.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectNotFound))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, project)
+}
+
+// GetProjectSummary returns the open/done task counts and hours of a project
+func GetProjectSummary(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectIdRequired))
+		return
+	}
+
+	project, err := repository.ProjectRepositoryServices.FindProjectById(id)
+	if err != nil || project.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectNotFound))
+		return
+	}
+
+	summary, err := repository.ProjectRepositoryServices.FindProjectSummary(id)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, summary)
+}
+
+func CreateProject(c *gin.Context) {
+	var project models.Project
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	if err := c.ShouldBindWith(&project, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	if project.Name == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectNameRequired))
+		return
+	}
+
+	site, err := repository.SiteRepositoryServices.FindSiteById(fmt.Sprint(project.SiteId))
+	if err != nil || site.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteNotFound))
+		return
+	}
+
+	project.ID = 0
+	project.OrganizationId = site.OrganizationId
+	project.Site = nil
+
+	project, err = repository.ProjectRepositoryServices.CreateProject(project, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, project)
+}
+
+func UpdateProject(c *gin.Context) {
+	var project models.Project
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&project, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	if project.Name == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectNameRequired))
+		return
+	}
+
+	current, err := repository.ProjectRepositoryServices.FindProjectById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectNotFound))
+		return
+	}
+
+	project, err = repository.ProjectRepositoryServices.UpdateProject(id, project, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, project)
+}
+
+// DeleteProject deletes a project. Projects with tasks are only deleted with
+// ?cascade=true, which deletes their tasks too.
+func DeleteProject(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectIdRequired))
+		return
+	}
+
+	current, err := repository.ProjectRepositoryServices.FindProjectById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.ProjectNotFound))
+		return
+	}
+
+	_, err = repository.ProjectRepositoryServices.DeleteProject(id, c.Query("cascade") == "true", eventMeta(c))
+	if errors.Is(err, utils.ErrProjectHasTasks) {
+		utils.SendJSONError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
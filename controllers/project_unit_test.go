@@ -0,0 +1,112 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/hugohenrick/gtasks/utils"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func TestDeleteProject(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	projectModel := models.Project{
+		ID:             1,
+		OrganizationId: 1,
+		SiteId:         1,
+		Name:           "Test Project",
+	}
+
+	t.Run("Failed: project has tasks", func(t *testing.T) {
+		expectMsgError := `{"error":"project has tasks, delete it with cascade=true to delete its tasks too"}`
+
+		iProjectMock := new(taskMock.IProjectRepository)
+		iProjectMock.On("FindProjectById", "1").Return(projectModel, nil)
+		iProjectMock.On("DeleteProject", "1", false, tmock.Anything).Return(int64(0), utils.ErrProjectHasTasks)
+		repository.ProjectRepositoryServices = iProjectMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddProjectRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/project/1", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusConflict, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Failed: project of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"project not found"}`
+
+		iProjectMock := new(taskMock.IProjectRepository)
+		iProjectMock.On("FindProjectById", "1").Return(projectModel, nil)
+		repository.ProjectRepositoryServices = iProjectMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(2))
+		})
+
+		routes.AddProjectRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/project/1?cascade=true", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Success: delete project with its tasks", func(t *testing.T) {
+		iProjectMock := new(taskMock.IProjectRepository)
+		iProjectMock.On("FindProjectById", "1").Return(projectModel, nil)
+		iProjectMock.On("DeleteProject", "1", true, tmock.Anything).Return(int64(1), nil)
+		repository.ProjectRepositoryServices = iProjectMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddProjectRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/project/1?cascade=true", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iProjectMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+func GetSites(c *gin.Context) {
+	customerId, err := queryUint32(c, "customer_id")
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	sites, err := repository.SiteRepositoryServices.FindSites(organizationIdFromContext(c), customerId)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, sites)
+}
+
+func GetSiteById(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteIdRequired))
+		return
+	}
+
+	site, err := repository.SiteRepositoryServices.FindSiteById(id)
+	if err != nil || site.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteNotFound))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, site)
+}
+
+func CreateSite(c *gin.Context) {
+	var site models.Site
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	if err := c.ShouldBindWith(&site, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	if site.Name == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteNameRequired))
+		return
+	}
+
+	customer, err := repository.CustomerRepositoryServices.FindCustomerById(fmt.Sprint(site.CustomerId))
+	if err != nil || customer.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.CustomerNotFound))
+		return
+	}
+
+	site.ID = 0
+	site.OrganizationId = customer.OrganizationId
+	site.Customer = nil
+
+	site, err = repository.SiteRepositoryServices.CreateSite(site, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, site)
+}
+
+func UpdateSite(c *gin.Context) {
+	var site models.Site
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&site, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	if site.Name == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteNameRequired))
+		return
+	}
+
+	current, err := repository.SiteRepositoryServices.FindSiteById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteNotFound))
+		return
+	}
+
+	site, err = repository.SiteRepositoryServices.UpdateSite(id, site, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, site)
+}
+
+func DeleteSite(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteIdRequired))
+		return
+	}
+
+	current, err := repository.SiteRepositoryServices.FindSiteById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.SiteNotFound))
+		return
+	}
+
+	_, err = repository.SiteRepositoryServices.DeleteSite(id, eventMeta(c))
+	if errors.Is(err, utils.ErrSiteHasProjects) {
+		utils.SendJSONError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/stream"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// streamRetry is the reconnection delay sent to the clients, in milliseconds
+const streamRetry = 3000
+
+// StreamTasks pushes the task events the user can see as server-sent events:
+// managers see the tasks of their organization, the other users the tasks
+// assigned to them, as in GetTasks, and the reassignment of the tasks taken
+// from them. Clients resume with Last-Event-ID; a reset event tells them
+// events were missed and the tasks must be fetched again. The stream ends when
+// the token expires and when the session is no longer valid, checked every
+// stream.RecheckInterval.
+func StreamTasks(c *gin.Context) {
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+	userId := userIdRaw.(uint32)
+	manager := isManager(c)
+	organizationId := organizationIdFromContext(c)
+
+	visible := func(entry stream.Entry) bool {
+		if entry.OrganizationId != organizationId {
+			return false
+		}
+		return manager || entry.UserId == userId || entry.PreviousUserId == userId
+	}
+
+	lastEventId := c.GetHeader("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = c.Query("last_event_id")
+	}
+
+	subscription, resumed := stream.Tasks.Subscribe(lastEventId, visible)
+	defer stream.Tasks.Unsubscribe(subscription)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry)
+	if !resumed {
+		fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+	}
+	for _, entry := range subscription.Backlog {
+		writeStreamEntry(c, entry)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(stream.HeartbeatInterval())
+	defer heartbeat.Stop()
+
+	recheck := time.NewTicker(stream.RecheckInterval())
+	defer recheck.Stop()
+
+	var expired <-chan time.Time
+	if expiresAt, ok := c.Get("tokenExpiresAt"); ok {
+		expiry := time.NewTimer(time.Until(expiresAt.(time.Time)))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-expired:
+			return
+		case <-recheck.C:
+			if !streamSessionValid(c, userId, manager, organizationId) {
+				return
+			}
+			continue
+		case entry, ok := <-subscription.Events:
+			if !ok {
+				// too far behind, the client reconnects and resumes
+				return
+			}
+			writeStreamEntry(c, entry)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+// streamSessionValid checks the stream can go on: the user is still active,
+// with the same role and organization, and its token was not invalidated
+// (password change or reset) nor revoked
+func streamSessionValid(c *gin.Context, userId uint32, manager bool, organizationId uint32) bool {
+	user, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(userId))
+	if err != nil || !user.Active || user.IsManager != manager || user.OrganizationId != organizationId {
+		return false
+	}
+
+	if version, ok := c.Get("tokenVersion"); ok && version.(uint32) != user.TokenVersion {
+		return false
+	}
+
+	if apiTokenId, ok := c.Get("apiTokenId"); ok {
+		tokens, err := repository.ApiTokenRepositoryServices.FindApiTokensByUser(userId)
+		if err != nil {
+			return false
+		}
+		for _, token := range tokens {
+			if token.ID == apiTokenId.(uint32) {
+				return token.RevokedAt == nil
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func writeStreamEntry(c *gin.Context, entry stream.Entry) {
+	fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", entry.ID, entry.Type, entry.Data)
+}
@@ -0,0 +1,248 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/hugohenrick/gtasks/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func streamRouter(w *httptest.ResponseRecorder, isManager bool) (*gin.Context, *gin.Engine) {
+	c, router := gin.CreateTestContext(w)
+	router.Use(func(c *gin.Context) {
+		c.Set("isManager", isManager)
+		c.Set("userId", uint32(2))
+		c.Set("organizationId", uint32(1))
+	})
+
+	routes.AddTaskRoutes(router)
+
+	return c, router
+}
+
+func streamEvents() {
+	stream.Tasks = stream.NewHub(10)
+	stream.Tasks.Publish(stream.Entry{ID: "e1", Type: "task.created", OrganizationId: 1, UserId: 2, Data: []byte(`{"id":"e1"}`)})
+	stream.Tasks.Publish(stream.Entry{ID: "e2", Type: "task.updated", OrganizationId: 1, UserId: 3, Data: []byte(`{"id":"e2"}`)})
+	stream.Tasks.Publish(stream.Entry{ID: "e3", Type: "task.executed", OrganizationId: 1, UserId: 2, Data: []byte(`{"id":"e3"}`)})
+	stream.Tasks.Publish(stream.Entry{ID: "e4", Type: "task.updated", OrganizationId: 5, UserId: 2, Data: []byte(`{"id":"e4"}`)})
+}
+
+// streamRequest returns a request whose client already disconnected, so the
+// stream ends after the backlog
+func streamRequest(lastEventId string) *http.Request {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/task/stream", nil)
+	if lastEventId != "" {
+		request.Header.Set("Last-Event-ID", lastEventId)
+	}
+
+	return request
+}
+
+func TestStreamTasks(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success: technician resumes with the tasks assigned to them", func(t *testing.T) {
+		streamEvents()
+
+		w := httptest.NewRecorder()
+		c, router := streamRouter(w, false)
+
+		// creating a request to send on endpoint call
+		c.Request = streamRequest("e1")
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal("text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal("retry: 3000\n\nid: e3\nevent: task.executed\ndata: {\"id\":\"e3\"}\n\n", w.Body.String())
+	})
+
+	t.Run("Success: manager resumes with the tasks of the organization", func(t *testing.T) {
+		streamEvents()
+
+		w := httptest.NewRecorder()
+		c, router := streamRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request = streamRequest("e1")
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Contains(w.Body.String(), "id: e2\n")
+		assert.Contains(w.Body.String(), "id: e3\n")
+		assert.NotContains(w.Body.String(), "id: e4\n")
+	})
+
+	t.Run("Success: reset when the last event is no longer kept", func(t *testing.T) {
+		streamEvents()
+
+		w := httptest.NewRecorder()
+		c, router := streamRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request = streamRequest("e0")
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.True(strings.HasSuffix(w.Body.String(), "event: reset\ndata: {}\n\n"))
+		assert.NotContains(w.Body.String(), "id: ")
+	})
+
+	t.Run("Success: new stream without backlog", func(t *testing.T) {
+		streamEvents()
+
+		w := httptest.NewRecorder()
+		c, router := streamRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request = streamRequest("")
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal("retry: 3000\n\n", w.Body.String())
+	})
+}
+
+func TestStreamTasksSession(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	sessionRouter := func(w *httptest.ResponseRecorder, tokenExpiresAt time.Time) (*gin.Context, *gin.Engine) {
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+			c.Set("tokenVersion", uint32(1))
+			c.Set("tokenExpiresAt", tokenExpiresAt)
+		})
+
+		routes.AddTaskRoutes(router)
+
+		return c, router
+	}
+
+	t.Run("Success: technician sees the reassignment of a task taken from them", func(t *testing.T) {
+		stream.Tasks = stream.NewHub(10)
+		stream.Tasks.Publish(stream.Entry{ID: "e1", Type: "task.created", OrganizationId: 1, UserId: 2, Data: []byte(`{"id":"e1"}`)})
+		stream.Tasks.Publish(stream.Entry{ID: "e2", Type: "task.updated", OrganizationId: 1, UserId: 3, PreviousUserId: 2, Data: []byte(`{"id":"e2"}`)})
+
+		w := httptest.NewRecorder()
+		c, router := streamRouter(w, false)
+
+		// creating a request to send on endpoint call
+		c.Request = streamRequest("e1")
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Contains(w.Body.String(), "id: e2\n")
+	})
+
+	t.Run("Success: stream ends when the token expires", func(t *testing.T) {
+		stream.Tasks = stream.NewHub(10)
+
+		w := httptest.NewRecorder()
+		c, router := sessionRouter(w, time.Now().Add(50*time.Millisecond))
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task/stream", nil)
+
+		// endpoint call
+		done := make(chan bool)
+		go func() {
+			router.ServeHTTP(w, c.Request)
+			close(done)
+		}()
+
+		// asserts
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream not ended at the token expiration")
+		}
+	})
+
+	t.Run("Success: stream ends when the tokens of the user are invalidated", func(t *testing.T) {
+		t.Setenv("STREAM_RECHECK_SECONDS", "1")
+		stream.Tasks = stream.NewHub(10)
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(models.User{ID: 2, OrganizationId: 1, Active: true, TokenVersion: 2}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := sessionRouter(w, time.Now().Add(time.Hour))
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task/stream", nil)
+
+		// endpoint call
+		done := make(chan bool)
+		go func() {
+			router.ServeHTTP(w, c.Request)
+			close(done)
+		}()
+
+		// asserts
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+			t.Fatal("stream not ended after the tokens were invalidated")
+		}
+		iUserMock.AssertCalled(t, "FindUserById", "2")
+	})
+
+	t.Run("Success: stream ends when the hub is closed", func(t *testing.T) {
+		stream.Tasks = stream.NewHub(10)
+
+		w := httptest.NewRecorder()
+		c, router := sessionRouter(w, time.Now().Add(time.Hour))
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task/stream", nil)
+
+		// endpoint call
+		done := make(chan bool)
+		go func() {
+			router.ServeHTTP(w, c.Request)
+			close(done)
+		}()
+		time.Sleep(50 * time.Millisecond)
+		stream.Tasks.Close()
+
+		// asserts
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream not ended on shutdown")
+		}
+	})
+}
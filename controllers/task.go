@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,14 +9,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/hugohenrick/gtasks/models"
-	"github.com/hugohenrick/gtasks/rabbitmq"
 	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/serializers"
 	"github.com/hugohenrick/gtasks/utils"
 )
 
 func GetTasks(c *gin.Context) {
 	var tasks []models.Task
-	var task models.Task
+	var filter models.TaskFilter
 
 	isManagerRaw, ok := c.Get("isManager")
 	if !ok || isManagerRaw == nil {
@@ -34,25 +33,51 @@ func GetTasks(c *gin.Context) {
 		}
 
 		userId := userIdRaw.(uint32)
-		task.UserId = userId
+		filter.UserId = userId
+	}
+	filter.OrganizationId = organizationIdFromContext(c)
+
+	if err := bindTaskFilter(c, &filter); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
 	}
 
-	tasks, err := repository.TaskRepositoryServices.FindTasks(task)
+	tasks, err := repository.TaskRepositoryServices.FindTasks(filter)
 	if err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
 		return
 	}
 
-	utils.SendJSONResponse(c, http.StatusOK, tasks)
+	sendSerialized(c, http.StatusOK, serializers.NewTaskListResponse(tasks))
 }
 
+// CreateTask creates an open task. Tasks created by technicians require the
+// approval of a manager to be done, managers choose with requires_approval.
 func CreateTask(c *gin.Context) {
-	var task models.Task
-	if err := c.ShouldBindWith(&task, binding.JSON); err != nil {
+	var create models.TaskCreate
+	if err := c.ShouldBindWith(&create, binding.JSON); err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
 		return
 	}
 
+	task := models.Task{
+		Title:            create.Title,
+		Summary:          create.Summary,
+		UserId:           create.UserId,
+		ProjectId:        create.ProjectId,
+		Status:           models.TaskStatusOpen,
+		RequiresApproval: !isManager(c),
+		Priority:         create.Priority,
+		Tags:             create.Tags,
+		Checklist:        create.Checklist,
+		EstimatedMinutes: create.EstimatedMinutes,
+		CustomFields:     create.CustomFields,
+		DueAt:            create.DueAt,
+	}
+	if isManager(c) && create.RequiresApproval != nil {
+		task.RequiresApproval = *create.RequiresApproval
+	}
+
 	if task.Title == "" {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskTitleRequired))
 		return
@@ -61,6 +86,10 @@ func CreateTask(c *gin.Context) {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskSummaryRequired))
 		return
 	}
+	if task.Priority != "" && !utils.ContainsString(models.TaskPriorities, task.Priority) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.TaskPriorityInvalid, task.Priority))
+		return
+	}
 
 	if task.UserId == 0 {
 		userIdRaw, ok := c.Get("userId")
@@ -73,13 +102,34 @@ func CreateTask(c *gin.Context) {
 		task.UserId = userId
 	}
 
-	task, err := repository.TaskRepositoryServices.CreateTask(task)
+	task.OrganizationId = organizationIdFromContext(c)
+
+	if create.UserId != 0 {
+		if err := validateTaskAssignee(task); err != nil {
+			utils.SendJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err := validateTaskProject(task); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	customFields, err := validateTaskCustomFields(task)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	task.CustomFields = customFields
+
+	task, err = repository.TaskRepositoryServices.CreateTask(task, eventMeta(c))
 	if err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
 		return
 	}
 
-	utils.SendJSONResponse(c, http.StatusOK, task)
+	sendSerialized(c, http.StatusOK, serializers.NewTaskResponse(task))
 }
 
 func GetTaskById(c *gin.Context) {
@@ -91,14 +141,17 @@ func GetTaskById(c *gin.Context) {
 	}
 
 	task, err := repository.TaskRepositoryServices.FindTaskById(fmt.Sprint(id))
-	if err != nil {
-		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+	if err != nil || !canSeeTask(c, task) {
+		utils.SendJSONError(c, http.StatusNotFound, fmt.Errorf("%v", utils.TaskNotFound))
 		return
 	}
 
-	utils.SendJSONResponse(c, http.StatusOK, task)
+	sendSerialized(c, http.StatusOK, serializers.NewTaskResponse(task))
 }
 
+// UpdateTask updates a task seen by the user: technicians update the tasks
+// assigned to them, managers the tasks of their organization and may
+// reassign them to another user of the organization.
 func UpdateTask(c *gin.Context) {
 	var task models.Task
 	id := c.Param("id")
@@ -122,6 +175,11 @@ func UpdateTask(c *gin.Context) {
 		return
 	}
 
+	if task.Priority != "" && !utils.ContainsString(models.TaskPriorities, task.Priority) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.TaskPriorityInvalid, task.Priority))
+		return
+	}
+
 	userIdRaw, ok := c.Get("userId")
 	if !ok || userIdRaw == nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
@@ -130,12 +188,47 @@ func UpdateTask(c *gin.Context) {
 
 	userId := userIdRaw.(uint32)
 
-	if task.UserId != userId {
+	if !isManager(c) && task.UserId != userId {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserCannotChangeTaskAnotherUser))
 		return
 	}
 
-	_, err := repository.TaskRepositoryServices.UpdateTask(fmt.Sprint(id), task)
+	current, err := repository.TaskRepositoryServices.FindTaskById(fmt.Sprint(id))
+	if err != nil || !canSeeTask(c, current) {
+		utils.SendJSONError(c, http.StatusNotFound, fmt.Errorf("%v", utils.TaskNotFound))
+		return
+	}
+
+	task.OrganizationId = current.OrganizationId
+	if task.UserId != current.UserId {
+		if err := validateTaskAssignee(task); err != nil {
+			utils.SendJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	task.TemplateVersionId = current.TemplateVersionId
+	if !isManager(c) {
+		task.RequiresApproval = current.RequiresApproval
+	}
+
+	if current.RequiresApproval && task.Done != current.Done {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskRequiresApproval))
+		return
+	}
+
+	if err := validateTaskProject(task); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	customFields, err := validateTaskCustomFields(task)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	task.CustomFields = customFields
+
+	_, err = repository.TaskRepositoryServices.UpdateTask(fmt.Sprint(id), task, eventMeta(c))
 	if err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
 		return
@@ -163,7 +256,13 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	_, err := repository.TaskRepositoryServices.DeleteTask(fmt.Sprint(id))
+	task, err := repository.TaskRepositoryServices.FindTaskById(fmt.Sprint(id))
+	if err != nil || task.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusNotFound, fmt.Errorf("%v", utils.TaskNotFound))
+		return
+	}
+
+	_, err = repository.TaskRepositoryServices.DeleteTask(fmt.Sprint(id), eventMeta(c))
 	if err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
 		return
@@ -199,18 +298,166 @@ func ExecuteTask(c *gin.Context) {
 		return
 	}
 
-	task.Done = true
+	if task.Done || task.Status == models.TaskStatusPendingReview {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskAlreadyExecuted))
+		return
+	}
+
+	// tasks that require approval wait for a manager review before being done
+	if task.RequiresApproval {
+		task.Status = models.TaskStatusPendingReview
+	} else {
+		task.Done = true
+		task.Status = models.TaskStatusDone
+	}
 	timeNow := time.Now()
 	task.FinishedAt = &timeNow
 
-	_, err = repository.TaskRepositoryServices.ExecuteTask(id, task)
+	_, err = repository.TaskRepositoryServices.ExecuteTask(id, task, eventMeta(c))
 	if err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
 		return
 	}
 
 	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
+
+// ReviewTask approves or rejects a task of the organization waiting for
+// review. Only approved tasks are published as executed.
+func ReviewTask(c *gin.Context) {
+	var review models.TaskReviewRequest
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&review, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	review.Comment = strings.TrimSpace(review.Comment)
+	if !review.Approved && review.Comment == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskReviewCommentRequired))
+		return
+	}
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	userId := userIdRaw.(uint32)
+
+	current, err := repository.TaskRepositoryServices.FindTaskById(id)
+	if err != nil || current.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusNotFound, fmt.Errorf("%v", utils.TaskNotFound))
+		return
+	}
+
+	task, err := repository.TaskRepositoryServices.ReviewTask(id, models.TaskReview{
+		ReviewerId: userId,
+		Approved:   review.Approved,
+		Comment:    review.Comment,
+	}, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewTaskResponse(task))
+}
+
+// validateTaskAssignee checks that the user assigned to the task belongs to its organization
+func validateTaskAssignee(task models.Task) error {
+	user, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(task.UserId))
+	if err != nil || user.OrganizationId != task.OrganizationId {
+		return fmt.Errorf("%v", utils.UserNotFound)
+	}
+
+	return nil
+}
+
+// validateTaskProject checks that the project of the task belongs to its organization
+func validateTaskProject(task models.Task) error {
+	if task.ProjectId == nil {
+		return nil
+	}
+
+	project, err := repository.ProjectRepositoryServices.FindProjectById(fmt.Sprint(*task.ProjectId))
+	if err != nil || project.OrganizationId != task.OrganizationId {
+		return fmt.Errorf("%v", utils.ProjectNotFound)
+	}
+
+	return nil
+}
+
+// validateTaskCustomFields checks the task custom fields against the schemas of its organization
+func validateTaskCustomFields(task models.Task) (models.CustomFields, error) {
+	schemas, err := repository.CustomFieldRepositoryServices.FindCustomFields(task.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.ValidateCustomFields(schemas, task.CustomFields)
+}
+
+// bindTaskFilter reads the customer/site/project filters, the custom field
+// filters (cf.<key>=value) and the sort (sort=created_at, sort=-cf.<key>)
+// from the query string.
+func bindTaskFilter(c *gin.Context, filter *models.TaskFilter) error {
+	var err error
+
+	if filter.CustomerId, err = queryUint32(c, "customer_id"); err != nil {
+		return err
+	}
+	if filter.SiteId, err = queryUint32(c, "site_id"); err != nil {
+		return err
+	}
+	if filter.ProjectId, err = queryUint32(c, "project_id"); err != nil {
+		return err
+	}
+
+	for param, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(param, models.CustomFieldSortPrefix) || len(values) == 0 {
+			continue
+		}
+		key := strings.TrimPrefix(param, models.CustomFieldSortPrefix)
+		if !utils.ValidCustomFieldKey(key) {
+			return fmt.Errorf("%v: %v", utils.CustomFieldKeyInvalid, key)
+		}
+		if filter.CustomFields == nil {
+			filter.CustomFields = make(map[string]string)
+		}
+		filter.CustomFields[key] = values[0]
+	}
+
+	sort := strings.TrimSpace(c.Query("sort"))
+	if sort == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(sort, "-") {
+		filter.SortDesc = true
+		sort = strings.TrimPrefix(sort, "-")
+	}
+
+	if strings.HasPrefix(sort, models.CustomFieldSortPrefix) {
+		if !utils.ValidCustomFieldKey(strings.TrimPrefix(sort, models.CustomFieldSortPrefix)) {
+			return fmt.Errorf("%v: %v", utils.TaskSortInvalid, sort)
+		}
+	} else if !utils.ContainsString(models.TaskSortColumns, sort) {
+		return fmt.Errorf("%v: %v", utils.TaskSortInvalid, sort)
+	}
 
-	msg := "The tech " + task.User.Name + " performed the task " + task.Title + " on date " + task.FinishedAt.Format("2006-01-02 15:04:05")
-	rabbitmq.PublishTask(context.Background(), msg)
+	filter.SortBy = sort
+	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	taskMock "github.com/hugohenrick/gtasks/mock"
@@ -71,6 +72,80 @@ func TestGetTasks(t *testing.T) {
 		// asserts
 		assert.Equal(http.StatusOK, w.Code)
 	})
+
+	t.Run("Success: selected fields without user credentials", func(t *testing.T) {
+		expectMsg := `[{"id":1,"title":"Task","user_id":1}]`
+
+		tasks := []models.Task{{
+			ID:     1,
+			Title:  "Task",
+			UserId: 1,
+			User:   models.User{ID: 1, Name: "Tech", Email: "tech@gtasks.com", Password: "$2a$14$hash", Active: true},
+		}}
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTasks", tmock.Anything).Return(tasks, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(3))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task?fields=id,title,user_id", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(expectMsg, w.Body.String())
+
+		w = httptest.NewRecorder()
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task", nil)
+		router.ServeHTTP(w, c.Request)
+
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Contains(w.Body.String(), `"email":"tech@gtasks.com"`)
+		assert.NotContains(w.Body.String(), "$2a$14$hash")
+		iTaskMock.AssertCalled(t, "FindTasks", tmock.MatchedBy(func(filter models.TaskFilter) bool {
+			// managers only list the tasks of their organization
+			return filter.OrganizationId == 3 && filter.UserId == 0
+		}))
+	})
+
+	t.Run("Failed: unknown field selected", func(t *testing.T) {
+		expectMsgError := `{"error":"invalid fields selection: unknown field \"secret\""}`
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTasks", tmock.Anything).Return([]models.Task{}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task?fields=id,secret", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
 }
 
 func TestGetTaskById(t *testing.T) {
@@ -134,6 +209,62 @@ func TestGetTaskById(t *testing.T) {
 		// asserts
 		assert.Equal(http.StatusOK, w.Code)
 	})
+
+	t.Run("Failed: task of another user", func(t *testing.T) {
+		expectMsgError := `{"error":"task not found"}`
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(taskModel, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task/1", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusNotFound, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Failed: task of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"task not found"}`
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, UserId: 1, OrganizationId: 9}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task/1", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusNotFound, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
 }
 
 func TestCreateTask(t *testing.T) {
@@ -221,6 +352,85 @@ func TestCreateTask(t *testing.T) {
 		assert.Equal(expectMsgError, w.Body.String())
 	})
 
+	t.Run("Failed: custom field is required", func(t *testing.T) {
+		// expect error msg
+		expectMsgError := `{"error":"custom field is required: asset_tag"}`
+
+		taskModel := models.Task{
+			ID:      1,
+			Title:   "Test Title",
+			Summary: "Test Summary",
+		}
+
+		iCustomFieldMock := new(taskMock.ICustomFieldRepository)
+		iCustomFieldMock.On("FindCustomFields", uint32(1)).Return([]models.CustomFieldSchema{
+			{OrganizationId: 1, Key: "asset_tag", Type: models.CustomFieldString, Required: true},
+		}, nil)
+		repository.CustomFieldRepositoryServices = iCustomFieldMock
+
+		data, _ := json.Marshal(taskModel)
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Failed: invalid custom field value", func(t *testing.T) {
+		// expect error msg
+		expectMsgError := `{"error":"invalid custom field value: floor: expected a number"}`
+
+		taskModel := models.Task{
+			ID:           1,
+			Title:        "Test Title",
+			Summary:      "Test Summary",
+			CustomFields: models.CustomFields{"floor": "ground"},
+		}
+
+		iCustomFieldMock := new(taskMock.ICustomFieldRepository)
+		iCustomFieldMock.On("FindCustomFields", uint32(1)).Return([]models.CustomFieldSchema{
+			{OrganizationId: 1, Key: "floor", Type: models.CustomFieldNumber},
+		}, nil)
+		repository.CustomFieldRepositoryServices = iCustomFieldMock
+
+		data, _ := json.Marshal(taskModel)
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
 	t.Run("Succes: create new task", func(t *testing.T) {
 		taskModel := models.Task{
 			ID:      1,
@@ -229,9 +439,13 @@ func TestCreateTask(t *testing.T) {
 		}
 
 		iTaskMock := new(taskMock.ITaskRepository)
-		iTaskMock.On("CreateTask", tmock.Anything).Return(taskModel, nil)
+		iTaskMock.On("CreateTask", tmock.Anything, tmock.Anything).Return(taskModel, nil)
 		repository.TaskRepositoryServices = iTaskMock
 
+		iCustomFieldMock := new(taskMock.ICustomFieldRepository)
+		iCustomFieldMock.On("FindCustomFields", tmock.Anything).Return([]models.CustomFieldSchema{}, nil)
+		repository.CustomFieldRepositoryServices = iCustomFieldMock
+
 		data, _ := json.Marshal(taskModel)
 		body := bytes.NewBuffer(data)
 
@@ -252,6 +466,113 @@ func TestCreateTask(t *testing.T) {
 		// asserts
 		assert.Equal(http.StatusOK, w.Code)
 	})
+
+	t.Run("Success: technician task created open and requiring approval", func(t *testing.T) {
+		iCustomFieldMock := new(taskMock.ICustomFieldRepository)
+		iCustomFieldMock.On("FindCustomFields", uint32(1)).Return([]models.CustomFieldSchema{}, nil)
+		repository.CustomFieldRepositoryServices = iCustomFieldMock
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("CreateTask", tmock.Anything, tmock.Anything).Return(models.Task{ID: 1, Title: "Test Title"}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		body := bytes.NewBufferString(`{"title":"Test Title","summary":"Test Summary","done":true,"status":"done","requires_approval":false}`)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iTaskMock.AssertCalled(t, "CreateTask", tmock.MatchedBy(func(task models.Task) bool {
+			return !task.Done && task.Status == models.TaskStatusOpen && task.RequiresApproval && task.UserId == 2 && task.OrganizationId == 1
+		}), tmock.Anything)
+	})
+
+	t.Run("Success: manager chooses requires_approval", func(t *testing.T) {
+		iCustomFieldMock := new(taskMock.ICustomFieldRepository)
+		iCustomFieldMock.On("FindCustomFields", uint32(1)).Return([]models.CustomFieldSchema{}, nil)
+		repository.CustomFieldRepositoryServices = iCustomFieldMock
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("CreateTask", tmock.Anything, tmock.Anything).Return(models.Task{ID: 1, Title: "Test Title"}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "3").Return(models.User{ID: 3, OrganizationId: 1}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		body := bytes.NewBufferString(`{"title":"Test Title","summary":"Test Summary","user_id":3,"requires_approval":false}`)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iTaskMock.AssertCalled(t, "CreateTask", tmock.MatchedBy(func(task models.Task) bool {
+			return !task.RequiresApproval && task.Status == models.TaskStatusOpen && task.UserId == 3
+		}), tmock.Anything)
+	})
+
+	t.Run("Failed: assignee of another organization", func(t *testing.T) {
+		// expect error msg
+		expectMsgError := `{"error":"user not found"}`
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		repository.TaskRepositoryServices = iTaskMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "3").Return(models.User{ID: 3, OrganizationId: 9}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		body := bytes.NewBufferString(`{"title":"Test Title","summary":"Test Summary","user_id":3}`)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iTaskMock.AssertNotCalled(t, "CreateTask", tmock.Anything, tmock.Anything)
+	})
 }
 
 func TestUpdateTask(t *testing.T) {
@@ -379,9 +700,14 @@ func TestUpdateTask(t *testing.T) {
 		}
 
 		iTaskMock := new(taskMock.ITaskRepository)
-		iTaskMock.On("UpdateTask", tmock.Anything, tmock.Anything).Return(taskModel, nil)
+		iTaskMock.On("FindTaskById", tmock.Anything).Return(taskModel, nil)
+		iTaskMock.On("UpdateTask", tmock.Anything, tmock.Anything, tmock.Anything).Return(taskModel, nil)
 		repository.TaskRepositoryServices = iTaskMock
 
+		iCustomFieldMock := new(taskMock.ICustomFieldRepository)
+		iCustomFieldMock.On("FindCustomFields", tmock.Anything).Return([]models.CustomFieldSchema{}, nil)
+		repository.CustomFieldRepositoryServices = iCustomFieldMock
+
 		data, _ := json.Marshal(taskModel)
 		body := bytes.NewBuffer(data)
 
@@ -402,6 +728,72 @@ func TestUpdateTask(t *testing.T) {
 		// asserts
 		assert.Equal(http.StatusOK, w.Code)
 	})
+
+	t.Run("Failed: task of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"task not found"}`
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, UserId: 1, OrganizationId: 9}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		body := bytes.NewBufferString(`{"title":"Test Title","summary":"Test Summary","user_id":1}`)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/task/1", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusNotFound, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iTaskMock.AssertNotCalled(t, "UpdateTask", tmock.Anything, tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Failed: manager reassigns to a user of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"user not found"}`
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, UserId: 1, OrganizationId: 1}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "3").Return(models.User{ID: 3, OrganizationId: 9}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		body := bytes.NewBufferString(`{"title":"Test Title","summary":"Test Summary","user_id":3}`)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/task/1", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iTaskMock.AssertNotCalled(t, "UpdateTask", tmock.Anything, tmock.Anything, tmock.Anything)
+	})
 }
 
 func TestDeleteTask(t *testing.T) {
@@ -414,7 +806,7 @@ func TestDeleteTask(t *testing.T) {
 
 		iTaskMock := new(taskMock.ITaskRepository)
 		var numRecord int64 = 1
-		iTaskMock.On("DeleteTask", tmock.Anything).Return(numRecord, nil)
+		iTaskMock.On("DeleteTask", tmock.Anything, tmock.Anything).Return(numRecord, nil)
 		repository.TaskRepositoryServices = iTaskMock
 
 		w := httptest.NewRecorder()
@@ -438,7 +830,7 @@ func TestDeleteTask(t *testing.T) {
 		expectMsgError := `{"error":"user without access permission"}`
 
 		iTaskMock := new(taskMock.ITaskRepository)
-		iTaskMock.On("DeleteTask", tmock.Anything).Return(numRecord, nil)
+		iTaskMock.On("DeleteTask", tmock.Anything, tmock.Anything).Return(numRecord, nil)
 		repository.TaskRepositoryServices = iTaskMock
 
 		w := httptest.NewRecorder()
@@ -465,7 +857,7 @@ func TestDeleteTask(t *testing.T) {
 		expectMsgError := `{"error":"user id is required"}`
 
 		iTaskMock := new(taskMock.ITaskRepository)
-		iTaskMock.On("DeleteTask", tmock.Anything).Return(numRecord, nil)
+		iTaskMock.On("DeleteTask", tmock.Anything, tmock.Anything).Return(numRecord, nil)
 		repository.TaskRepositoryServices = iTaskMock
 
 		w := httptest.NewRecorder()
@@ -490,7 +882,8 @@ func TestDeleteTask(t *testing.T) {
 
 	t.Run("Success: expect correct result", func(t *testing.T) {
 		iTaskMock := new(taskMock.ITaskRepository)
-		iTaskMock.On("DeleteTask", tmock.Anything).Return(numRecord, nil)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, OrganizationId: 1}, nil)
+		iTaskMock.On("DeleteTask", tmock.Anything, tmock.Anything).Return(numRecord, nil)
 		repository.TaskRepositoryServices = iTaskMock
 
 		w := httptest.NewRecorder()
@@ -498,6 +891,7 @@ func TestDeleteTask(t *testing.T) {
 		router.Use(func(c *gin.Context) {
 			c.Set("isManager", true)
 			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
 		})
 
 		router.Use(func(c *gin.Context) {})
@@ -513,6 +907,35 @@ func TestDeleteTask(t *testing.T) {
 		// asserts
 		assert.Equal(http.StatusOK, w.Code)
 	})
+
+	t.Run("Failed: task of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"task not found"}`
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, OrganizationId: 9}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/task/1", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusNotFound, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iTaskMock.AssertNotCalled(t, "DeleteTask", tmock.Anything, tmock.Anything)
+	})
 }
 
 func TestExecuteTask(t *testing.T) {
@@ -553,9 +976,17 @@ func TestExecuteTask(t *testing.T) {
 
 		iTaskMock := new(taskMock.ITaskRepository)
 		iTaskMock.On("FindTaskById", tmock.Anything).Return(taskModel, nil)
-		iTaskMock.On("ExecuteTask", tmock.Anything, tmock.Anything).Return(taskModel, nil)
+		iTaskMock.On("ExecuteTask", tmock.Anything, tmock.Anything, tmock.Anything).Return(taskModel, nil)
 		repository.TaskRepositoryServices = iTaskMock
 
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", tmock.Anything).Return([]models.TaskWatcher{}, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", tmock.Anything).Return([]models.NotificationPreference{}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
 		data, _ := json.Marshal(taskModel)
 		body := bytes.NewBuffer(data)
 
@@ -577,3 +1008,142 @@ func TestExecuteTask(t *testing.T) {
 		assert.Equal(http.StatusOK, w.Code)
 	})
 }
+
+func TestReviewTask(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Failed: user without access permission", func(t *testing.T) {
+		expectMsgError := `{"error":"user without access permission"}`
+
+		data, _ := json.Marshal(models.TaskReviewRequest{Approved: true})
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task/1/review", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Failed: a comment is required to reject a task", func(t *testing.T) {
+		expectMsgError := `{"error":"a comment is required to reject a task"}`
+
+		data, _ := json.Marshal(models.TaskReviewRequest{Approved: false, Comment: " "})
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task/1/review", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Succes: approve task", func(t *testing.T) {
+		timeNow := time.Now()
+		taskModel := models.Task{
+			ID:               1,
+			Title:            "Test Title",
+			Summary:          "Test Summary",
+			UserId:           1,
+			Done:             true,
+			Status:           models.TaskStatusDone,
+			RequiresApproval: true,
+			FinishedAt:       &timeNow,
+		}
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, OrganizationId: 1, Status: models.TaskStatusPendingReview}, nil)
+		iTaskMock.On("ReviewTask", "1", models.TaskReview{ReviewerId: 2, Approved: true}, tmock.Anything).Return(taskModel, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", tmock.Anything).Return([]models.TaskWatcher{}, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", tmock.Anything).Return([]models.NotificationPreference{}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		data, _ := json.Marshal(models.TaskReviewRequest{Approved: true})
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task/1/review", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iTaskMock.AssertExpectations(t)
+	})
+
+	t.Run("Failed: task of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"task not found"}`
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, OrganizationId: 9, Status: models.TaskStatusPendingReview}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		data, _ := json.Marshal(models.TaskReviewRequest{Approved: true})
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTaskRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task/1/review", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusNotFound, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iTaskMock.AssertNotCalled(t, "ReviewTask", tmock.Anything, tmock.Anything, tmock.Anything)
+	})
+}
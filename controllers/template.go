@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+func GetTemplates(c *gin.Context) {
+	templates, err := repository.TemplateRepositoryServices.FindTemplates(organizationIdFromContext(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, templates)
+}
+
+func GetTemplateById(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TemplateIdRequired))
+		return
+	}
+
+	template, err := repository.TemplateRepositoryServices.FindTemplateById(id)
+	if err != nil || template.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TemplateNotFound))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, template)
+}
+
+func CreateTemplate(c *gin.Context) {
+	var register models.TaskTemplateRegister
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	if err := c.ShouldBindWith(&register, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	if register.Name == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TemplateNameRequired))
+		return
+	}
+
+	version, err := templateVersionFromRegister(register)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	template, err := repository.TemplateRepositoryServices.CreateTemplate(models.TaskTemplate{
+		OrganizationId: organizationIdFromContext(c),
+		Name:           register.Name,
+		Versions:       []models.TaskTemplateVersion{version},
+	}, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, template)
+}
+
+// UpdateTemplate stores the changes as a new version of the template, so
+// tasks already created from it are not altered.
+func UpdateTemplate(c *gin.Context) {
+	var register models.TaskTemplateRegister
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TemplateIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&register, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	template, err := repository.TemplateRepositoryServices.FindTemplateById(id)
+	if err != nil || template.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TemplateNotFound))
+		return
+	}
+
+	version, err := templateVersionFromRegister(register)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	version, err = repository.TemplateRepositoryServices.CreateTemplateVersion(id, version, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, version)
+}
+
+func CreateTaskFromTemplate(c *gin.Context) {
+	var request models.TaskFromTemplate
+
+	templateId := strings.TrimSpace(c.Param("templateId"))
+	if templateId == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TemplateIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&request, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	assigned := request.UserId != 0
+	if !assigned {
+		userIdRaw, ok := c.Get("userId")
+		if !ok || userIdRaw == nil {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+			return
+		}
+
+		request.UserId = userIdRaw.(uint32)
+	}
+
+	template, err := repository.TemplateRepositoryServices.FindTemplateById(templateId)
+	if err != nil || template.OrganizationId != organizationIdFromContext(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TemplateNotFound))
+		return
+	}
+
+	version, err := repository.TemplateRepositoryServices.FindLatestTemplateVersion(templateId)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	task, err := taskFromTemplateVersion(version, request.Variables)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	task.UserId = request.UserId
+	task.OrganizationId = template.OrganizationId
+	task.CustomFields = request.CustomFields
+
+	if assigned {
+		if err := validateTaskAssignee(task); err != nil {
+			utils.SendJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	customFields, err := validateTaskCustomFields(task)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	task.CustomFields = customFields
+
+	task, err = repository.TaskRepositoryServices.CreateTask(task, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewTaskResponse(task))
+}
+
+func templateVersionFromRegister(register models.TaskTemplateRegister) (models.TaskTemplateVersion, error) {
+	if register.Title == "" {
+		return models.TaskTemplateVersion{}, fmt.Errorf("%v", utils.TaskTitleRequired)
+	}
+	if register.Summary == "" {
+		return models.TaskTemplateVersion{}, fmt.Errorf("%v", utils.TaskSummaryRequired)
+	}
+	if register.Priority != "" && !utils.ContainsString(models.TaskPriorities, register.Priority) {
+		return models.TaskTemplateVersion{}, fmt.Errorf("%v: %v", utils.TaskPriorityInvalid, register.Priority)
+	}
+
+	return models.TaskTemplateVersion{
+		Title:            register.Title,
+		Summary:          register.Summary,
+		Checklist:        register.Checklist,
+		Priority:         register.Priority,
+		Tags:             register.Tags,
+		EstimatedMinutes: register.EstimatedMinutes,
+		RequiresApproval: register.RequiresApproval,
+	}, nil
+}
+
+// taskFromTemplateVersion fills a task with the template defaults, replacing
+// the {{variable}} placeholders of the title, summary and checklist.
+func taskFromTemplateVersion(version models.TaskTemplateVersion, variables map[string]string) (models.Task, error) {
+	var missing []string
+
+	title, m := utils.RenderPlaceholders(version.Title, variables)
+	missing = append(missing, m...)
+
+	summary, m := utils.RenderPlaceholders(version.Summary, variables)
+	missing = append(missing, m...)
+
+	var checklist models.Checklist
+	for _, item := range version.Checklist {
+		text, m := utils.RenderPlaceholders(item, variables)
+		missing = append(missing, m...)
+		checklist = append(checklist, models.ChecklistItem{Text: text})
+	}
+
+	if len(missing) > 0 {
+		return models.Task{}, fmt.Errorf("%v: %v", utils.TemplateVariableMissing, utils.MissingPlaceholders(missing))
+	}
+
+	versionId := version.ID
+
+	return models.Task{
+		Title:             title,
+		Summary:           summary,
+		Checklist:         checklist,
+		Priority:          version.Priority,
+		Tags:              version.Tags,
+		EstimatedMinutes:  version.EstimatedMinutes,
+		RequiresApproval:  version.RequiresApproval,
+		TemplateVersionId: &versionId,
+	}, nil
+}
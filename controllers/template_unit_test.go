@@ -0,0 +1,161 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func TestCreateTaskFromTemplate(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	templateModel := models.TaskTemplate{
+		ID:             1,
+		OrganizationId: 1,
+		Name:           "HVAC inspection",
+		LatestVersion:  2,
+	}
+
+	versionModel := models.TaskTemplateVersion{
+		ID:               5,
+		TemplateId:       1,
+		Version:          2,
+		Title:            "Quarterly HVAC inspection at {{site}}",
+		Summary:          "Inspect every unit of {{ site }}",
+		Checklist:        models.StringSlice{"Check filters on floor {{floor}}"},
+		Priority:         models.TaskPriorityHigh,
+		EstimatedMinutes: 90,
+	}
+
+	t.Run("Failed: template variables without value", func(t *testing.T) {
+		// expect error msg
+		expectMsgError := `{"error":"template variables without value: site, floor"}`
+
+		iTemplateMock := new(taskMock.ITemplateRepository)
+		iTemplateMock.On("FindTemplateById", "1").Return(templateModel, nil)
+		iTemplateMock.On("FindLatestTemplateVersion", "1").Return(versionModel, nil)
+		repository.TemplateRepositoryServices = iTemplateMock
+
+		data, _ := json.Marshal(models.TaskFromTemplate{})
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTemplateRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task/from-template/1", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Succes: create task from template", func(t *testing.T) {
+		iTemplateMock := new(taskMock.ITemplateRepository)
+		iTemplateMock.On("FindTemplateById", "1").Return(templateModel, nil)
+		iTemplateMock.On("FindLatestTemplateVersion", "1").Return(versionModel, nil)
+		repository.TemplateRepositoryServices = iTemplateMock
+
+		iCustomFieldMock := new(taskMock.ICustomFieldRepository)
+		iCustomFieldMock.On("FindCustomFields", uint32(1)).Return([]models.CustomFieldSchema{}, nil)
+		repository.CustomFieldRepositoryServices = iCustomFieldMock
+
+		var created models.Task
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("CreateTask", tmock.Anything, tmock.Anything).Run(func(args tmock.Arguments) {
+			created = args.Get(0).(models.Task)
+		}).Return(models.Task{ID: 1}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		data, _ := json.Marshal(models.TaskFromTemplate{
+			Variables: map[string]string{"site": "Plant 2", "floor": "3"},
+		})
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTemplateRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task/from-template/1", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal("Quarterly HVAC inspection at Plant 2", created.Title)
+		assert.Equal("Inspect every unit of Plant 2", created.Summary)
+		assert.Equal(models.Checklist{{Text: "Check filters on floor 3"}}, created.Checklist)
+		assert.Equal(models.TaskPriorityHigh, created.Priority)
+		assert.Equal(uint32(1), created.UserId)
+		assert.Equal(uint32(5), *created.TemplateVersionId)
+	})
+
+	t.Run("Failed: assignee of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"user not found"}`
+
+		iTemplateMock := new(taskMock.ITemplateRepository)
+		iTemplateMock.On("FindTemplateById", "1").Return(templateModel, nil)
+		iTemplateMock.On("FindLatestTemplateVersion", "1").Return(versionModel, nil)
+		repository.TemplateRepositoryServices = iTemplateMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "7").Return(models.User{ID: 7, OrganizationId: 9}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iTaskMock := new(taskMock.ITaskRepository)
+		repository.TaskRepositoryServices = iTaskMock
+
+		data, _ := json.Marshal(models.TaskFromTemplate{
+			UserId:    7,
+			Variables: map[string]string{"site": "Plant 2", "floor": "3"},
+		})
+		body := bytes.NewBuffer(data)
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddTemplateRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task/from-template/1", body)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iTaskMock.AssertNotCalled(t, "CreateTask", tmock.Anything, tmock.Anything)
+	})
+}
@@ -1,53 +1,119 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/golang-jwt/jwt"
+	"github.com/hugohenrick/gtasks/auth"
 	"github.com/hugohenrick/gtasks/models"
 	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/serializers"
 	"github.com/hugohenrick/gtasks/utils"
 )
 
 func CreateUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindWith(&user, binding.JSON); err != nil {
+	var register models.UserRegister
+	if err := c.ShouldBindWith(&register, binding.JSON); err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
 		return
 	}
 
-	hashPassword, _ := utils.HashPassword(user.Password)
-	user.Password = hashPassword
+	email := utils.NormalizeEmail(register.Email)
+	if !utils.ValidEmail(email) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserEmailInvalid))
+		return
+	}
+
+	if err := auth.DefaultPasswordPolicy.Validate(register.Password); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := repository.UserRepositoryServices.FindUserByEmail(email); err == nil {
+		utils.SendJSONError(c, http.StatusConflict, utils.ErrEmailTaken)
+		return
+	}
 
-	user, err := repository.UserRepositoryServices.CreateUser(user)
+	hashPassword, err := utils.HashPassword(register.Password)
+	if err != nil {
+		log.Printf("error hashing password: %s\n", err)
+		utils.SendJSONError(c, http.StatusInternalServerError, fmt.Errorf("%v", utils.UserFailedHashPassword))
+		return
+	}
+
+	// self-signup creates technicians, managers of the organization grant the role
+	user, err := repository.UserRepositoryServices.CreateUser(models.User{
+		Name:           register.Name,
+		Email:          email,
+		Password:       hashPassword,
+		OrganizationId: signupOrganizationId(),
+	}, eventMeta(c))
+	if errors.Is(err, utils.ErrEmailTaken) {
+		utils.SendJSONError(c, http.StatusConflict, err)
+		return
+	}
 	if err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
 		return
 	}
 
-	utils.SendJSONResponse(c, http.StatusOK, user)
+	err = sendUserToken(c.Request.Context(), user, models.UserTokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		log.Printf("error sending email verification: %s\n", err)
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewUserResponse(user))
+}
+
+// signupOrganizationId is the organization of the users who sign up,
+// SIGNUP_ORGANIZATION_ID (none by default)
+func signupOrganizationId() uint32 {
+	value, err := strconv.ParseUint(os.Getenv("SIGNUP_ORGANIZATION_ID"), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(value)
 }
 
+// GetUsers lists the users of the organization of the manager, of every
+// organization for the administrators
 func GetUsers(c *gin.Context) {
 	var users []models.User
 
-	users, err := repository.UserRepositoryServices.FindUsers()
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	organizationId := organizationIdFromContext(c)
+	filter := &organizationId
+	if isAdmin(c) {
+		filter = nil
+	}
+
+	users, err := repository.UserRepositoryServices.FindUsers(filter)
 	if err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
 		return
 	}
 
-	utils.SendJSONResponse(c, http.StatusOK, users)
+	sendSerialized(c, http.StatusOK, serializers.NewUserListResponse(users))
 }
 
 //LoginUser : Generates JWT Token for validated user
 func LoginUser(c *gin.Context) {
 	var user models.UserLogin
-	var hmacSampleSecret []byte
 
 	if err := c.ShouldBindWith(&user, binding.JSON); err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
@@ -55,34 +121,545 @@ func LoginUser(c *gin.Context) {
 	}
 
 	var dbUser models.User
-	email := user.Email
+	email := utils.NormalizeEmail(user.Email)
 	password := user.Password
 
+	if !checkLoginThrottle(c, email) {
+		return
+	}
+
+	// unknown emails and wrong passwords get the same response, and take the
+	// same time thanks to the comparison with a dummy hash
 	dbUser, err := repository.UserRepositoryServices.FindUserByEmail(email)
 	if err != nil {
-		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.UserNotFound, err))
+		utils.CheckPasswordHash(password, dummyPasswordHash())
+		recordLoginAttempt(c, email, nil, models.LoginInvalidCredentials)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserInvalidCredentials))
 		return
 	}
 
 	hashErr := utils.CheckPasswordHash(password, dbUser.Password)
 	if hashErr != nil {
-		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.UserInvalidCredentials, err))
+		recordLoginAttempt(c, email, &dbUser.ID, models.LoginInvalidCredentials)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserInvalidCredentials))
+		return
+	}
+
+	if !dbUser.Active {
+		recordLoginAttempt(c, email, &dbUser.ID, models.LoginUserDeactivated)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserDeactivated))
+		return
+	}
+
+	rehashPassword(dbUser, password)
+
+	if passwordLoginDisabled(dbUser) {
+		recordLoginAttempt(c, email, &dbUser.ID, models.LoginPasswordDisabled)
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserPasswordLoginDisabled))
+		return
+	}
+
+	// with MFA the password only gets a challenge token, exchanged for the JWT by LoginMfa
+	if dbUser.MfaEnabled {
+		mfaToken, err := generateMfaChallenge(dbUser)
+		if err != nil {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.UserFailedGetToken, err))
+			return
+		}
+
+		recordLoginAttempt(c, email, &dbUser.ID, models.LoginMfaChallenged)
+
+		utils.SendJSONResponse(c, http.StatusOK, gin.H{
+			"message":      utils.UserMfaCodeRequired,
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+		})
+		return
+	}
+
+	sendLoginToken(c, email, dbUser)
+}
+
+// sendLoginToken completes a login. Users the policy requires MFA from get a
+// token that only allows the MFA enrollment until they enroll.
+func sendLoginToken(c *gin.Context, email string, user models.User) {
+	enrollment := auth.MfaRequired(user) && !user.MfaEnabled
+
+	tokenString, err := generateToken(user)
+	if enrollment {
+		tokenString, err = generateEnrollmentToken(user)
+	}
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.UserFailedGetToken, err))
 		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user": dbUser,
+	recordLoginAttempt(c, email, &user.ID, models.LoginSucceeded)
+
+	response := gin.H{
+		"message": "Token generated sucessfully",
+		"token":   tokenString,
+	}
+	if enrollment {
+		response["mfa_enrollment_required"] = true
+	}
+
+	utils.SendJSONResponse(c, http.StatusCreated, response)
+}
+
+// rehashPassword replaces a hash made with outdated parameters once the
+// password is known to be right. The tokens already issued keep working.
+func rehashPassword(user models.User, password string) {
+	if !utils.PasswordNeedsRehash(user.Password) {
+		return
+	}
+
+	hashPassword, err := utils.HashPassword(password)
+	if err == nil {
+		err = repository.UserRepositoryServices.UpdatePasswordHash(fmt.Sprint(user.ID), hashPassword)
+	}
+	if err != nil {
+		log.Printf("error rehashing password of user %d: %s\n", user.ID, err)
+	}
+}
+
+// passwordLoginDisabled reports whether the organization of the user only
+// allows the single sign-on
+func passwordLoginDisabled(user models.User) bool {
+	if user.OrganizationId == 0 {
+		return false
+	}
+
+	organization, err := repository.OrganizationRepositoryServices.FindOrganizationById(user.OrganizationId)
+	if err != nil {
+		log.Printf("error finding organization %d: %s\n", user.OrganizationId, err)
+		return false
+	}
+
+	return organization.PasswordLoginDisabled
+}
+
+// checkLoginThrottle refuses a login attempt on email made before the wait
+// imposed by the previous failures, with 429 and Retry-After. The refused
+// attempts are not recorded, so they don't extend the wait. When the failures
+// can't be counted the attempt is refused too.
+func checkLoginThrottle(c *gin.Context, email string) bool {
+	wait, err := auth.LoginRetryAfter(email, c.ClientIP(), time.Now())
+	if err != nil {
+		log.Printf("error checking login attempts: %s\n", err)
+		utils.SendJSONError(c, http.StatusServiceUnavailable, fmt.Errorf("%v", utils.UserLoginUnavailable))
+		return false
+	}
+
+	if wait > 0 {
+		c.Header("Retry-After", fmt.Sprint(int(math.Ceil(wait.Seconds()))))
+		utils.SendJSONError(c, http.StatusTooManyRequests, fmt.Errorf("%v", utils.UserTooManyLoginAttempts))
+		return false
+	}
+
+	return true
+}
+
+// recordLoginAttempt adds the attempt to the login security log
+func recordLoginAttempt(c *gin.Context, email string, userId *uint32, result string) {
+	_, err := repository.LoginAttemptRepositoryServices.CreateLoginAttempt(models.LoginAttempt{
+		UserId:    userId,
+		Email:     email,
+		IP:        c.ClientIP(),
+		UserAgent: truncate(c.Request.UserAgent(), 500),
+		Success:   result == models.LoginSucceeded,
+		Result:    result,
+	})
+	if err != nil {
+		log.Printf("error recording login attempt: %s\n", err)
+	}
+}
+
+var (
+	dummyHash     string
+	dummyHashOnce sync.Once
+)
+
+// dummyPasswordHash is compared against when the email is unknown
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = utils.HashPassword("gtasks-dummy-password")
+	})
+	return dummyHash
+}
+
+func truncate(value string, size int) string {
+	if len(value) > size {
+		return value[:size]
+	}
+	return value
+}
+
+// generateToken signs the JWT of the user. The token is refused by the
+// middlewares once the token version of the user changes.
+func generateToken(user models.User) (string, error) {
+	return signToken(jwt.MapClaims{
+		"user": serializers.NewUserResponse(user),
+		"ver":  user.TokenVersion,
 		"exp":  time.Now().Add(time.Minute * 30).Unix(),
 	})
+}
+
+// generateEnrollmentToken signs a JWT that only allows the MFA enrollment
+func generateEnrollmentToken(user models.User) (string, error) {
+	return signToken(jwt.MapClaims{
+		"user":       serializers.NewUserResponse(user),
+		"ver":        user.TokenVersion,
+		"mfa_enroll": true,
+		"exp":        time.Now().Add(time.Minute * 30).Unix(),
+	})
+}
+
+// signToken signs the claims with the key the middlewares verify the tokens with
+func signToken(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(os.Getenv("SECRET")))
+}
+
+func GetMe(c *gin.Context) {
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	user, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(userIdRaw))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewUserResponse(user))
+}
+
+func UpdateMe(c *gin.Context) {
+	var profile models.UserProfile
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	if err := c.ShouldBindWith(&profile, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	id := fmt.Sprint(userIdRaw)
+
+	user, err := repository.UserRepositoryServices.FindUserById(id)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
 
-	tokenString, err := token.SignedString(hmacSampleSecret)
+	if profile.Name != nil {
+		if strings.TrimSpace(*profile.Name) == "" {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNameRequired))
+			return
+		}
+		user.Name = strings.TrimSpace(*profile.Name)
+	}
+	if profile.Timezone != nil {
+		if !utils.ValidTimezone(*profile.Timezone) {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserTimezoneInvalid))
+			return
+		}
+		user.Timezone = *profile.Timezone
+	}
+	if profile.Locale != nil {
+		if !utils.ValidLocale(*profile.Locale) {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserLocaleInvalid))
+			return
+		}
+		user.Locale = *profile.Locale
+	}
+	if profile.AvatarUrl != nil {
+		if !utils.ValidAvatarUrl(*profile.AvatarUrl) {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserAvatarUrlInvalid))
+			return
+		}
+		user.AvatarUrl = *profile.AvatarUrl
+	}
+
+	user, err = repository.UserRepositoryServices.UpdateProfile(id, user, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewUserResponse(user))
+}
+
+// ChangeMyPassword changes the password of the authenticated user. The tokens
+// already issued stop working and a new token is returned.
+func ChangeMyPassword(c *gin.Context) {
+	var change models.UserPasswordChange
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	if err := c.ShouldBindWith(&change, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	id := fmt.Sprint(userIdRaw)
+
+	user, err := repository.UserRepositoryServices.FindUserById(id)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	if err := utils.CheckPasswordHash(change.CurrentPassword, user.Password); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserCurrentPasswordInvalid))
+		return
+	}
+
+	if err := auth.DefaultPasswordPolicy.Validate(change.NewPassword); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	hashPassword, err := utils.HashPassword(change.NewPassword)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	user, err = repository.UserRepositoryServices.UpdatePassword(id, hashPassword)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	tokenString, err := generateToken(user)
 	if err != nil {
 		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.UserFailedGetToken, err))
 		return
 	}
 
-	utils.SendJSONResponse(c, http.StatusCreated, gin.H{
-		"message": "Token generated sucessfully",
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"message": "Password changed sucessfully",
 		"token":   tokenString,
 	})
 }
+
+func GetUserById(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserIdRequired))
+		return
+	}
+
+	user, err := repository.UserRepositoryServices.FindUserById(id)
+	if err != nil || !canManageUser(c, user) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewUserResponse(user))
+}
+
+func UpdateUser(c *gin.Context) {
+	var update models.UserUpdate
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&update, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	user, err := repository.UserRepositoryServices.FindUserById(id)
+	if err != nil || !canManageUser(c, user) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	previousEmail := user.Email
+	if update.Name != nil {
+		user.Name = *update.Name
+	}
+	if update.Email != nil {
+		user.Email = utils.NormalizeEmail(*update.Email)
+		if !utils.ValidEmail(user.Email) {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserEmailInvalid))
+			return
+		}
+	}
+	if update.IsManager != nil {
+		user.IsManager = *update.IsManager
+	}
+
+	user, err = repository.UserRepositoryServices.UpdateUser(id, user, eventMeta(c))
+	if errors.Is(err, utils.ErrEmailTaken) {
+		utils.SendJSONError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	// the new email has to be verified again, e.g. before an SSO identity is linked to it
+	if user.Email != previousEmail {
+		err = sendUserToken(c.Request.Context(), user, models.UserTokenEmailVerification, emailVerificationTTL)
+		if err != nil {
+			log.Printf("error sending email verification: %s\n", err)
+		}
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewUserResponse(user))
+}
+
+func DeleteUser(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserIdRequired))
+		return
+	}
+
+	user, err := repository.UserRepositoryServices.FindUserById(id)
+	if err != nil || !canManageUser(c, user) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	_, err = repository.UserRepositoryServices.DeleteUser(id, eventMeta(c))
+	if errors.Is(err, utils.ErrUserHasTasks) {
+		utils.SendJSONError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
+
+// DeactivateUser refuses the logins of a user who left, invalidates the tokens
+// already issued and reassigns the open tasks to reassign_to. Tasks are preserved.
+func DeactivateUser(c *gin.Context) {
+	var deactivate models.UserDeactivate
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&deactivate, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	userId := userIdRaw.(uint32)
+
+	user, err := repository.UserRepositoryServices.FindUserById(id)
+	if err != nil || !canManageUser(c, user) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	if user.ID == userId {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserCannotDeactivateItself))
+		return
+	}
+
+	if deactivate.ReassignTo != 0 {
+		assignee, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(deactivate.ReassignTo))
+		if err != nil || assignee.ID == user.ID || !assignee.Active || !canManageUser(c, assignee) {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserReassignToInvalid))
+			return
+		}
+	}
+
+	reassigned, err := repository.UserRepositoryServices.DeactivateUser(id, deactivate.ReassignTo, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, gin.H{
+		"message":          "success",
+		"reassigned_tasks": len(reassigned),
+	})
+}
+
+func ActivateUser(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserIdRequired))
+		return
+	}
+
+	user, err := repository.UserRepositoryServices.FindUserById(id)
+	if err != nil || !canManageUser(c, user) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserNotFound))
+		return
+	}
+
+	user, err = repository.UserRepositoryServices.ActivateUser(id, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewUserResponse(user))
+}
+
+// canManageUser reports whether the authenticated user can see and manage user:
+// managers manage the users of their organization, other users only themselves.
+func canManageUser(c *gin.Context, user models.User) bool {
+	if isManager(c) {
+		return user.OrganizationId == organizationIdFromContext(c)
+	}
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		return false
+	}
+	return user.ID == userIdRaw.(uint32)
+}
@@ -0,0 +1,868 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/mailer"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/hugohenrick/gtasks/utils"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestDeactivateUser(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	userModel := models.User{
+		ID:             2,
+		Name:           "Tech",
+		Email:          "tech@gtasks.com",
+		OrganizationId: 1,
+		Active:         true,
+	}
+
+	assigneeModel := models.User{
+		ID:             3,
+		Name:           "Other Tech",
+		Email:          "other@gtasks.com",
+		OrganizationId: 1,
+		Active:         true,
+	}
+
+	t.Run("Failed: user without access permission", func(t *testing.T) {
+		expectMsgError := `{"error":"user without access permission"}`
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/2/deactivate", bytes.NewBufferString(`{}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Failed: manager cannot deactivate itself", func(t *testing.T) {
+		expectMsgError := `{"error":"user cannot deactivate itself"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/2/deactivate", bytes.NewBufferString(`{}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Failed: reassign to an inactive user", func(t *testing.T) {
+		expectMsgError := `{"error":"tasks cannot be reassigned to this user"}`
+
+		inactive := assigneeModel
+		inactive.Active = false
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		iUserMock.On("FindUserById", "3").Return(inactive, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/2/deactivate", bytes.NewBufferString(`{"reassign_to":3}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Success: deactivate user and reassign open tasks", func(t *testing.T) {
+		expectMsg := `{"message":"success","reassigned_tasks":1}`
+
+		reassigned := []models.Task{{ID: 10, Title: "Task", UserId: 3, OrganizationId: 1}}
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		iUserMock.On("FindUserById", "3").Return(assigneeModel, nil)
+		iUserMock.On("DeactivateUser", "2", uint32(3), tmock.Anything).Return(reassigned, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", tmock.Anything).Return([]models.TaskWatcher{}, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", tmock.Anything).Return([]models.NotificationPreference{}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/2/deactivate", bytes.NewBufferString(`{"reassign_to":3}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(expectMsg, w.Body.String())
+		iUserMock.AssertCalled(t, "DeactivateUser", "2", uint32(3), tmock.MatchedBy(func(meta events.Meta) bool {
+			return meta.ActorId == 1 && meta.CorrelationId != ""
+		}))
+	})
+}
+
+func TestGetUserById(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Failed: technician cannot see another user", func(t *testing.T) {
+		expectMsgError := `{"error":"user not found"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "3").Return(models.User{ID: 3, OrganizationId: 1}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/user/3", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+}
+
+func TestUpdateMe(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	userModel := models.User{ID: 2, Name: "Tech", Email: "tech@gtasks.com", OrganizationId: 1, Active: true}
+
+	t.Run("Failed: invalid timezone", func(t *testing.T) {
+		expectMsgError := `{"error":"user timezone is invalid"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/user/me", bytes.NewBufferString(`{"timezone":"Mars/Olympus"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Success: update own profile", func(t *testing.T) {
+		updated := userModel
+		updated.Timezone = "America/Sao_Paulo"
+		updated.Locale = "pt-BR"
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		iUserMock.On("UpdateProfile", "2", updated, tmock.Anything).Return(updated, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+			c.Set("organizationId", uint32(1))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/user/me?fields=timezone,locale", bytes.NewBufferString(`{"timezone":"America/Sao_Paulo","locale":"pt-BR"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal(`{"locale":"pt-BR","timezone":"America/Sao_Paulo"}`, w.Body.String())
+	})
+}
+
+func TestChangeMyPassword(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("current-secret"), bcrypt.MinCost)
+	userModel := models.User{ID: 2, Name: "Tech", Email: "tech@gtasks.com", Password: string(hash), Active: true}
+
+	t.Run("Failed: invalid current password", func(t *testing.T) {
+		expectMsgError := `{"error":"user current password is invalid"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/password", bytes.NewBufferString(`{"current_password":"wrong","new_password":"new-secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "UpdatePassword", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: password changed and new token issued", func(t *testing.T) {
+		changed := userModel
+		changed.TokenVersion = 1
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(userModel, nil)
+		iUserMock.On("UpdatePassword", "2", tmock.Anything).Return(changed, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", false)
+			c.Set("userId", uint32(2))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/me/password", bytes.NewBufferString(`{"current_password":"current-secret","new_password":"new-secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Contains(w.Body.String(), `"token":`)
+		iUserMock.AssertCalled(t, "UpdatePassword", "2", tmock.Anything)
+	})
+}
+
+func TestCreateUser(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Failed: invalid email", func(t *testing.T) {
+		expectMsgError := `{"error":"user email is invalid"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user", bytes.NewBufferString(`{"name":"Tech","email":"Tech <tech@gtasks.com>","password":"secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "CreateUser", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Failed: email already registered in another case", func(t *testing.T) {
+		expectMsgError := `{"error":"email already registered"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "tech@gtasks.com").Return(models.User{ID: 2, Email: "tech@gtasks.com"}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user", bytes.NewBufferString(`{"name":"Tech","email":"  Tech@GTasks.com ","password":"correct-horse"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusConflict, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "CreateUser", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Failed: password too short", func(t *testing.T) {
+		expectMsgError := `{"error":"password is too short: minimum 8 characters"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user", bytes.NewBufferString(`{"name":"Tech","email":"tech@gtasks.com","password":"secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "CreateUser", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: signup creates a technician of the signup organization", func(t *testing.T) {
+		t.Setenv("SIGNUP_ORGANIZATION_ID", "7")
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "tech@gtasks.com").Return(models.User{}, errors.New("user not found"))
+		iUserMock.On("CreateUser", tmock.Anything, tmock.Anything).Return(func(user models.User, meta events.Meta) models.User {
+			user.ID = 2
+			return user
+		}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iTokenMock := new(taskMock.IUserTokenRepository)
+		iTokenMock.On("CreateToken", tmock.Anything).Return(models.UserToken{ID: 1}, nil)
+		repository.UserTokenRepositoryServices = iTokenMock
+
+		iMailerMock := new(taskMock.IMailer)
+		iMailerMock.On("Send", tmock.Anything, tmock.Anything).Return(nil)
+		mailer.MailerServices = iMailerMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user", bytes.NewBufferString(`{"name":"Tech","email":"tech@gtasks.com","password":"correct-horse","is_manager":true,"organization_id":1}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iUserMock.AssertCalled(t, "CreateUser", tmock.MatchedBy(func(user models.User) bool {
+			return !user.IsManager && user.OrganizationId == 7 && user.Password != ""
+		}), tmock.Anything)
+	})
+}
+
+func TestLoginUser(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	userModel := models.User{ID: 2, Name: "Tech", Email: "tech@gtasks.com", Password: string(hash), Active: true}
+
+	t.Run("Failed: unknown email and wrong password get the same response", func(t *testing.T) {
+		expectMsgError := `{"error":"user invalid credentials"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "unknown@gtasks.com").Return(models.User{}, errors.New("user not found"))
+		iUserMock.On("FindUserByEmail", "tech@gtasks.com").Return(userModel, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iAttemptMock := new(taskMock.ILoginAttemptRepository)
+		iAttemptMock.On("CountAccountFailures", tmock.Anything, tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CountEmailFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CountIPFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CreateLoginAttempt", tmock.Anything).Return(models.LoginAttempt{}, nil)
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"unknown@gtasks.com","password":"secret"}`))
+		c.Request.Header.Set("User-Agent", "test-agent")
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		unknownCode, unknownBody := w.Code, w.Body.String()
+
+		w = httptest.NewRecorder()
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"Tech@gtasks.com","password":"wrong"}`))
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, unknownCode)
+		assert.Equal(expectMsgError, unknownBody)
+		assert.Equal(unknownCode, w.Code)
+		assert.Equal(unknownBody, w.Body.String())
+		iAttemptMock.AssertCalled(t, "CreateLoginAttempt", tmock.MatchedBy(func(attempt models.LoginAttempt) bool {
+			return attempt.Email == "unknown@gtasks.com" && attempt.UserId == nil && !attempt.Success &&
+				attempt.Result == models.LoginInvalidCredentials && attempt.UserAgent == "test-agent"
+		}))
+		iAttemptMock.AssertCalled(t, "CreateLoginAttempt", tmock.MatchedBy(func(attempt models.LoginAttempt) bool {
+			return attempt.Email == "tech@gtasks.com" && attempt.UserId != nil && *attempt.UserId == 2 && !attempt.Success
+		}))
+	})
+
+	t.Run("Failed: too many attempts", func(t *testing.T) {
+		expectMsgError := `{"error":"too many login attempts, try again later"}`
+
+		lastFailure := time.Now()
+
+		iUserMock := new(taskMock.IUserRepository)
+		repository.UserRepositoryServices = iUserMock
+
+		iAttemptMock := new(taskMock.ILoginAttemptRepository)
+		iAttemptMock.On("CountAccountFailures", "tech@gtasks.com", tmock.Anything, tmock.Anything).Return(models.LoginFailures{Count: 10, LastAt: &lastFailure}, nil)
+		iAttemptMock.On("CountEmailFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CountIPFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CreateLoginAttempt", tmock.Anything).Return(models.LoginAttempt{}, nil)
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"tech@gtasks.com","password":"secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusTooManyRequests, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		assert.Equal("900", w.Header().Get("Retry-After"))
+		iUserMock.AssertNotCalled(t, "FindUserByEmail", tmock.Anything)
+		iAttemptMock.AssertNotCalled(t, "CreateLoginAttempt", tmock.Anything)
+	})
+
+	t.Run("Failed: too many attempts on the email from many IPs", func(t *testing.T) {
+		expectMsgError := `{"error":"too many login attempts, try again later"}`
+
+		lastFailure := time.Now()
+
+		iUserMock := new(taskMock.IUserRepository)
+		repository.UserRepositoryServices = iUserMock
+
+		iAttemptMock := new(taskMock.ILoginAttemptRepository)
+		iAttemptMock.On("CountAccountFailures", tmock.Anything, tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CountEmailFailures", "tech@gtasks.com", tmock.Anything).Return(models.LoginFailures{Count: 100, LastAt: &lastFailure}, nil)
+		iAttemptMock.On("CountIPFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"tech@gtasks.com","password":"secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusTooManyRequests, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "FindUserByEmail", tmock.Anything)
+	})
+
+	t.Run("Failed: login refused when the failures can't be counted", func(t *testing.T) {
+		expectMsgError := `{"error":"login temporarily unavailable, try again later"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		repository.UserRepositoryServices = iUserMock
+
+		iAttemptMock := new(taskMock.ILoginAttemptRepository)
+		iAttemptMock.On("CountAccountFailures", tmock.Anything, tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, errors.New("connection refused"))
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"tech@gtasks.com","password":"secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusServiceUnavailable, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "FindUserByEmail", tmock.Anything)
+	})
+
+	t.Run("Success: login recorded in the security log", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "tech@gtasks.com").Return(userModel, nil)
+		iUserMock.On("UpdatePasswordHash", "2", tmock.Anything).Return(nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iAttemptMock := new(taskMock.ILoginAttemptRepository)
+		iAttemptMock.On("CountAccountFailures", tmock.Anything, tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CountEmailFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CountIPFailures", tmock.Anything, tmock.Anything).Return(models.LoginFailures{}, nil)
+		iAttemptMock.On("CreateLoginAttempt", tmock.Anything).Return(models.LoginAttempt{}, nil)
+		repository.LoginAttemptRepositoryServices = iAttemptMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"tech@gtasks.com","password":"secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		iAttemptMock.AssertCalled(t, "CreateLoginAttempt", tmock.MatchedBy(func(attempt models.LoginAttempt) bool {
+			return attempt.Success && attempt.Result == models.LoginSucceeded
+		}))
+	})
+
+	t.Run("Success: legacy bcrypt hash rehashed with argon2id", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "tech@gtasks.com").Return(userModel, nil)
+		iUserMock.On("UpdatePasswordHash", "2", tmock.Anything).Return(nil)
+		repository.UserRepositoryServices = iUserMock
+
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"tech@gtasks.com","password":"secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		iUserMock.AssertCalled(t, "UpdatePasswordHash", "2", tmock.MatchedBy(func(hash string) bool {
+			return !utils.PasswordNeedsRehash(hash) && utils.CheckPasswordHash("secret", hash) == nil
+		}))
+	})
+
+	t.Run("Success: current hash is kept", func(t *testing.T) {
+		current := userModel
+		current.Password, _ = utils.HashPassword("secret")
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserByEmail", "tech@gtasks.com").Return(current, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		repository.LoginAttemptRepositoryServices = mockLoginAttempts()
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/user/login", bytes.NewBufferString(`{"email":"tech@gtasks.com","password":"secret"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		iUserMock.AssertNotCalled(t, "UpdatePasswordHash", tmock.Anything, tmock.Anything)
+	})
+}
+
+func TestGetUsers(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	usersRouter := func(w *httptest.ResponseRecorder, isManager bool, organizationId uint32) (*gin.Context, *gin.Engine) {
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", isManager)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", organizationId)
+		})
+
+		routes.AddUserRoutes(router)
+
+		return c, router
+	}
+
+	t.Run("Success: manager lists the users of the organization", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUsers", tmock.Anything).Return([]models.User{{ID: 2, OrganizationId: 3}}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := usersRouter(w, true, 3)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/user", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iUserMock.AssertCalled(t, "FindUsers", tmock.MatchedBy(func(organizationId *uint32) bool {
+			return organizationId != nil && *organizationId == 3
+		}))
+	})
+
+	t.Run("Success: administrator lists the users of every organization", func(t *testing.T) {
+		t.Setenv("ADMIN_ORGANIZATION_ID", "1")
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUsers", (*uint32)(nil)).Return([]models.User{{ID: 2, OrganizationId: 3}}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := usersRouter(w, true, 1)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/user", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iUserMock.AssertCalled(t, "FindUsers", (*uint32)(nil))
+	})
+
+	t.Run("Failed: user without access permission", func(t *testing.T) {
+		expectMsgError := `{"error":"user without access permission"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := usersRouter(w, false, 3)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/user", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "FindUsers", tmock.Anything)
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Failed: manager role of another organization", func(t *testing.T) {
+		expectMsgError := `{"error":"user not found"}`
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "5").Return(models.User{ID: 5, OrganizationId: 9, Active: true}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(3))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/user/5", bytes.NewBufferString(`{"is_manager":true}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iUserMock.AssertNotCalled(t, "UpdateUser", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: a new email is verified again", func(t *testing.T) {
+		var stored models.UserToken
+		var sent mailer.Message
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "5").Return(models.User{ID: 5, Email: "tech@gtasks.com", EmailVerified: true, OrganizationId: 3, Active: true}, nil)
+		iUserMock.On("UpdateUser", "5", tmock.Anything, tmock.Anything).Return(models.User{ID: 5, Email: "new@gtasks.com", OrganizationId: 3, Active: true}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iTokenMock := new(taskMock.IUserTokenRepository)
+		iTokenMock.On("CreateToken", tmock.Anything).Run(func(args tmock.Arguments) {
+			stored = args.Get(0).(models.UserToken)
+		}).Return(models.UserToken{ID: 1}, nil)
+		repository.UserTokenRepositoryServices = iTokenMock
+
+		iMailerMock := new(taskMock.IMailer)
+		iMailerMock.On("Send", tmock.Anything, tmock.Anything).Run(func(args tmock.Arguments) {
+			sent = args.Get(1).(mailer.Message)
+		}).Return(nil)
+		mailer.MailerServices = iMailerMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(3))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/user/5", bytes.NewBufferString(`{"email":"New@gtasks.com"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iUserMock.AssertCalled(t, "UpdateUser", "5", tmock.MatchedBy(func(user models.User) bool {
+			return user.Email == "new@gtasks.com"
+		}), tmock.Anything)
+		assert.Equal(models.UserTokenEmailVerification, stored.Purpose)
+		assert.Equal("new@gtasks.com", sent.To)
+	})
+
+	t.Run("Success: no verification when the email is kept", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "5").Return(models.User{ID: 5, Email: "tech@gtasks.com", EmailVerified: true, OrganizationId: 3, Active: true}, nil)
+		iUserMock.On("UpdateUser", "5", tmock.Anything, tmock.Anything).Return(models.User{ID: 5, Name: "Tech", Email: "tech@gtasks.com", EmailVerified: true, OrganizationId: 3, Active: true}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iTokenMock := new(taskMock.IUserTokenRepository)
+		repository.UserTokenRepositoryServices = iTokenMock
+
+		w := httptest.NewRecorder()
+		c, router := gin.CreateTestContext(w)
+		router.Use(func(c *gin.Context) {
+			c.Set("isManager", true)
+			c.Set("userId", uint32(1))
+			c.Set("organizationId", uint32(3))
+		})
+
+		routes.AddUserRoutes(router)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/user/5", bytes.NewBufferString(`{"name":"Tech"}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iTokenMock.AssertNotCalled(t, "CreateToken", tmock.Anything)
+	})
+}
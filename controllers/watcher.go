@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// GetTaskWatchers lists the watchers of a task the user can see
+func GetTaskWatchers(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskIdRequired))
+		return
+	}
+
+	task, err := repository.TaskRepositoryServices.FindTaskById(id)
+	if err != nil || !canSeeTask(c, task) {
+		utils.SendJSONError(c, http.StatusNotFound, fmt.Errorf("%v", utils.TaskNotFound))
+		return
+	}
+
+	watchers, err := repository.WatcherRepositoryServices.FindWatchers(fmt.Sprint(task.ID))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewWatcherListResponse(watchers))
+}
+
+// WatchTask adds the user to the watchers of a task it can see
+func WatchTask(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskIdRequired))
+		return
+	}
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	userId := userIdRaw.(uint32)
+
+	task, err := repository.TaskRepositoryServices.FindTaskById(id)
+	if err != nil || !canSeeTask(c, task) {
+		utils.SendJSONError(c, http.StatusNotFound, fmt.Errorf("%v", utils.TaskNotFound))
+		return
+	}
+
+	watcher, err := repository.WatcherRepositoryServices.AddWatcher(models.TaskWatcher{
+		TaskId: task.ID,
+		UserId: userId,
+	}, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewWatcherResponse(watcher))
+}
+
+func UnwatchTask(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.TaskIdRequired))
+		return
+	}
+
+	userIdRaw, ok := c.Get("userId")
+	if !ok || userIdRaw == nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	userId := userIdRaw.(uint32)
+
+	_, err := repository.WatcherRepositoryServices.RemoveWatcher(id, userId, eventMeta(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
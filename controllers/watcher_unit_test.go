@@ -0,0 +1,133 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func watcherRouter(w *httptest.ResponseRecorder, isManager bool) (*gin.Context, *gin.Engine) {
+	c, router := gin.CreateTestContext(w)
+	router.Use(func(c *gin.Context) {
+		c.Set("isManager", isManager)
+		c.Set("userId", uint32(2))
+		c.Set("organizationId", uint32(1))
+	})
+
+	routes.AddTaskRoutes(router)
+
+	return c, router
+}
+
+func TestWatchTask(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success: technician watches a task assigned to them", func(t *testing.T) {
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, UserId: 2, OrganizationId: 1}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("AddWatcher", models.TaskWatcher{TaskId: 1, UserId: 2}, tmock.Anything).Return(models.TaskWatcher{ID: 3, TaskId: 1, UserId: 2}, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		w := httptest.NewRecorder()
+		c, router := watcherRouter(w, false)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/task/1/watch", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iWatcherMock.AssertCalled(t, "AddWatcher", models.TaskWatcher{TaskId: 1, UserId: 2}, tmock.Anything)
+	})
+
+	t.Run("Failed: task of another technician or organization", func(t *testing.T) {
+		expectMsgError := `{"error":"task not found"}`
+
+		for _, isManager := range []bool{false, true} {
+			iTaskMock := new(taskMock.ITaskRepository)
+			iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, UserId: 5, OrganizationId: 9}, nil)
+			repository.TaskRepositoryServices = iTaskMock
+
+			iWatcherMock := new(taskMock.IWatcherRepository)
+			repository.WatcherRepositoryServices = iWatcherMock
+
+			w := httptest.NewRecorder()
+			c, router := watcherRouter(w, isManager)
+
+			// creating a request to send on endpoint call
+			c.Request, _ = http.NewRequest(http.MethodPost, "/task/1/watch", nil)
+
+			// endpoint call
+			router.ServeHTTP(w, c.Request)
+
+			// asserts
+			assert.Equal(http.StatusNotFound, w.Code)
+			assert.Equal(expectMsgError, w.Body.String())
+			iWatcherMock.AssertNotCalled(t, "AddWatcher", tmock.Anything)
+		}
+	})
+}
+
+func TestGetTaskWatchers(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success: manager lists the watchers of a task of the organization", func(t *testing.T) {
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, UserId: 5, OrganizationId: 1}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", "1").Return([]models.TaskWatcher{{ID: 3, TaskId: 1, UserId: 5}}, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		w := httptest.NewRecorder()
+		c, router := watcherRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task/1/watchers", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		iWatcherMock.AssertCalled(t, "FindWatchers", "1")
+	})
+
+	t.Run("Failed: task of another organization", func(t *testing.T) {
+		iTaskMock := new(taskMock.ITaskRepository)
+		iTaskMock.On("FindTaskById", "1").Return(models.Task{ID: 1, UserId: 5, OrganizationId: 9}, nil)
+		repository.TaskRepositoryServices = iTaskMock
+
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		w := httptest.NewRecorder()
+		c, router := watcherRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/task/1/watchers", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusNotFound, w.Code)
+		iWatcherMock.AssertNotCalled(t, "FindWatchers", tmock.Anything)
+	})
+}
@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+	"github.com/hugohenrick/gtasks/webhook"
+)
+
+// GetWebhooks lists the webhooks of the organization
+func GetWebhooks(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	webhooks, err := repository.WebhookRepositoryServices.FindWebhooks(organizationIdFromContext(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewWebhookListResponse(webhooks))
+}
+
+// CreateWebhook subscribes a url to task events of the organization. The
+// secret signing the deliveries is only returned in this response.
+func CreateWebhook(c *gin.Context) {
+	var input models.WebhookCreate
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	if err := c.ShouldBindWith(&input, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	webhookUrl, err := validateWebhookUrl(input.Url)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	eventTypes, err := validateWebhookEvents(input.EventTypes)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	hook, err := repository.WebhookRepositoryServices.CreateWebhook(models.Webhook{
+		OrganizationId: organizationIdFromContext(c),
+		Url:            webhookUrl,
+		EventTypes:     strings.Join(eventTypes, ","),
+		Secret:         secret,
+		Active:         true,
+	})
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusCreated, gin.H{
+		"secret":  secret,
+		"webhook": serializers.NewWebhookResponse(hook),
+	})
+}
+
+// UpdateWebhook changes the url, event types or state of a webhook of the
+// organization. Activating a webhook resets its failures.
+func UpdateWebhook(c *gin.Context) {
+	var input models.WebhookUpdate
+
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.WebhookIdRequired))
+		return
+	}
+
+	if err := c.ShouldBindWith(&input, binding.JSON); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v: %v", utils.InvalidJsonProvided, err))
+		return
+	}
+
+	hook, err := repository.WebhookRepositoryServices.FindWebhookById(id, organizationIdFromContext(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.WebhookNotFound))
+		return
+	}
+
+	if input.Url != nil {
+		if hook.Url, err = validateWebhookUrl(*input.Url); err != nil {
+			utils.SendJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if input.EventTypes != nil {
+		eventTypes, err := validateWebhookEvents(*input.EventTypes)
+		if err != nil {
+			utils.SendJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+		hook.EventTypes = strings.Join(eventTypes, ",")
+	}
+
+	if input.Active != nil {
+		if *input.Active && !hook.Active {
+			hook.ConsecutiveFailures = 0
+			hook.DisabledAt = nil
+		}
+		hook.Active = *input.Active
+	}
+
+	hook, err = repository.WebhookRepositoryServices.UpdateWebhook(hook)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	sendSerialized(c, http.StatusOK, serializers.NewWebhookResponse(hook))
+}
+
+// DeleteWebhook deletes a webhook of the organization with its deliveries
+func DeleteWebhook(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.WebhookIdRequired))
+		return
+	}
+
+	if err := repository.WebhookRepositoryServices.DeleteWebhook(id, organizationIdFromContext(c)); err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, "success")
+}
+
+// GetWebhookDeliveries lists the latest deliveries of a webhook of the
+// organization, limit defaults to 20 and is at most 100
+func GetWebhookDeliveries(c *gin.Context) {
+	if !isManager(c) {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.UserWithoutAccesPermission))
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.WebhookIdRequired))
+		return
+	}
+
+	limit := 20
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 100 {
+			utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.WebhookDeliveryLimitInvalid))
+			return
+		}
+		limit = parsed
+	}
+
+	hook, err := repository.WebhookRepositoryServices.FindWebhookById(id, organizationIdFromContext(c))
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", utils.WebhookNotFound))
+		return
+	}
+
+	deliveries, err := repository.WebhookRepositoryServices.FindWebhookDeliveries(hook.ID, limit)
+	if err != nil {
+		utils.SendJSONError(c, http.StatusBadRequest, fmt.Errorf("%v", err))
+		return
+	}
+
+	utils.SendJSONResponse(c, http.StatusOK, deliveries)
+}
+
+// validateWebhookUrl returns the trimmed url when it is an absolute http or
+// https url. Hosts of the internal network are refused early; the worker
+// checks the resolved address of every other host when posting.
+func validateWebhookUrl(value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("%v", utils.WebhookUrlInvalid)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "", fmt.Errorf("%v", utils.WebhookUrlNotAllowed)
+	}
+	if ip := net.ParseIP(host); ip != nil && !webhook.PublicIP(ip) {
+		return "", fmt.Errorf("%v", utils.WebhookUrlNotAllowed)
+	}
+
+	return value, nil
+}
+
+// validateWebhookEvents returns the distinct event types, all of them must be valid
+func validateWebhookEvents(values []string) ([]string, error) {
+	eventTypes := []string{}
+	for _, eventType := range values {
+		eventType = strings.TrimSpace(eventType)
+		if !webhook.ValidEventType(eventType) {
+			return nil, fmt.Errorf("%v: %v", utils.WebhookEventInvalid, eventType)
+		}
+		if !utils.ContainsString(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%v", utils.WebhookEventRequired)
+	}
+
+	return eventTypes, nil
+}
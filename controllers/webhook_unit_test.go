@@ -0,0 +1,230 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/routes"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func webhookRouter(w *httptest.ResponseRecorder, isManager bool) (*gin.Context, *gin.Engine) {
+	c, router := gin.CreateTestContext(w)
+	router.Use(func(c *gin.Context) {
+		c.Set("isManager", isManager)
+		c.Set("userId", uint32(1))
+		c.Set("organizationId", uint32(2))
+	})
+
+	routes.AddWebhookRoutes(router)
+
+	return c, router
+}
+
+func TestCreateWebhook(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success: secret returned once", func(t *testing.T) {
+		iWebhookMock := new(taskMock.IWebhookRepository)
+		iWebhookMock.On("CreateWebhook", tmock.Anything).Return(func(webhook models.Webhook) models.Webhook {
+			webhook.ID = 1
+			return webhook
+		}, nil)
+		repository.WebhookRepositoryServices = iWebhookMock
+
+		w := httptest.NewRecorder()
+		c, router := webhookRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(`{"url":"https://erp.example.com/hooks","event_types":["task.created","task.executed","task.created"]}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		var body struct {
+			Secret  string `json:"secret"`
+			Webhook struct {
+				Url        string   `json:"url"`
+				EventTypes []string `json:"event_types"`
+				Active     bool     `json:"active"`
+			} `json:"webhook"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+
+		// asserts
+		assert.Equal(http.StatusCreated, w.Code)
+		assert.True(strings.HasPrefix(body.Secret, "whsec_"))
+		assert.Equal("https://erp.example.com/hooks", body.Webhook.Url)
+		assert.Equal([]string{"task.created", "task.executed"}, body.Webhook.EventTypes)
+		assert.True(body.Webhook.Active)
+		iWebhookMock.AssertCalled(t, "CreateWebhook", tmock.MatchedBy(func(webhook models.Webhook) bool {
+			return webhook.OrganizationId == 2 && webhook.Secret == body.Secret
+		}))
+	})
+
+	t.Run("Failed: invalid url", func(t *testing.T) {
+		expectMsgError := `{"error":"webhook url must be an absolute http or https url"}`
+
+		iWebhookMock := new(taskMock.IWebhookRepository)
+		repository.WebhookRepositoryServices = iWebhookMock
+
+		w := httptest.NewRecorder()
+		c, router := webhookRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(`{"url":"ftp://erp.example.com","event_types":["task.created"]}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+		iWebhookMock.AssertNotCalled(t, "CreateWebhook", tmock.Anything)
+	})
+
+	t.Run("Failed: internal url", func(t *testing.T) {
+		expectMsgError := `{"error":"webhook url must not point to the internal network"}`
+
+		iWebhookMock := new(taskMock.IWebhookRepository)
+		repository.WebhookRepositoryServices = iWebhookMock
+
+		for _, url := range []string{"http://localhost:8080/admin", "http://169.254.169.254/latest/meta-data", "http://[::1]/", "https://10.0.0.5/hooks"} {
+			w := httptest.NewRecorder()
+			c, router := webhookRouter(w, true)
+
+			// creating a request to send on endpoint call
+			c.Request, _ = http.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(`{"url":"`+url+`","event_types":["task.created"]}`))
+
+			// endpoint call
+			router.ServeHTTP(w, c.Request)
+
+			// asserts
+			assert.Equal(http.StatusBadRequest, w.Code, url)
+			assert.Equal(expectMsgError, w.Body.String(), url)
+		}
+		iWebhookMock.AssertNotCalled(t, "CreateWebhook", tmock.Anything)
+	})
+
+	t.Run("Failed: unknown event type", func(t *testing.T) {
+		expectMsgError := `{"error":"invalid webhook event type: user.created"}`
+
+		iWebhookMock := new(taskMock.IWebhookRepository)
+		repository.WebhookRepositoryServices = iWebhookMock
+
+		w := httptest.NewRecorder()
+		c, router := webhookRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(`{"url":"https://erp.example.com","event_types":["user.created"]}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+
+	t.Run("Failed: user is not a manager", func(t *testing.T) {
+		expectMsgError := `{"error":"user without access permission"}`
+
+		w := httptest.NewRecorder()
+		c, router := webhookRouter(w, false)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(`{"url":"https://erp.example.com","event_types":["task.created"]}`))
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	iWebhookMock := new(taskMock.IWebhookRepository)
+	iWebhookMock.On("FindWebhookById", "1", uint32(2)).Return(models.Webhook{
+		ID: 1, OrganizationId: 2, Url: "https://erp.example.com", EventTypes: "task.created", Active: false, ConsecutiveFailures: 10,
+	}, nil)
+	iWebhookMock.On("UpdateWebhook", tmock.Anything).Return(func(webhook models.Webhook) models.Webhook {
+		return webhook
+	}, nil)
+	repository.WebhookRepositoryServices = iWebhookMock
+
+	w := httptest.NewRecorder()
+	c, router := webhookRouter(w, true)
+
+	// creating a request to send on endpoint call
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/webhook/1", bytes.NewBufferString(`{"active":true}`))
+
+	// endpoint call
+	router.ServeHTTP(w, c.Request)
+
+	// asserts
+	assert.Equal(http.StatusOK, w.Code)
+	iWebhookMock.AssertCalled(t, "UpdateWebhook", tmock.MatchedBy(func(webhook models.Webhook) bool {
+		return webhook.Active && webhook.ConsecutiveFailures == 0 && webhook.DisabledAt == nil
+	}))
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success: latest deliveries of the webhook", func(t *testing.T) {
+		iWebhookMock := new(taskMock.IWebhookRepository)
+		iWebhookMock.On("FindWebhookById", "1", uint32(2)).Return(models.Webhook{ID: 1, OrganizationId: 2}, nil)
+		iWebhookMock.On("FindWebhookDeliveries", uint32(1), 5).Return([]models.WebhookDelivery{
+			{ID: 9, WebhookId: 1, EventType: "task.created", Status: models.WebhookDeliveryFailed, Attempts: 8, ResponseStatus: 500, Payload: `{"secret":"payload"}`},
+		}, nil)
+		repository.WebhookRepositoryServices = iWebhookMock
+
+		w := httptest.NewRecorder()
+		c, router := webhookRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/webhook/1/deliveries?limit=5", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Contains(w.Body.String(), `"status":"failed"`)
+		assert.Contains(w.Body.String(), `"response_status":500`)
+		assert.NotContains(w.Body.String(), "payload")
+	})
+
+	t.Run("Failed: limit out of range", func(t *testing.T) {
+		expectMsgError := `{"error":"webhook delivery limit must be between 1 and 100"}`
+
+		w := httptest.NewRecorder()
+		c, router := webhookRouter(w, true)
+
+		// creating a request to send on endpoint call
+		c.Request, _ = http.NewRequest(http.MethodGet, "/webhook/1/deliveries?limit=500", nil)
+
+		// endpoint call
+		router.ServeHTTP(w, c.Request)
+
+		// asserts
+		assert.Equal(http.StatusBadRequest, w.Code)
+		assert.Equal(expectMsgError, w.Body.String())
+	})
+}
@@ -32,5 +32,39 @@ func Conn() {
 
 	fmt.Println("Database connection established")
 
-	DB.AutoMigrate(&models.Task{}, &models.User{})
+	if err := Migrate(DB); err != nil {
+		log.Printf("error migrating the database: %s\n", err)
+	}
+
+	if err := MigrateUserEmails(DB); err != nil {
+		log.Printf("error migrating user emails: %s\n", err)
+	}
+}
+
+// Migrate creates and updates the tables of the models
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&models.Task{},
+		&models.User{},
+		&models.TaskWatcher{},
+		&models.NotificationPreference{},
+		&models.Organization{},
+		&models.CustomFieldSchema{},
+		&models.TaskTemplate{},
+		&models.TaskTemplateVersion{},
+		&models.TaskReview{},
+		&models.Customer{},
+		&models.Site{},
+		&models.Project{},
+		&models.UserToken{},
+		&models.LoginAttempt{},
+		&models.RecoveryCode{},
+		&models.ApiToken{},
+		&models.OutboxMessage{},
+		&models.ProcessedMessage{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.EmailDigest{},
+		&models.SentEmail{},
+	)
 }
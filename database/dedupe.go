@@ -0,0 +1,232 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hugohenrick/gtasks/models"
+	"gorm.io/gorm"
+)
+
+// DuplicateEmail groups the users registered with the same normalized email
+type DuplicateEmail struct {
+	Email string
+	Users []models.User
+}
+
+// FindDuplicateEmails returns the emails used by more than one user, comparing
+// them trimmed and lowercased
+func FindDuplicateEmails(db *gorm.DB) ([]DuplicateEmail, error) {
+	var emails []string
+
+	err := db.Model(&models.User{}).
+		Select("LOWER(TRIM(email))").
+		Group("LOWER(TRIM(email))").
+		Having("COUNT(*) > 1").
+		Pluck("LOWER(TRIM(email))", &emails).Error
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]DuplicateEmail, 0, len(emails))
+	for _, email := range emails {
+		var users []models.User
+		if err := db.Where("LOWER(TRIM(email)) = ?", email).Order("id").Find(&users).Error; err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, DuplicateEmail{Email: email, Users: users})
+	}
+
+	return duplicates, nil
+}
+
+// CanonicalUser picks the account kept when duplicated accounts are merged:
+// active accounts first, then verified emails, then the oldest account
+func CanonicalUser(users []models.User) models.User {
+	sorted := append([]models.User(nil), users...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Active != sorted[j].Active {
+			return sorted[i].Active
+		}
+		if sorted[i].EmailVerified != sorted[j].EmailVerified {
+			return sorted[i].EmailVerified
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted[0]
+}
+
+// SpansOrganizations reports whether the accounts of a duplicated email belong
+// to more than one organization. They are not merged: data would move between
+// tenants, they must be resolved by hand.
+func SpansOrganizations(duplicate DuplicateEmail) bool {
+	for _, user := range duplicate.Users {
+		if user.OrganizationId != duplicate.Users[0].OrganizationId {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeDuplicateUsers merges the accounts of a duplicated email into the
+// canonical one: tasks, reviews, watched tasks, notification preferences,
+// sent emails, login attempts and api tokens move to it, along with the
+// identity provider subject and the MFA it lacks (see MergeAccount). Pending
+// tokens and email digests are discarded and the other accounts are deleted.
+// Accounts of different organizations are refused. The kept user is returned.
+func MergeDuplicateUsers(db *gorm.DB, duplicate DuplicateEmail) (models.User, error) {
+	if SpansOrganizations(duplicate) {
+		return models.User{}, fmt.Errorf("the users of %s belong to different organizations", duplicate.Email)
+	}
+
+	keep := CanonicalUser(duplicate.Users)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, user := range duplicate.Users {
+			if user.ID == keep.ID {
+				continue
+			}
+
+			merged, mfaMoved, err := MergeAccount(keep, user)
+			if err != nil {
+				return err
+			}
+			keep = merged
+
+			if err := tx.Model(&models.Task{}).Where("user_id = ?", user.ID).Update("user_id", keep.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.TaskReview{}).Where("reviewer_id = ?", user.ID).Update("reviewer_id", keep.ID).Error; err != nil {
+				return err
+			}
+			if err := mergeWatchers(tx, user.ID, keep.ID); err != nil {
+				return err
+			}
+			if err := mergePreferences(tx, user.ID, keep.ID); err != nil {
+				return err
+			}
+			if err := mergeSentEmails(tx, user.ID, keep.ID); err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.EmailDigest{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.LoginAttempt{}).Where("user_id = ?", user.ID).Update("user_id", keep.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.ApiToken{}).Where("user_id = ?", user.ID).Update("user_id", keep.ID).Error; err != nil {
+				return err
+			}
+			if err := mergeRecoveryCodes(tx, user.ID, keep.ID, mfaMoved); err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserToken{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.User{}, user.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		keep.Email = duplicate.Email
+		return tx.Model(&keep).Select("email", "oidc_subject", "mfa_enabled", "mfa_secret", "mfa_last_step").Updates(keep).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return keep, nil
+}
+
+// MergeAccount applies to keep the identity provider subject and the MFA of
+// the merged user: keep takes the subject when it has none and the
+// authenticator of user when it has no MFA enabled, which is reported so the
+// recovery codes move with it. The role of keep never changes. Accounts of
+// different organizations or linked to different subjects can't be merged.
+func MergeAccount(keep models.User, user models.User) (models.User, bool, error) {
+	if keep.OrganizationId != user.OrganizationId {
+		return models.User{}, false, fmt.Errorf("users #%d and #%d belong to different organizations", keep.ID, user.ID)
+	}
+
+	if user.OidcSubject != nil {
+		if keep.OidcSubject != nil && *keep.OidcSubject != *user.OidcSubject {
+			return models.User{}, false, fmt.Errorf("users #%d and #%d are linked to different identity provider subjects", keep.ID, user.ID)
+		}
+		keep.OidcSubject = user.OidcSubject
+	}
+
+	if keep.MfaEnabled || !user.MfaEnabled {
+		return keep, false, nil
+	}
+
+	keep.MfaEnabled = true
+	keep.MfaSecret = user.MfaSecret
+	keep.MfaLastStep = user.MfaLastStep
+
+	return keep, true, nil
+}
+
+// mergeWatchers moves the watched tasks of from to to, skipping the tasks to already watches
+func mergeWatchers(tx *gorm.DB, from uint32, to uint32) error {
+	var watched []uint32
+	if err := tx.Model(&models.TaskWatcher{}).Where("user_id = ?", to).Pluck("task_id", &watched).Error; err != nil {
+		return err
+	}
+
+	if len(watched) > 0 {
+		if err := tx.Where("user_id = ? AND task_id IN ?", from, watched).Delete(&models.TaskWatcher{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.TaskWatcher{}).Where("user_id = ?", from).Update("user_id", to).Error
+}
+
+// mergePreferences moves the notification preferences of from to to, the
+// preferences to already has win
+func mergePreferences(tx *gorm.DB, from uint32, to uint32) error {
+	var events []string
+	if err := tx.Model(&models.NotificationPreference{}).Where("user_id = ?", to).Pluck("event", &events).Error; err != nil {
+		return err
+	}
+
+	if len(events) > 0 {
+		if err := tx.Where("user_id = ? AND event IN ?", from, events).Delete(&models.NotificationPreference{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.NotificationPreference{}).Where("user_id = ?", from).Update("user_id", to).Error
+}
+
+// mergeSentEmails moves the emails sent to from to to, skipping the events to
+// was already emailed for
+func mergeSentEmails(tx *gorm.DB, from uint32, to uint32) error {
+	var sent []string
+	if err := tx.Model(&models.SentEmail{}).Where("user_id = ?", to).Pluck("event_id", &sent).Error; err != nil {
+		return err
+	}
+
+	if len(sent) > 0 {
+		if err := tx.Where("user_id = ? AND event_id IN ?", from, sent).Delete(&models.SentEmail{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.SentEmail{}).Where("user_id = ?", from).Update("user_id", to).Error
+}
+
+// mergeRecoveryCodes moves the recovery codes of from to to along with its
+// authenticator, replacing the codes of an MFA setup to didn't finish.
+// Otherwise the codes of from are discarded with its authenticator.
+func mergeRecoveryCodes(tx *gorm.DB, from uint32, to uint32, mfaMoved bool) error {
+	if !mfaMoved {
+		return tx.Where("user_id = ?", from).Delete(&models.RecoveryCode{}).Error
+	}
+
+	if err := tx.Where("user_id = ?", to).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.RecoveryCode{}).Where("user_id = ?", from).Update("user_id", to).Error
+}
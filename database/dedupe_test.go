@@ -0,0 +1,185 @@
+package database_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// migratedDatabase opens the MySQL database of TEST_DB_DSN, e.g.
+// user:pass@tcp(localhost:3306)/gtasks_test?parseTime=True, migrated with the
+// models. The tests needing it are skipped when it is not set.
+func migratedDatabase(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN not set")
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn))
+	if err != nil {
+		t.Fatalf("error connecting to the test database: %s", err)
+	}
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("error migrating the test database: %s", err)
+	}
+	if db.Migrator().HasIndex("user", "idx_user_email") {
+		t.Skip("the test database has the unique index on user.email, duplicates can't be created")
+	}
+
+	return db
+}
+
+func TestCanonicalUser(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: active account wins over an older inactive one", func(t *testing.T) {
+		users := []models.User{
+			{ID: 1, Active: false, EmailVerified: true},
+			{ID: 2, Active: true},
+		}
+
+		// asserts
+		assert.Equal(uint32(2), database.CanonicalUser(users).ID)
+	})
+
+	t.Run("Success: verified email wins, then the oldest account", func(t *testing.T) {
+		users := []models.User{
+			{ID: 5, Active: true},
+			{ID: 7, Active: true, EmailVerified: true},
+			{ID: 6, Active: true, EmailVerified: true},
+		}
+
+		// asserts
+		assert.Equal(uint32(6), database.CanonicalUser(users).ID)
+	})
+}
+
+func TestMergeAccount(t *testing.T) {
+	assert := assert.New(t)
+
+	subject := "idp-1"
+	other := "idp-2"
+
+	t.Run("Success: subject and MFA of the merged user kept, role unchanged", func(t *testing.T) {
+		keep := models.User{ID: 1, OrganizationId: 1}
+		user := models.User{ID: 2, OrganizationId: 1, IsManager: true, OidcSubject: &subject, MfaEnabled: true, MfaSecret: "SECRET", MfaLastStep: 7}
+
+		merged, mfaMoved, err := database.MergeAccount(keep, user)
+
+		// asserts
+		assert.Nil(err)
+		assert.True(mfaMoved)
+		assert.False(merged.IsManager)
+		assert.Equal(&subject, merged.OidcSubject)
+		assert.True(merged.MfaEnabled)
+		assert.Equal("SECRET", merged.MfaSecret)
+		assert.Equal(int64(7), merged.MfaLastStep)
+	})
+
+	t.Run("Success: MFA of the kept user wins", func(t *testing.T) {
+		keep := models.User{ID: 1, MfaEnabled: true, MfaSecret: "KEPT"}
+		user := models.User{ID: 2, MfaEnabled: true, MfaSecret: "SECRET"}
+
+		merged, mfaMoved, err := database.MergeAccount(keep, user)
+
+		// asserts
+		assert.Nil(err)
+		assert.False(mfaMoved)
+		assert.Equal("KEPT", merged.MfaSecret)
+	})
+
+	t.Run("Error: users of different organizations", func(t *testing.T) {
+		keep := models.User{ID: 1, OrganizationId: 1}
+		user := models.User{ID: 2, OrganizationId: 2}
+
+		_, _, err := database.MergeAccount(keep, user)
+
+		// asserts
+		assert.NotNil(err)
+	})
+
+	t.Run("Error: users linked to different subjects", func(t *testing.T) {
+		keep := models.User{ID: 1, OidcSubject: &subject}
+		user := models.User{ID: 2, OidcSubject: &other}
+
+		_, _, err := database.MergeAccount(keep, user)
+
+		// asserts
+		assert.NotNil(err)
+	})
+}
+
+func TestMergeDuplicateUsers(t *testing.T) {
+	assert := assert.New(t)
+	db := migratedDatabase(t)
+
+	// every change is rolled back, the merge runs in a savepoint of the transaction
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	t.Run("Success: data of the duplicated account moved to the kept one", func(t *testing.T) {
+		subject := "idp-merge-" + time.Now().Format("150405.000000")
+		keep := models.User{Name: "Kept", Email: "merge@gtasks.com", Password: "x", OrganizationId: 1, Active: true, EmailVerified: true}
+		other := models.User{Name: "Other", Email: " Merge@gtasks.com", Password: "x", OrganizationId: 1, Active: true, IsManager: true, OidcSubject: &subject}
+		assert.Nil(tx.Create(&keep).Error)
+		assert.Nil(tx.Create(&other).Error)
+
+		task := models.Task{Title: "Fix the pump", Summary: "The pump leaks", UserId: other.ID, OrganizationId: 1}
+		assert.Nil(tx.Create(&task).Error)
+		assert.Nil(tx.Create(&models.TaskWatcher{TaskId: task.ID, UserId: other.ID}).Error)
+		assert.Nil(tx.Create(&models.NotificationPreference{UserId: other.ID, Event: models.EmailEventDigest, Enabled: false}).Error)
+		assert.Nil(tx.Create(&models.LoginAttempt{UserId: &other.ID, Email: "merge@gtasks.com", IP: "10.0.0.1", Result: "success", Success: true}).Error)
+		assert.Nil(tx.Create(&models.ApiToken{UserId: other.ID, Name: "ci", Prefix: "gtk_merge", TokenHash: subject, Scopes: "task:read"}).Error)
+		assert.Nil(tx.Create(&models.SentEmail{EventId: subject, UserId: other.ID, SentAt: time.Now()}).Error)
+		assert.Nil(tx.Create(&models.EmailDigest{UserId: other.ID, SentOn: "2026-10-19", SentAt: time.Now()}).Error)
+		assert.Nil(tx.Create(&models.UserToken{UserId: other.ID, Purpose: models.UserTokenEmailVerification, TokenHash: subject, ExpiresAt: time.Now()}).Error)
+
+		kept, err := database.MergeDuplicateUsers(tx, database.DuplicateEmail{Email: "merge@gtasks.com", Users: []models.User{keep, other}})
+
+		count := func(model interface{}, query string, args ...interface{}) int64 {
+			var n int64
+			tx.Model(model).Where(query, args...).Count(&n)
+			return n
+		}
+
+		var stored models.User
+		tx.First(&stored, keep.ID)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(keep.ID, kept.ID)
+		assert.False(stored.IsManager)
+		assert.Equal(&subject, stored.OidcSubject)
+		assert.Equal(int64(0), count(&models.User{}, "id = ?", other.ID))
+		assert.Equal(int64(1), count(&models.Task{}, "id = ? AND user_id = ?", task.ID, keep.ID))
+		assert.Equal(int64(1), count(&models.TaskWatcher{}, "task_id = ? AND user_id = ?", task.ID, keep.ID))
+		assert.Equal(int64(1), count(&models.NotificationPreference{}, "user_id = ?", keep.ID))
+		assert.Equal(int64(1), count(&models.LoginAttempt{}, "user_id = ?", keep.ID))
+		assert.Equal(int64(1), count(&models.ApiToken{}, "user_id = ?", keep.ID))
+		assert.Equal(int64(1), count(&models.SentEmail{}, "user_id = ?", keep.ID))
+		assert.Equal(int64(0), count(&models.EmailDigest{}, "user_id = ?", other.ID))
+		assert.Equal(int64(0), count(&models.UserToken{}, "user_id = ?", other.ID))
+	})
+
+	t.Run("Error: accounts of different organizations refused", func(t *testing.T) {
+		first := models.User{Name: "First", Email: "tenants@gtasks.com", Password: "x", OrganizationId: 1, Active: true}
+		second := models.User{Name: "Second", Email: "tenants@gtasks.com", Password: "x", OrganizationId: 2, Active: true}
+		assert.Nil(tx.Create(&first).Error)
+		assert.Nil(tx.Create(&second).Error)
+
+		_, err := database.MergeDuplicateUsers(tx, database.DuplicateEmail{Email: "tenants@gtasks.com", Users: []models.User{first, second}})
+
+		var users int64
+		tx.Model(&models.User{}).Where("email = ?", "tenants@gtasks.com").Count(&users)
+
+		// asserts
+		assert.NotNil(err)
+		assert.Equal(int64(2), users)
+	})
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const userEmailIndex = "idx_user_email"
+
+// MigrateUserEmails normalizes the user emails and creates the unique index on
+// user.email. The index is not created while the same email is used by more
+// than one user: those accounts must be merged first with cmd/dedupe-users.
+func MigrateUserEmails(db *gorm.DB) error {
+	if db.Migrator().HasIndex("user", userEmailIndex) {
+		return nil
+	}
+
+	err := db.Exec("UPDATE `user` SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email))").Error
+	if err != nil {
+		return err
+	}
+
+	duplicates, err := FindDuplicateEmails(db)
+	if err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%d emails are used by more than one user, merge them with cmd/dedupe-users", len(duplicates))
+	}
+
+	return db.Exec("CREATE UNIQUE INDEX " + userEmailIndex + " ON `user` (email)").Error
+}
@@ -0,0 +1,182 @@
+// Package events defines the domain events gtasks publishes to the message
+// broker. Every event is a JSON CloudEvents envelope whose data is one of the
+// payload types of this package. Consumers can import this package alone, it
+// only depends on the standard library.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SpecVersion is the CloudEvents version of the envelope
+const SpecVersion = "1.0"
+
+// ContentType is the content type of the published messages
+const ContentType = "application/cloudevents+json"
+
+// Event types
+const (
+	TaskCreated  = "task.created"
+	TaskUpdated  = "task.updated"
+	TaskExecuted = "task.executed"
+	TaskDeleted  = "task.deleted"
+	UserCreated  = "user.created"
+	UserUpdated  = "user.updated"
+	UserDeleted  = "user.deleted"
+
+	WatcherAdded   = "watcher.added"
+	WatcherRemoved = "watcher.removed"
+
+	CustomerCreated = "customer.created"
+	CustomerUpdated = "customer.updated"
+	CustomerDeleted = "customer.deleted"
+
+	SiteCreated = "site.created"
+	SiteUpdated = "site.updated"
+	SiteDeleted = "site.deleted"
+
+	ProjectCreated = "project.created"
+	ProjectUpdated = "project.updated"
+	ProjectDeleted = "project.deleted"
+
+	TemplateCreated = "template.created"
+	TemplateUpdated = "template.updated"
+
+	CustomFieldCreated = "custom_field.created"
+	CustomFieldUpdated = "custom_field.updated"
+	CustomFieldDeleted = "custom_field.deleted"
+)
+
+// schemaVersions is the current version of the data of each event type. A
+// version changes when a field of the payload is removed or changes meaning,
+// new fields don't change it.
+var schemaVersions = map[string]int{
+	TaskCreated:  1,
+	TaskUpdated:  1,
+	TaskExecuted: 1,
+	TaskDeleted:  1,
+	UserCreated:  1,
+	UserUpdated:  1,
+	UserDeleted:  1,
+
+	WatcherAdded:   1,
+	WatcherRemoved: 1,
+
+	CustomerCreated: 1,
+	CustomerUpdated: 1,
+	CustomerDeleted: 1,
+
+	SiteCreated: 1,
+	SiteUpdated: 1,
+	SiteDeleted: 1,
+
+	ProjectCreated: 1,
+	ProjectUpdated: 1,
+	ProjectDeleted: 1,
+
+	TemplateCreated: 1,
+	TemplateUpdated: 1,
+
+	CustomFieldCreated: 1,
+	CustomFieldUpdated: 1,
+	CustomFieldDeleted: 1,
+}
+
+// Event is the envelope of a domain event
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	Subject         string          `json:"subject"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   int             `json:"schemaversion"`
+	CorrelationId   string          `json:"correlationid,omitempty"`
+	OrganizationId  uint32          `json:"organizationid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// Meta is the context of the change an event is published for
+type Meta struct {
+	ActorId       uint32
+	CorrelationId string
+}
+
+// SchemaVersion returns the current schema version of the event type, 0 when unknown
+func SchemaVersion(eventType string) int {
+	return schemaVersions[eventType]
+}
+
+// New builds the event of the given type about the subject, e.g. task/12
+func New(eventType string, subject string, correlationId string, data interface{}) (Event, error) {
+	version, ok := schemaVersions[eventType]
+	if !ok {
+		return Event{}, fmt.Errorf("unknown event type %q", eventType)
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	id, err := NewId()
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		SpecVersion:     SpecVersion,
+		ID:              id,
+		Type:            eventType,
+		Source:          "/gtasks/" + Aggregate(eventType) + "s",
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		SchemaVersion:   version,
+		CorrelationId:   correlationId,
+		Data:            body,
+	}, nil
+}
+
+// Decode unmarshals the data of the event into v, e.g. a *TaskExecutedData
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// Aggregate returns the kind of entity of the event type, e.g. task for task.executed
+func Aggregate(eventType string) string {
+	return strings.SplitN(eventType, ".", 2)[0]
+}
+
+// NewId returns a random UUID (version 4), used for the ids of events and correlations
+func NewId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// TaskSubject returns the subject of the events of a task
+func TaskSubject(id uint32) string {
+	return fmt.Sprintf("task/%d", id)
+}
+
+// UserSubject returns the subject of the events of a user
+func UserSubject(id uint32) string {
+	return fmt.Sprintf("user/%d", id)
+}
+
+// Subject returns the subject of the events of an entity of the aggregate,
+// e.g. project/4
+func Subject(aggregate string, id uint32) string {
+	return fmt.Sprintf("%s/%d", aggregate, id)
+}
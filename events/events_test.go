@@ -0,0 +1,80 @@
+package events_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: task event envelope", func(t *testing.T) {
+		event, err := events.New(events.TaskExecuted, events.TaskSubject(12), "corr-1", events.TaskExecutedData{
+			Task:         events.Task{ID: 12, Title: "Test Title", Done: true},
+			TechnicianId: 3,
+		})
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal("1.0", event.SpecVersion)
+		assert.Regexp(regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), event.ID)
+		assert.Equal("task.executed", event.Type)
+		assert.Equal("/gtasks/tasks", event.Source)
+		assert.Equal("task/12", event.Subject)
+		assert.Equal("application/json", event.DataContentType)
+		assert.Equal(1, event.SchemaVersion)
+		assert.Equal("corr-1", event.CorrelationId)
+		assert.WithinDuration(time.Now(), event.Time, time.Minute)
+	})
+
+	t.Run("Success: user event source", func(t *testing.T) {
+		event, err := events.New(events.UserCreated, events.UserSubject(4), "", events.UserCreatedData{
+			User:   events.User{ID: 4},
+			Origin: events.UserOriginSignup,
+		})
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal("/gtasks/users", event.Source)
+		assert.Equal("user/4", event.Subject)
+	})
+
+	t.Run("Failed: unknown event type", func(t *testing.T) {
+		_, err := events.New("task.archived", events.TaskSubject(1), "", nil)
+
+		// asserts
+		assert.NotNil(err)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	assert := assert.New(t)
+
+	event, _ := events.New(events.TaskUpdated, events.TaskSubject(1), "corr-1", events.TaskUpdatedData{
+		Task:    events.Task{ID: 1, UserId: 2},
+		Changes: []string{"user_id"},
+		ActorId: 5,
+	})
+
+	body, err := json.Marshal(event)
+	assert.Nil(err)
+
+	var received events.Event
+	assert.Nil(json.Unmarshal(body, &received))
+
+	var data events.TaskUpdatedData
+	err = received.Decode(&data)
+
+	// asserts
+	assert.Nil(err)
+	assert.Equal(event.ID, received.ID)
+	assert.Equal(uint32(2), data.Task.UserId)
+	assert.Equal([]string{"user_id"}, data.Changes)
+	assert.Equal(uint32(5), data.ActorId)
+	assert.Nil(data.Review)
+}
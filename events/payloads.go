@@ -0,0 +1,203 @@
+package events
+
+import (
+	"time"
+)
+
+// Task is the state of a task carried by the task events
+type Task struct {
+	ID               uint32     `json:"id"`
+	Title            string     `json:"title"`
+	Summary          string     `json:"summary"`
+	UserId           uint32     `json:"user_id"`
+	OrganizationId   uint32     `json:"organization_id"`
+	ProjectId        *uint32    `json:"project_id,omitempty"`
+	Done             bool       `json:"done"`
+	Status           string     `json:"status"`
+	RequiresApproval bool       `json:"requires_approval"`
+	Priority         string     `json:"priority,omitempty"`
+	Tags             []string   `json:"tags,omitempty"`
+	DueAt            *time.Time `json:"due_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+}
+
+// User is the public state of a user carried by the user events
+type User struct {
+	ID             uint32    `json:"id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	IsManager      bool      `json:"is_manager"`
+	OrganizationId uint32    `json:"organization_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Review is the decision of a manager on a task waiting for review
+type Review struct {
+	ReviewerId uint32 `json:"reviewer_id"`
+	Approved   bool   `json:"approved"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// TaskCreatedData is the data of task.created
+type TaskCreatedData struct {
+	Task    Task   `json:"task"`
+	ActorId uint32 `json:"actor_id"`
+}
+
+// TaskUpdatedData is the data of task.updated. Changes lists the json names
+// of the fields that changed, e.g. user_id when the task was reassigned, and
+// PreviousUserId is then the assignee before the reassignment.
+type TaskUpdatedData struct {
+	Task           Task     `json:"task"`
+	Changes        []string `json:"changes"`
+	ActorId        uint32   `json:"actor_id"`
+	PreviousUserId uint32   `json:"previous_user_id,omitempty"`
+	Review         *Review  `json:"review,omitempty"`
+}
+
+// TaskExecutedData is the data of task.executed, published when a task is
+// done: executed by its technician, or approved by a manager when it
+// requires approval
+type TaskExecutedData struct {
+	Task         Task      `json:"task"`
+	TechnicianId uint32    `json:"technician_id"`
+	ExecutedAt   time.Time `json:"executed_at"`
+	Review       *Review   `json:"review,omitempty"`
+}
+
+// TaskDeletedData is the data of task.deleted
+type TaskDeletedData struct {
+	TaskId         uint32 `json:"task_id"`
+	UserId         uint32 `json:"user_id"`
+	OrganizationId uint32 `json:"organization_id"`
+	ActorId        uint32 `json:"actor_id"`
+}
+
+// UserCreatedData is the data of user.created. Origin is signup or sso.
+type UserCreatedData struct {
+	User   User   `json:"user"`
+	Origin string `json:"origin"`
+}
+
+// User origins
+const (
+	UserOriginSignup = "signup"
+	UserOriginSso    = "sso"
+)
+
+// UserUpdatedData is the data of user.updated, published when the profile,
+// the role or the state of the user changed. Changes lists the json names of
+// the fields that changed, active when the user was deactivated or activated.
+type UserUpdatedData struct {
+	User    User     `json:"user"`
+	Changes []string `json:"changes"`
+	Active  bool     `json:"active"`
+	ActorId uint32   `json:"actor_id"`
+}
+
+// Watcher is a user watching a task
+type Watcher struct {
+	TaskId uint32 `json:"task_id"`
+	UserId uint32 `json:"user_id"`
+}
+
+// WatcherData is the data of watcher.added and watcher.removed
+type WatcherData struct {
+	Watcher Watcher `json:"watcher"`
+	ActorId uint32  `json:"actor_id"`
+}
+
+// Customer is the state of a customer carried by the customer events
+type Customer struct {
+	ID             uint32    `json:"id"`
+	OrganizationId uint32    `json:"organization_id"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CustomerData is the data of customer.created and customer.updated
+type CustomerData struct {
+	Customer Customer `json:"customer"`
+	ActorId  uint32   `json:"actor_id"`
+}
+
+// Site is the state of a site carried by the site events
+type Site struct {
+	ID             uint32    `json:"id"`
+	OrganizationId uint32    `json:"organization_id"`
+	CustomerId     uint32    `json:"customer_id"`
+	Name           string    `json:"name"`
+	Address        string    `json:"address,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SiteData is the data of site.created and site.updated
+type SiteData struct {
+	Site    Site   `json:"site"`
+	ActorId uint32 `json:"actor_id"`
+}
+
+// Project is the state of a project carried by the project events
+type Project struct {
+	ID             uint32    `json:"id"`
+	OrganizationId uint32    `json:"organization_id"`
+	SiteId         uint32    `json:"site_id"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ProjectData is the data of project.created and project.updated
+type ProjectData struct {
+	Project Project `json:"project"`
+	ActorId uint32  `json:"actor_id"`
+}
+
+// Template is the state of a task template carried by the template events,
+// LatestVersion is the version new tasks are created from
+type Template struct {
+	ID             uint32    `json:"id"`
+	OrganizationId uint32    `json:"organization_id"`
+	Name           string    `json:"name"`
+	LatestVersion  uint32    `json:"latest_version"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TemplateData is the data of template.created and template.updated, the
+// latter published when a new version of the template is created
+type TemplateData struct {
+	Template Template `json:"template"`
+	ActorId  uint32   `json:"actor_id"`
+}
+
+// CustomField is the schema of a custom field carried by the custom field events
+type CustomField struct {
+	ID             uint32    `json:"id"`
+	OrganizationId uint32    `json:"organization_id"`
+	Key            string    `json:"key"`
+	Label          string    `json:"label"`
+	Type           string    `json:"type"`
+	Required       bool      `json:"required"`
+	Pattern        string    `json:"pattern,omitempty"`
+	Options        []string  `json:"options,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CustomFieldData is the data of custom_field.created and custom_field.updated
+type CustomFieldData struct {
+	CustomField CustomField `json:"custom_field"`
+	ActorId     uint32      `json:"actor_id"`
+}
+
+// DeletedData is the data of the deleted events but task.deleted, e.g.
+// user.deleted or project.deleted. ID is the id of the deleted entity.
+type DeletedData struct {
+	ID             uint32 `json:"id"`
+	OrganizationId uint32 `json:"organization_id"`
+	ActorId        uint32 `json:"actor_id"`
+}
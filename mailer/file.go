@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer writes every email as a .eml file in Dir instead of sending it,
+// for local development
+type FileMailer struct {
+	Dir  string
+	From string
+
+	sequence uint64
+}
+
+// LogMailer logs the recipient, subject and text of every email instead of
+// sending it, for local development
+type LogMailer struct{}
+
+// NewMailer returns the mail transport of MAIL_TRANSPORT: smtp (default),
+// file, writing to MAIL_DIR (mails by default), or log
+func NewMailer() IMailer {
+	switch os.Getenv("MAIL_TRANSPORT") {
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mails"
+		}
+		return &FileMailer{Dir: dir, From: mailFrom()}
+	case "log":
+		return &LogMailer{}
+	default:
+		return NewSMTPMailer()
+	}
+}
+
+func (m *FileMailer) Send(ctx context.Context, message Message) error {
+	body, err := buildMIME(m.From, message)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return fmt.Errorf("error writing email: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().UTC().Format("20060102T150405.000000"), atomic.AddUint64(&m.sequence, 1))
+	if err := os.WriteFile(filepath.Join(m.Dir, name), body, 0o644); err != nil {
+		return fmt.Errorf("error writing email: %w", err)
+	}
+
+	return nil
+}
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	log.Printf("email to %s: %s\n%s\n", message.To, message.Subject, strings.TrimSpace(message.Text))
+	return nil
+}
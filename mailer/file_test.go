@@ -0,0 +1,31 @@
+package mailer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/mailer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailerSend(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := filepath.Join(t.TempDir(), "mails")
+	fileMailer := &mailer.FileMailer{Dir: dir, From: "no-reply@gtasks.local"}
+
+	err := fileMailer.Send(context.Background(), mailer.Message{To: "tech@gtasks.com", Subject: "Hello", Text: "Body"})
+	assert.Nil(err)
+	err = fileMailer.Send(context.Background(), mailer.Message{To: "manager@gtasks.com", Subject: "Hello", Text: "Body"})
+	assert.Nil(err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	content, _ := os.ReadFile(files[0])
+
+	// asserts
+	assert.Len(files, 2)
+	assert.Contains(string(content), "To: tech@gtasks.com\r\n")
+	assert.Contains(string(content), "Subject: Hello\r\n")
+}
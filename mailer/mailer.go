@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"context"
+)
+
+// Message is an email with a plain text and an HTML body
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// IMailer delivers emails. The SMTP implementation is used by default, the
+// file and log ones keep the emails local (see NewMailer). Tests replace
+// MailerServices with a mock or point the SMTP mailer to a local server.
+type IMailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+var MailerServices IMailer
@@ -0,0 +1,168 @@
+// Package mailertest provides a local SMTP server that records the emails it
+// receives, to test mail delivery without a real SMTP server.
+package mailertest
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Email is a message received by the Server
+type Email struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Header returns a header of the email, e.g. Subject
+func (e Email) Header(name string) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(e.Data))
+	if err != nil {
+		return ""
+	}
+	return msg.Header.Get(name)
+}
+
+// Body returns the decoded body part with the given media type, e.g. text/plain
+func (e Email) Body(mediaType string) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(e.Data))
+	if err != nil {
+		return ""
+	}
+
+	contentType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	if !strings.HasPrefix(contentType, "multipart/") {
+		body, _ := io.ReadAll(msg.Body)
+		return string(body)
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return ""
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if partType == mediaType {
+			body, _ := io.ReadAll(part)
+			return string(body)
+		}
+	}
+}
+
+// Server is a minimal SMTP server listening on a random local port
+type Server struct {
+	Host string
+	Port string
+
+	listener net.Listener
+	mu       sync.Mutex
+	emails   []Email
+}
+
+// NewServer starts a Server, callers must Close it
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	server := &Server{Host: host, Port: port, listener: listener}
+
+	go server.serve()
+
+	return server, nil
+}
+
+// Emails returns the emails received so far
+func (s *Server) Emails() []Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Email(nil), s.emails...)
+}
+
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	reply := func(line string) {
+		io.WriteString(conn, line+"\r\n")
+	}
+
+	var email Email
+	reply("220 localhost ESMTP mailertest")
+
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			email = Email{From: address(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			email.To = append(email.To, address(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := reader.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			email.Data = data
+
+			s.mu.Lock()
+			s.emails = append(s.emails, email)
+			s.mu.Unlock()
+
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// address strips the angle brackets and parameters of a MAIL/RCPT argument
+func address(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if i := strings.Index(arg, ">"); i >= 0 {
+		arg = arg[:i]
+	}
+	return strings.TrimPrefix(arg, "<")
+}
@@ -0,0 +1,97 @@
+package mailer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/mailer"
+	"github.com/hugohenrick/gtasks/mailer/mailertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTPMailerSend(t *testing.T) {
+	assert := assert.New(t)
+
+	server, err := mailertest.NewServer()
+	assert.Nil(err)
+	defer server.Close()
+
+	smtpMailer := &mailer.SMTPMailer{Host: server.Host, Port: server.Port, From: "no-reply@gtasks.local"}
+
+	link := "http://localhost:8080/reset-password?token=" + strings.Repeat("a", 80)
+	message, err := mailer.Render(mailer.TemplatePasswordReset, "tech@gtasks.com", map[string]interface{}{
+		"Name":      "Tech",
+		"Link":      link,
+		"ExpiresIn": "1 hour",
+	})
+	assert.Nil(err)
+
+	err = smtpMailer.Send(context.Background(), message)
+
+	// asserts
+	assert.Nil(err)
+
+	emails := server.Emails()
+	assert.Len(emails, 1)
+	assert.Equal("no-reply@gtasks.local", emails[0].From)
+	assert.Equal([]string{"tech@gtasks.com"}, emails[0].To)
+	assert.Equal("Reset your gtasks password", emails[0].Header("Subject"))
+	assert.Contains(emails[0].Body("text/plain"), link)
+	assert.Contains(emails[0].Body("text/html"), `<a href="`+link+`">`)
+}
+
+func TestRender(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: html body is escaped", func(t *testing.T) {
+		message, err := mailer.Render(mailer.TemplateEmailVerification, "tech@gtasks.com", map[string]interface{}{
+			"Name":      "<b>Tech</b>",
+			"Link":      "http://localhost:8080/verify-email?token=abc",
+			"ExpiresIn": "24 hours",
+		})
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal("Confirm your gtasks email", message.Subject)
+		assert.True(strings.HasPrefix(message.Text, "Hello <b>Tech</b>,"))
+		assert.Contains(message.HTML, "Hello &lt;b&gt;Tech&lt;/b&gt;,")
+	})
+
+	t.Run("Failed: unknown template", func(t *testing.T) {
+		_, err := mailer.Render("unknown", "tech@gtasks.com", nil)
+
+		// asserts
+		assert.NotNil(err)
+	})
+}
+
+func TestRenderLocalized(t *testing.T) {
+	assert := assert.New(t)
+
+	data := map[string]interface{}{
+		"Name":    "Tech",
+		"Title":   "Fix the pump",
+		"Summary": "The pump leaks",
+		"DueAt":   "",
+		"Link":    "http://localhost:8080/task/1",
+	}
+
+	t.Run("Success: translation of the language of the locale", func(t *testing.T) {
+		message, err := mailer.RenderLocalized(mailer.TemplateTaskAssigned, "pt-BR", "tech@gtasks.com", data)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal("Nova tarefa atribuída a você: Fix the pump", message.Subject)
+		assert.Contains(message.HTML, "Abrir a tarefa")
+	})
+
+	t.Run("Success: English without a translation", func(t *testing.T) {
+		message, err := mailer.RenderLocalized(mailer.TemplateTaskAssigned, "de-DE", "tech@gtasks.com", data)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal("New task assigned to you: Fix the pump", message.Subject)
+		assert.NotContains(message.Text, "due on")
+	})
+}
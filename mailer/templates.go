@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Email templates, each one has a <name>.txt and a <name>.html body in
+// English. The first line of the text template is the subject. Translations
+// are named <name>.<language>.txt and <name>.<language>.html, e.g.
+// task_assigned.pt.txt.
+const (
+	TemplatePasswordReset     = "password_reset"
+	TemplateEmailVerification = "email_verification"
+	TemplateTaskAssigned      = "task_assigned"
+	TemplateTaskCompleted     = "task_completed"
+	TemplateTaskOverdue       = "task_overdue"
+	TemplateDailyDigest       = "daily_digest"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templatesFS, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templatesFS, "templates/*.html"))
+)
+
+// Render builds the message of the template name to the given recipient
+func Render(name string, to string, data interface{}) (Message, error) {
+	return render(name, to, data)
+}
+
+// RenderLocalized builds the message of the template name in the locale of the
+// recipient, e.g. pt-BR: the translation of the locale, then of its language,
+// then the English template
+func RenderLocalized(name string, locale string, to string, data interface{}) (Message, error) {
+	return render(localizedName(name, locale), to, data)
+}
+
+func localizedName(name string, locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, language} {
+		if candidate == "" {
+			continue
+		}
+		localized := name + "." + candidate
+		if textTemplates.Lookup(localized+".txt") != nil && htmlTemplates.Lookup(localized+".html") != nil {
+			return localized
+		}
+	}
+
+	return name
+}
+
+func render(file string, to string, data interface{}) (Message, error) {
+	var text, html bytes.Buffer
+
+	if err := textTemplates.ExecuteTemplate(&text, file+".txt", data); err != nil {
+		return Message{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, file+".html", data); err != nil {
+		return Message{}, err
+	}
+
+	subject, body, _ := strings.Cut(text.String(), "\n")
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject),
+		Text:    strings.TrimLeft(body, "\n"),
+		HTML:    html.String(),
+	}, nil
+}
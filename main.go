@@ -6,16 +6,24 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/auth"
+	"github.com/hugohenrick/gtasks/broker"
 	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/mailer"
 	"github.com/hugohenrick/gtasks/middlewares"
+	"github.com/hugohenrick/gtasks/notification"
+	"github.com/hugohenrick/gtasks/outbox"
 	"github.com/hugohenrick/gtasks/rabbitmq"
 	"github.com/hugohenrick/gtasks/repository"
 	"github.com/hugohenrick/gtasks/routes"
+	"github.com/hugohenrick/gtasks/stream"
+	"github.com/hugohenrick/gtasks/webhook"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -34,10 +42,18 @@ func main() {
 
 	router := gin.Default()
 
+	// the client IP, used by the login throttling, is read from X-Forwarded-For
+	// only when the request comes through one of the TRUSTED_PROXIES
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		fmt.Printf("error setting trusted proxies: %s\n", err)
+		os.Exit(1)
+	}
+
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     cors.DefaultConfig().AllowMethods,
-		AllowHeaders:     cors.DefaultConfig().AllowHeaders,
+		AllowHeaders:     append(cors.DefaultConfig().AllowHeaders, middlewares.CorrelationIdHeader),
+		ExposeHeaders:    []string{middlewares.CorrelationIdHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -47,6 +63,7 @@ func main() {
 		httpPort = ":" + os.Getenv("SERVER_PORT")
 	}
 
+	router.Use(middlewares.CorrelationId())
 	router.Use(middlewares.Authenticate())
 
 	database.Conn()
@@ -56,24 +73,102 @@ func main() {
 	case "users":
 		routes.AddUserRoutes(router)
 		repository.UserRepositoryServices = repository.NewUserRepository()
+		repository.NotificationRepositoryServices = repository.NewNotificationRepository()
+		repository.WatcherRepositoryServices = repository.NewWatcherRepository()
+		repository.UserTokenRepositoryServices = repository.NewUserTokenRepository()
+		repository.LoginAttemptRepositoryServices = repository.NewLoginAttemptRepository()
+		repository.MfaRepositoryServices = repository.NewMfaRepository()
+		repository.ApiTokenRepositoryServices = repository.NewApiTokenRepository()
+		repository.OrganizationRepositoryServices = repository.NewOrganizationRepository()
+		repository.OutboxRepositoryServices = repository.NewOutboxRepository()
+		auth.OIDCProviderServices = auth.NewOIDCProvider(auth.OIDCConfigFromEnv())
+		mailer.MailerServices = mailer.NewMailer()
 	case "tasks":
 		routes.AddTaskRoutes(router)
+		routes.AddCustomFieldRoutes(router)
+		routes.AddTemplateRoutes(router)
+		routes.AddProjectRoutes(router)
+		routes.AddWebhookRoutes(router)
 		repository.TaskRepositoryServices = repository.NewTaskRepository()
+		repository.TemplateRepositoryServices = repository.NewTemplateRepository()
+		repository.CustomerRepositoryServices = repository.NewCustomerRepository()
+		repository.SiteRepositoryServices = repository.NewSiteRepository()
+		repository.ProjectRepositoryServices = repository.NewProjectRepository()
+		repository.CustomFieldRepositoryServices = repository.NewCustomFieldRepository()
+		repository.WatcherRepositoryServices = repository.NewWatcherRepository()
+		repository.NotificationRepositoryServices = repository.NewNotificationRepository()
+		repository.OutboxRepositoryServices = repository.NewOutboxRepository()
+		repository.WebhookRepositoryServices = repository.NewWebhookRepository()
+		repository.WebhookDeliveryRepositoryServices = repository.NewWebhookDeliveryRepository()
+		repository.UserRepositoryServices = repository.NewUserRepository()
+		repository.ApiTokenRepositoryServices = repository.NewApiTokenRepository()
+		repository.EmailRepositoryServices = repository.NewEmailRepository()
+		mailer.MailerServices = mailer.NewMailer()
 	default:
 		repository.UserRepositoryServices = repository.NewUserRepository()
 		repository.TaskRepositoryServices = repository.NewTaskRepository()
+		repository.WatcherRepositoryServices = repository.NewWatcherRepository()
+		repository.NotificationRepositoryServices = repository.NewNotificationRepository()
+		repository.CustomFieldRepositoryServices = repository.NewCustomFieldRepository()
+		repository.TemplateRepositoryServices = repository.NewTemplateRepository()
+		repository.CustomerRepositoryServices = repository.NewCustomerRepository()
+		repository.SiteRepositoryServices = repository.NewSiteRepository()
+		repository.ProjectRepositoryServices = repository.NewProjectRepository()
+		repository.UserTokenRepositoryServices = repository.NewUserTokenRepository()
+		repository.LoginAttemptRepositoryServices = repository.NewLoginAttemptRepository()
+		repository.MfaRepositoryServices = repository.NewMfaRepository()
+		repository.ApiTokenRepositoryServices = repository.NewApiTokenRepository()
+		repository.OrganizationRepositoryServices = repository.NewOrganizationRepository()
+		repository.OutboxRepositoryServices = repository.NewOutboxRepository()
+		repository.WebhookRepositoryServices = repository.NewWebhookRepository()
+		repository.WebhookDeliveryRepositoryServices = repository.NewWebhookDeliveryRepository()
+		repository.EmailRepositoryServices = repository.NewEmailRepository()
+		auth.OIDCProviderServices = auth.NewOIDCProvider(auth.OIDCConfigFromEnv())
+		mailer.MailerServices = mailer.NewMailer()
 		routes.AddUserRoutes(router)
 		routes.AddTaskRoutes(router)
+		routes.AddCustomFieldRoutes(router)
+		routes.AddTemplateRoutes(router)
+		routes.AddProjectRoutes(router)
+		routes.AddWebhookRoutes(router)
 	}
+	routes.AddAdminRoutes(router)
 
 	//Message Broker
 	ctx := context.Background()
-	rabbitmq.Start(ctx)
+	startBroker(ctx)
+	broker.ProcessedStoreServices = repository.NewProcessedMessageRepository()
+	if repository.WebhookDeliveryRepositoryServices != nil {
+		broker.Consumers = append(broker.Consumers, webhook.Consumer)
+	}
+	if repository.EmailRepositoryServices != nil {
+		broker.Consumers = append(broker.Consumers, notification.EmailConsumer)
+	}
+	if repository.TaskRepositoryServices != nil {
+		broker.Consumers = append(broker.Consumers, notification.WatcherConsumer)
+		if consumer, err := stream.NewConsumer(); err != nil {
+			fmt.Printf("error creating stream consumer: %s\n", err)
+		} else {
+			broker.Consumers = append(broker.Consumers, consumer)
+		}
+	}
+	if err := broker.StartConsumers(ctx, broker.BrokerServices); err != nil {
+		fmt.Printf("error starting consumers: %s\n", err)
+	}
+	outbox.Start(ctx)
+	if repository.WebhookDeliveryRepositoryServices != nil {
+		webhook.Start(ctx)
+	}
+	if repository.EmailRepositoryServices != nil {
+		notification.StartScheduler(ctx)
+	}
 
 	server := &http.Server{
 		Addr:    httpPort,
 		Handler: router,
 	}
+	// Shutdown waits for the active requests, so the open task streams are ended
+	server.RegisterOnShutdown(stream.Tasks.Close)
 
 	// start API server
 	go func() {
@@ -100,3 +195,31 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// startBroker starts the message broker of MESSAGE_BROKER: RabbitMQ (amqp, by
+// default) or memory, to run without RabbitMQ
+func startBroker(ctx context.Context) {
+	if os.Getenv("MESSAGE_BROKER") == "memory" {
+		memory := broker.NewMemory()
+		broker.BrokerServices = memory
+		broker.DeadLetterServices = memory
+		return
+	}
+
+	amqp := rabbitmq.New()
+	amqp.Start(ctx)
+	broker.BrokerServices = amqp
+	broker.DeadLetterServices = rabbitmq.NewDeadLetters()
+}
+
+// trustedProxies returns the IPs or CIDRs of TRUSTED_PROXIES (comma separated),
+// none by default so the client IP is the address of the connection
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
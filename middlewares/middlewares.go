@@ -1,15 +1,22 @@
 package middlewares
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
+	"github.com/hugohenrick/gtasks/auth"
 	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
 	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
 )
 
 func Authenticate() gin.HandlerFunc {
@@ -25,6 +32,11 @@ func Authenticate() gin.HandlerFunc {
 				return
 			}
 
+			if auth.IsApiToken(strings.TrimPrefix(tokenString, "Bearer ")) {
+				authenticateApiToken(c, strings.TrimPrefix(tokenString, "Bearer "), interceptorValue)
+				return
+			}
+
 			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 					return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
@@ -48,26 +60,41 @@ func Authenticate() gin.HandlerFunc {
 			}
 
 			if claims, ok := token.Claims.(jwt.MapClaims); ok {
-				if float64(time.Now().Unix()) > claims["exp"].(float64) {
+				exp := claims["exp"].(float64)
+				if float64(time.Now().Unix()) > exp {
 					c.AbortWithStatus(http.StatusUnauthorized)
 				}
 
 				var user models.User
-				database.DB.First(&user, claims["user"])
+				if data, ok := claims["user"].(map[string]interface{}); ok {
+					database.DB.First(&user, "id = ?", data["id"])
+				}
 
-				if user.ID == 0 {
+				// deactivated users and tokens issued before the last invalidation are refused
+				version, _ := claims["ver"].(float64)
+				if user.ID == 0 || !user.Active || uint32(version) != user.TokenVersion || claims["typ"] != nil {
 					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 						"error": "unauthorized user",
 					})
 					return
 				}
 
+				if enroll, _ := claims["mfa_enroll"].(bool); enroll && !validateRequestMethod(interceptorValue, routesForMfaEnrollment) {
+					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+						"error": "two-factor authentication enrollment required",
+					})
+					return
+				}
+
 				// data := claims["user"].(map[string]interface{})
 				// isManager := data["is_manager"].(bool)
 				// userId := data["id"].(float64)
 
 				c.Set("isManager", user.IsManager)
 				c.Set("userId", user.ID)
+				c.Set("organizationId", user.OrganizationId)
+				c.Set("tokenVersion", user.TokenVersion)
+				c.Set("tokenExpiresAt", time.Unix(int64(exp), 0))
 
 				c.Next()
 
@@ -80,3 +107,84 @@ func Authenticate() gin.HandlerFunc {
 		}
 	}
 }
+
+// apiTokenTouchInterval limits the updates of the last use of api tokens
+const apiTokenTouchInterval = time.Minute
+
+// authenticateApiToken authenticates the request as the user of an api token,
+// limited to the scopes of the token
+func authenticateApiToken(c *gin.Context, tokenString string, interceptorValue string) {
+	timeNow := time.Now()
+
+	var token models.ApiToken
+	err := database.DB.First(&token, "token_hash = ?", utils.HashToken(tokenString)).Error
+
+	var user models.User
+	if err == nil && token.RevokedAt == nil && (token.ExpiresAt == nil || timeNow.Before(*token.ExpiresAt)) {
+		err = database.DB.First(&user, "id = ?", token.UserId).Error
+	}
+
+	// a token or user not found is unauthorized, a failing database must not look like one
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("error authenticating api token: %s\n", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "error authenticating the token",
+		})
+		return
+	}
+
+	if user.ID == 0 || !user.Active {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized user",
+		})
+		return
+	}
+
+	if auth.MfaRequired(user) && !user.MfaEnabled {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "two-factor authentication enrollment required",
+		})
+		return
+	}
+
+	if validateRequestMethod(interceptorValue, routesForbiddenToApiTokens) ||
+		!auth.ApiTokenAllows(token.ScopeList(), c.Request.Method, c.FullPath()) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "api token without access to this route",
+		})
+		return
+	}
+
+	if token.LastUsedAt == nil || timeNow.Sub(*token.LastUsedAt) > apiTokenTouchInterval {
+		database.DB.Model(&token).UpdateColumn("last_used_at", &timeNow)
+	}
+
+	c.Set("isManager", user.IsManager)
+	c.Set("userId", user.ID)
+	c.Set("organizationId", user.OrganizationId)
+	c.Set("apiTokenId", token.ID)
+	if token.ExpiresAt != nil {
+		c.Set("tokenExpiresAt", *token.ExpiresAt)
+	}
+
+	c.Next()
+}
+
+// CorrelationIdHeader is the header carrying the id that correlates a request
+// with the events it publishes
+const CorrelationIdHeader = "X-Correlation-ID"
+
+// CorrelationId keeps the correlation id sent by the client, or creates one,
+// and returns it in the response
+func CorrelationId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationId := strings.TrimSpace(c.GetHeader(CorrelationIdHeader))
+		if correlationId == "" || len(correlationId) > 128 {
+			correlationId, _ = events.NewId()
+		}
+
+		c.Set("correlationId", correlationId)
+		c.Header(CorrelationIdHeader, correlationId)
+		c.Next()
+	}
+}
@@ -1,10 +1,91 @@
 package middlewares
 
+// routesForMfaEnrollment are the only routes allowed to the tokens of users
+// that must enroll in MFA before using the api
+var routesForMfaEnrollment = []string{
+	"GET/user/me",
+	"POST/user/me/mfa/enroll",
+	"POST/user/me/mfa/confirm",
+}
+
+// routesForbiddenToApiTokens manage the profile and the credentials of the
+// user, they require a login token whatever the scopes of the api token
+var routesForbiddenToApiTokens = []string{
+	"PATCH/user/me",
+	"POST/user/me/password",
+	"POST/user/me/mfa/enroll",
+	"POST/user/me/mfa/confirm",
+	"POST/user/me/mfa/disable",
+	"POST/user/me/mfa/recovery-codes",
+	"GET/user/me/tokens",
+	"POST/user/me/tokens",
+	"DELETE/user/me/tokens/:id",
+}
+
 var routesWithAuthentication = []string{
 	"GET/task",
+	"GET/task/stream",
 	"GET/task/:id",
 	"POST/task",
 	"PATCH/task/execute/:id",
+	"POST/task/:id/review",
 	"PATCH/task/:id",
 	"DELETE/task/:id",
+	"GET/task/:id/watchers",
+	"POST/task/:id/watch",
+	"DELETE/task/:id/watch",
+	"GET/template",
+	"GET/template/:id",
+	"POST/template",
+	"PATCH/template/:id",
+	"POST/task/from-template/:templateId",
+	"GET/customer",
+	"GET/customer/:id",
+	"POST/customer",
+	"PATCH/customer/:id",
+	"DELETE/customer/:id",
+	"GET/site",
+	"GET/site/:id",
+	"POST/site",
+	"PATCH/site/:id",
+	"DELETE/site/:id",
+	"GET/project",
+	"GET/project/:id",
+	"GET/project/:id/summary",
+	"POST/project",
+	"PATCH/project/:id",
+	"DELETE/project/:id",
+	"GET/custom-field",
+	"POST/custom-field",
+	"PATCH/custom-field/:id",
+	"DELETE/custom-field/:id",
+	"GET/webhook",
+	"POST/webhook",
+	"PATCH/webhook/:id",
+	"DELETE/webhook/:id",
+	"GET/webhook/:id/deliveries",
+	"GET/user",
+	"GET/user/me",
+	"PATCH/user/me",
+	"POST/user/me/password",
+	"POST/user/me/mfa/enroll",
+	"POST/user/me/mfa/confirm",
+	"POST/user/me/mfa/disable",
+	"POST/user/me/mfa/recovery-codes",
+	"GET/user/me/tokens",
+	"POST/user/me/tokens",
+	"DELETE/user/me/tokens/:id",
+	"GET/user/:id",
+	"PATCH/user/:id",
+	"DELETE/user/:id",
+	"POST/user/:id/deactivate",
+	"POST/user/:id/activate",
+	"GET/user/notification-preferences",
+	"PUT/user/notification-preferences",
+	"GET/organization",
+	"PATCH/organization",
+	"GET/admin/dead-letters/:queue",
+	"POST/admin/dead-letters/:queue/replay",
+	"DELETE/admin/dead-letters/:queue",
+	"GET/admin/consumers",
 }
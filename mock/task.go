@@ -3,6 +3,7 @@
 package mock
 
 import (
+	events "github.com/hugohenrick/gtasks/events"
 	models "github.com/hugohenrick/gtasks/models"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,20 +13,20 @@ type ITaskRepository struct {
 	mock.Mock
 }
 
-// CreateTask provides a mock function with given fields: task
-func (_m *ITaskRepository) CreateTask(task models.Task) (models.Task, error) {
-	ret := _m.Called(task)
+// CreateTask provides a mock function with given fields: task, meta
+func (_m *ITaskRepository) CreateTask(task models.Task, meta events.Meta) (models.Task, error) {
+	ret := _m.Called(task, meta)
 
 	var r0 models.Task
-	if rf, ok := ret.Get(0).(func(models.Task) models.Task); ok {
-		r0 = rf(task)
+	if rf, ok := ret.Get(0).(func(models.Task, events.Meta) models.Task); ok {
+		r0 = rf(task, meta)
 	} else {
 		r0 = ret.Get(0).(models.Task)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(models.Task) error); ok {
-		r1 = rf(task)
+	if rf, ok := ret.Get(1).(func(models.Task, events.Meta) error); ok {
+		r1 = rf(task, meta)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -33,20 +34,20 @@ func (_m *ITaskRepository) CreateTask(task models.Task) (models.Task, error) {
 	return r0, r1
 }
 
-// DeleteTask provides a mock function with given fields: id
-func (_m *ITaskRepository) DeleteTask(id string) (int64, error) {
-	ret := _m.Called(id)
+// DeleteTask provides a mock function with given fields: id, meta
+func (_m *ITaskRepository) DeleteTask(id string, meta events.Meta) (int64, error) {
+	ret := _m.Called(id, meta)
 
 	var r0 int64
-	if rf, ok := ret.Get(0).(func(string) int64); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(string, events.Meta) int64); ok {
+		r0 = rf(id, meta)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(string, events.Meta) error); ok {
+		r1 = rf(id, meta)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -54,20 +55,20 @@ func (_m *ITaskRepository) DeleteTask(id string) (int64, error) {
 	return r0, r1
 }
 
-// ExecuteTask provides a mock function with given fields: id, task
-func (_m *ITaskRepository) ExecuteTask(id string, task models.Task) (models.Task, error) {
-	ret := _m.Called(id, task)
+// ExecuteTask provides a mock function with given fields: id, task, meta
+func (_m *ITaskRepository) ExecuteTask(id string, task models.Task, meta events.Meta) (models.Task, error) {
+	ret := _m.Called(id, task, meta)
 
 	var r0 models.Task
-	if rf, ok := ret.Get(0).(func(string, models.Task) models.Task); ok {
-		r0 = rf(id, task)
+	if rf, ok := ret.Get(0).(func(string, models.Task, events.Meta) models.Task); ok {
+		r0 = rf(id, task, meta)
 	} else {
 		r0 = ret.Get(0).(models.Task)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, models.Task) error); ok {
-		r1 = rf(id, task)
+	if rf, ok := ret.Get(1).(func(string, models.Task, events.Meta) error); ok {
+		r1 = rf(id, task, meta)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -96,13 +97,13 @@ func (_m *ITaskRepository) FindTaskById(id string) (models.Task, error) {
 	return r0, r1
 }
 
-// FindTasks provides a mock function with given fields: task
-func (_m *ITaskRepository) FindTasks(task models.Task) ([]models.Task, error) {
-	ret := _m.Called(task)
+// FindTasks provides a mock function with given fields: filter
+func (_m *ITaskRepository) FindTasks(filter models.TaskFilter) ([]models.Task, error) {
+	ret := _m.Called(filter)
 
 	var r0 []models.Task
-	if rf, ok := ret.Get(0).(func(models.Task) []models.Task); ok {
-		r0 = rf(task)
+	if rf, ok := ret.Get(0).(func(models.TaskFilter) []models.Task); ok {
+		r0 = rf(filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Task)
@@ -110,8 +111,29 @@ func (_m *ITaskRepository) FindTasks(task models.Task) ([]models.Task, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(models.Task) error); ok {
-		r1 = rf(task)
+	if rf, ok := ret.Get(1).(func(models.TaskFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewTask provides a mock function with given fields: id, review, meta
+func (_m *ITaskRepository) ReviewTask(id string, review models.TaskReview, meta events.Meta) (models.Task, error) {
+	ret := _m.Called(id, review, meta)
+
+	var r0 models.Task
+	if rf, ok := ret.Get(0).(func(string, models.TaskReview, events.Meta) models.Task); ok {
+		r0 = rf(id, review, meta)
+	} else {
+		r0 = ret.Get(0).(models.Task)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, models.TaskReview, events.Meta) error); ok {
+		r1 = rf(id, review, meta)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -119,20 +141,20 @@ func (_m *ITaskRepository) FindTasks(task models.Task) ([]models.Task, error) {
 	return r0, r1
 }
 
-// UpdateTask provides a mock function with given fields: id, task
-func (_m *ITaskRepository) UpdateTask(id string, task models.Task) (models.Task, error) {
-	ret := _m.Called(id, task)
+// UpdateTask provides a mock function with given fields: id, task, meta
+func (_m *ITaskRepository) UpdateTask(id string, task models.Task, meta events.Meta) (models.Task, error) {
+	ret := _m.Called(id, task, meta)
 
 	var r0 models.Task
-	if rf, ok := ret.Get(0).(func(string, models.Task) models.Task); ok {
-		r0 = rf(id, task)
+	if rf, ok := ret.Get(0).(func(string, models.Task, events.Meta) models.Task); ok {
+		r0 = rf(id, task, meta)
 	} else {
 		r0 = ret.Get(0).(models.Task)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, models.Task) error); ok {
-		r1 = rf(id, task)
+	if rf, ok := ret.Get(1).(func(string, models.Task, events.Meta) error); ok {
+		r1 = rf(id, task, meta)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -3,6 +3,7 @@
 package mock
 
 import (
+	events "github.com/hugohenrick/gtasks/events"
 	models "github.com/hugohenrick/gtasks/models"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,20 +13,85 @@ type IUserRepository struct {
 	mock.Mock
 }
 
-// CreateUser provides a mock function with given fields: User
-func (_m *IUserRepository) CreateUser(User models.User) (models.User, error) {
-	ret := _m.Called(User)
+// ActivateUser provides a mock function with given fields: id, meta
+func (_m *IUserRepository) ActivateUser(id string, meta events.Meta) (models.User, error) {
+	ret := _m.Called(id, meta)
 
 	var r0 models.User
-	if rf, ok := ret.Get(0).(func(models.User) models.User); ok {
-		r0 = rf(User)
+	if rf, ok := ret.Get(0).(func(string, events.Meta) models.User); ok {
+		r0 = rf(id, meta)
 	} else {
 		r0 = ret.Get(0).(models.User)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(models.User) error); ok {
-		r1 = rf(User)
+	if rf, ok := ret.Get(1).(func(string, events.Meta) error); ok {
+		r1 = rf(id, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUser provides a mock function with given fields: User, meta
+func (_m *IUserRepository) CreateUser(User models.User, meta events.Meta) (models.User, error) {
+	ret := _m.Called(User, meta)
+
+	var r0 models.User
+	if rf, ok := ret.Get(0).(func(models.User, events.Meta) models.User); ok {
+		r0 = rf(User, meta)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.User, events.Meta) error); ok {
+		r1 = rf(User, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeactivateUser provides a mock function with given fields: id, reassignTo, meta
+func (_m *IUserRepository) DeactivateUser(id string, reassignTo uint32, meta events.Meta) ([]models.Task, error) {
+	ret := _m.Called(id, reassignTo, meta)
+
+	var r0 []models.Task
+	if rf, ok := ret.Get(0).(func(string, uint32, events.Meta) []models.Task); ok {
+		r0 = rf(id, reassignTo, meta)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Task)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, uint32, events.Meta) error); ok {
+		r1 = rf(id, reassignTo, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteUser provides a mock function with given fields: id, meta
+func (_m *IUserRepository) DeleteUser(id string, meta events.Meta) (int64, error) {
+	ret := _m.Called(id, meta)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, events.Meta) int64); ok {
+		r0 = rf(id, meta)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, events.Meta) error); ok {
+		r1 = rf(id, meta)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -54,13 +120,55 @@ func (_m *IUserRepository) FindUserByEmail(email string) (models.User, error) {
 	return r0, r1
 }
 
-// FindUsers provides a mock function with given fields: User
-func (_m *IUserRepository) FindUsers(User models.User) ([]models.User, error) {
-	ret := _m.Called(User)
+// FindUserById provides a mock function with given fields: id
+func (_m *IUserRepository) FindUserById(id string) (models.User, error) {
+	ret := _m.Called(id)
+
+	var r0 models.User
+	if rf, ok := ret.Get(0).(func(string) models.User); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindUserByOidcSubject provides a mock function with given fields: subject
+func (_m *IUserRepository) FindUserByOidcSubject(subject string) (models.User, error) {
+	ret := _m.Called(subject)
+
+	var r0 models.User
+	if rf, ok := ret.Get(0).(func(string) models.User); ok {
+		r0 = rf(subject)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindUsers provides a mock function with given fields: organizationId
+func (_m *IUserRepository) FindUsers(organizationId *uint32) ([]models.User, error) {
+	ret := _m.Called(organizationId)
 
 	var r0 []models.User
-	if rf, ok := ret.Get(0).(func(models.User) []models.User); ok {
-		r0 = rf(User)
+	if rf, ok := ret.Get(0).(func(*uint32) []models.User); ok {
+		r0 = rf(organizationId)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.User)
@@ -68,8 +176,127 @@ func (_m *IUserRepository) FindUsers(User models.User) ([]models.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(models.User) error); ok {
-		r1 = rf(User)
+	if rf, ok := ret.Get(1).(func(*uint32) error); ok {
+		r1 = rf(organizationId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveOidcLogin provides a mock function with given fields: id, subject, isManager, meta
+func (_m *IUserRepository) SaveOidcLogin(id string, subject string, isManager bool, meta events.Meta) (models.User, error) {
+	ret := _m.Called(id, subject, isManager, meta)
+
+	var r0 models.User
+	if rf, ok := ret.Get(0).(func(string, string, bool, events.Meta) models.User); ok {
+		r0 = rf(id, subject, isManager, meta)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, bool, events.Meta) error); ok {
+		r1 = rf(id, subject, isManager, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePassword provides a mock function with given fields: id, password
+func (_m *IUserRepository) UpdatePassword(id string, password string) (models.User, error) {
+	ret := _m.Called(id, password)
+
+	var r0 models.User
+	if rf, ok := ret.Get(0).(func(string, string) models.User); ok {
+		r0 = rf(id, password)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(id, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePasswordHash provides a mock function with given fields: id, password
+func (_m *IUserRepository) UpdatePasswordHash(id string, password string) error {
+	ret := _m.Called(id, password)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(id, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProfile provides a mock function with given fields: id, user, meta
+func (_m *IUserRepository) UpdateProfile(id string, user models.User, meta events.Meta) (models.User, error) {
+	ret := _m.Called(id, user, meta)
+
+	var r0 models.User
+	if rf, ok := ret.Get(0).(func(string, models.User, events.Meta) models.User); ok {
+		r0 = rf(id, user, meta)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, models.User, events.Meta) error); ok {
+		r1 = rf(id, user, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateUser provides a mock function with given fields: id, user, meta
+func (_m *IUserRepository) UpdateUser(id string, user models.User, meta events.Meta) (models.User, error) {
+	ret := _m.Called(id, user, meta)
+
+	var r0 models.User
+	if rf, ok := ret.Get(0).(func(string, models.User, events.Meta) models.User); ok {
+		r0 = rf(id, user, meta)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, models.User, events.Meta) error); ok {
+		r1 = rf(id, user, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyEmail provides a mock function with given fields: id
+func (_m *IUserRepository) VerifyEmail(id string) (models.User, error) {
+	ret := _m.Called(id)
+
+	var r0 models.User
+	if rf, ok := ret.Get(0).(func(string) models.User); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
 	} else {
 		r1 = ret.Error(1)
 	}
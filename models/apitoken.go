@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ApiToken is a long-lived token for integrations. It acts as its user, limited
+// to its scopes. Only the SHA-256 hash of the token is stored, the prefix
+// identifies the token in listings.
+type ApiToken struct {
+	ID         uint32     `gorm:"primary_key;auto_increment" json:"id"`
+	UserId     uint32     `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"size:500;not null" json:"scopes"` // comma separated
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+}
+
+// ScopeList returns the scopes of the token
+func (token ApiToken) ScopeList() []string {
+	if token.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(token.Scopes, ",")
+}
+
+// ApiTokenCreate is the body of POST /user/me/tokens. Tokens without
+// expires_in_days never expire.
+type ApiTokenCreate struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays uint32   `json:"expires_in_days"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Custom field types supported by the schema
+const (
+	CustomFieldString  = "string"
+	CustomFieldNumber  = "number"
+	CustomFieldDate    = "date"
+	CustomFieldEnum    = "enum"
+	CustomFieldBoolean = "boolean"
+)
+
+// CustomFieldSchema describes an extra field the organization wants on its tasks
+type CustomFieldSchema struct {
+	ID             uint32      `gorm:"primary_key;auto_increment" json:"id"`
+	OrganizationId uint32      `gorm:"not null;uniqueIndex:idx_organization_key" json:"organization_id"`
+	Key            string      `gorm:"size:50;not null;uniqueIndex:idx_organization_key" json:"key"`
+	Label          string      `gorm:"size:200" json:"label"`
+	Type           string      `gorm:"size:20;not null" json:"type"`
+	Required       bool        `json:"required"`
+	Pattern        string      `gorm:"size:500" json:"pattern,omitempty"`
+	Options        StringSlice `gorm:"type:json" json:"options,omitempty"`
+	CreatedAt      time.Time   `json:"created_at,omitempty"`
+	UpdatedAt      time.Time   `json:"updated_at,omitempty"`
+}
+
+// CustomFields holds the custom field values of a task, stored as a JSON column
+type CustomFields map[string]interface{}
+
+func (f CustomFields) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+func (f *CustomFields) Scan(value interface{}) error {
+	return scanJSON(value, f)
+}
+
+// StringSlice is a list of strings stored as a JSON column
+type StringSlice []string
+
+func (s StringSlice) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *StringSlice) Scan(value interface{}) error {
+	return scanJSON(value, s)
+}
+
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return errors.New("unsupported json column value")
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter is a message that failed all its attempts and waits in the
+// dead-letter queue of the queue it was consumed from
+type DeadLetter struct {
+	MessageId     string          `json:"message_id"`
+	CorrelationId string          `json:"correlation_id,omitempty"`
+	Queue         string          `json:"queue"`
+	ContentType   string          `json:"content_type,omitempty"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	FailedAt      *time.Time      `json:"failed_at,omitempty"`
+	Body          json.RawMessage `json:"body"`
+}
+
+// DeadLetterReplay is the number of dead-lettered messages to publish again to their queue
+type DeadLetterReplay struct {
+	Limit int `json:"limit"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Login attempt results
+const (
+	LoginSucceeded          = "succeeded"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginUserDeactivated    = "user_deactivated"
+	LoginMfaChallenged      = "mfa_challenged"
+	LoginInvalidMfaCode     = "invalid_mfa_code"
+	LoginPasswordDisabled   = "password_login_disabled"
+	LoginGroupNotAllowed    = "group_not_allowed"
+)
+
+// LoginFailureResults are the results of the wrong guesses counted by the
+// login throttling. Throttled attempts are not recorded.
+var LoginFailureResults = []string{LoginInvalidCredentials, LoginInvalidMfaCode}
+
+// LoginFailures counts the recent failed login attempts of an account or an IP
+type LoginFailures struct {
+	Count  int
+	LastAt *time.Time
+}
+
+// LoginAttempt is an entry of the login security log, it also backs the login throttling
+type LoginAttempt struct {
+	ID        uint32    `gorm:"primary_key;auto_increment" json:"id"`
+	UserId    *uint32   `gorm:"index" json:"user_id,omitempty"`
+	Email     string    `gorm:"size:255;not null;index" json:"email"`
+	IP        string    `gorm:"size:45;not null;index" json:"ip"`
+	UserAgent string    `gorm:"size:500" json:"user_agent"`
+	Success   bool      `json:"success"`
+	Result    string    `gorm:"size:30;not null" json:"result"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
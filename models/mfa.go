@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// RecoveryCode is a single-use code that replaces the TOTP code when the
+// authenticator is lost. Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint32     `gorm:"primary_key;auto_increment" json:"id"`
+	UserId    uint32     `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+}
+
+// MfaCode is the body of the endpoints that require a TOTP or recovery code
+type MfaCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MfaLogin is the body of POST /user/login/mfa
+type MfaLogin struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
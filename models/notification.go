@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+)
+
+// Task events that are fanned out to the task watchers
+const (
+	TaskEventStatusChange = "status_change"
+	TaskEventReassignment = "reassignment"
+	TaskEventCompletion   = "completion"
+)
+
+// TaskEvents lists the task events fanned out to the watchers
+var TaskEvents = []string{
+	TaskEventStatusChange,
+	TaskEventReassignment,
+	TaskEventCompletion,
+}
+
+// Emails a user can opt out of in the notification preferences
+const (
+	EmailEventAssignment = "email_assignment"
+	EmailEventCompletion = "email_completion"
+	EmailEventOverdue    = "email_overdue"
+	EmailEventDigest     = "email_digest"
+)
+
+// EmailEvents lists the emails sent by the notification service
+var EmailEvents = []string{
+	EmailEventAssignment,
+	EmailEventCompletion,
+	EmailEventOverdue,
+	EmailEventDigest,
+}
+
+// NotificationEvents lists every event a user can set a notification preference for
+var NotificationEvents = append(append([]string{}, TaskEvents...), EmailEvents...)
+
+type NotificationPreference struct {
+	ID        uint32    `gorm:"primary_key;auto_increment" json:"id"`
+	UserId    uint32    `gorm:"not null;uniqueIndex:idx_user_event" json:"user_id"`
+	Event     string    `gorm:"size:50;not null;uniqueIndex:idx_user_event" json:"event"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Notification is the message sent to a single watcher of a task
+type Notification struct {
+	UserId    uint32    `json:"user_id"`
+	TaskId    uint32    `json:"task_id"`
+	Event     string    `json:"event"`
+	ActorId   uint32    `json:"actor_id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SentEmail records an email of an event sent to a user, so a retried event
+// doesn't email again the users already emailed
+type SentEmail struct {
+	EventId string    `gorm:"primary_key;size:36" json:"event_id"`
+	UserId  uint32    `gorm:"primary_key;autoIncrement:false" json:"user_id"`
+	SentAt  time.Time `gorm:"index" json:"sent_at"`
+}
+
+// EmailDigest records the last daily digest sent to a user, SentOn is the
+// date in the timezone of the user
+type EmailDigest struct {
+	UserId uint32    `gorm:"primary_key;autoIncrement:false" json:"user_id"`
+	SentOn string    `gorm:"size:10;not null" json:"sent_on"`
+	SentAt time.Time `json:"sent_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+type Organization struct {
+	ID                    uint32    `gorm:"primary_key;auto_increment" json:"id"`
+	Name                  string    `gorm:"size:200;not null" json:"name"`
+	PasswordLoginDisabled bool      `gorm:"not null;default:false" json:"password_login_disabled"`
+	CreatedAt             time.Time `json:"created_at,omitempty"`
+	UpdatedAt             time.Time `json:"updated_at,omitempty"`
+}
+
+// OrganizationUpdate is the body of PATCH /organization, only the given fields change
+type OrganizationUpdate struct {
+	Name                  *string `json:"name"`
+	PasswordLoginDisabled *bool   `json:"password_login_disabled"`
+}
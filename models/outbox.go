@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxMessage is a domain event saved in the same transaction as the change
+// it describes, waiting to be published to the message broker by the relay
+type OutboxMessage struct {
+	ID            uint64     `gorm:"primary_key;auto_increment" json:"id"`
+	EventId       string     `gorm:"size:36;not null;uniqueIndex" json:"event_id"`
+	EventType     string     `gorm:"size:50;not null" json:"event_type"`
+	AggregateType string     `gorm:"size:20;not null;index:idx_outbox_aggregate" json:"aggregate_type"`
+	AggregateId   uint32     `gorm:"not null;index:idx_outbox_aggregate" json:"aggregate_id"`
+	Payload       string     `gorm:"type:json;not null" json:"payload"`
+	Attempts      uint32     `json:"attempts"`
+	Rejections    uint32     `json:"rejections"` // failed attempts refused by the broker, e.g. unroutable
+	LastError     string     `gorm:"size:500" json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `gorm:"index" json:"sent_at,omitempty"`
+	FailedAt      *time.Time `gorm:"index" json:"failed_at,omitempty"` // set when the relay gave up, after OUTBOX_MAX_ATTEMPTS rejections
+	CreatedAt     time.Time  `json:"created_at"`
+}
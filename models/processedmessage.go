@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// ProcessedMessage records a message consumed from a queue, so its
+// redeliveries are skipped until it expires. A message being processed holds a
+// short lease; when the consumer dies the lease expires and a redelivery
+// processes it again.
+type ProcessedMessage struct {
+	Queue     string    `gorm:"primaryKey;size:100" json:"queue"`
+	MessageId string    `gorm:"primaryKey;size:100" json:"message_id"`
+	Processed bool      `gorm:"not null" json:"processed"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Results of claiming a message for processing
+const (
+	MessageClaimed    = "claimed"
+	MessageInProgress = "in_progress"
+	MessageProcessed  = "processed"
+)
+
+// ConsumerStats counts the messages handled by the consumer of a queue
+type ConsumerStats struct {
+	Queue         string  `json:"queue"`
+	Processed     uint64  `json:"processed"`
+	Failed        uint64  `json:"failed"`
+	Duplicates    uint64  `json:"duplicates"`
+	DuplicateRate float64 `json:"duplicate_rate"`
+}
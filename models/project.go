@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+type Customer struct {
+	ID             uint32    `gorm:"primary_key;auto_increment" json:"id"`
+	OrganizationId uint32    `gorm:"index" json:"organization_id"`
+	Name           string    `gorm:"size:200;not null" json:"name"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+type Site struct {
+	ID             uint32    `gorm:"primary_key;auto_increment" json:"id"`
+	OrganizationId uint32    `gorm:"index" json:"organization_id"`
+	CustomerId     uint32    `gorm:"not null;index" json:"customer_id"`
+	Customer       *Customer `json:"customer,omitempty"`
+	Name           string    `gorm:"size:200;not null" json:"name"`
+	Address        string    `gorm:"size:500" json:"address,omitempty"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+type Project struct {
+	ID             uint32    `gorm:"primary_key;auto_increment" json:"id"`
+	OrganizationId uint32    `gorm:"index" json:"organization_id"`
+	SiteId         uint32    `gorm:"not null;index" json:"site_id"`
+	Site           *Site     `json:"site,omitempty"`
+	Name           string    `gorm:"size:200;not null" json:"name"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+// ProjectSummary is the rollup of the tasks of a project
+type ProjectSummary struct {
+	ProjectId      uint32  `json:"project_id"`
+	OpenTasks      int64   `json:"open_tasks"`
+	DoneTasks      int64   `json:"done_tasks"`
+	EstimatedHours float64 `json:"estimated_hours"`
+	DoneHours      float64 `json:"done_hours"`
+}
@@ -1,17 +1,119 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"time"
 )
 
+// Task priorities
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityNormal = "normal"
+	TaskPriorityHigh   = "high"
+	TaskPriorityUrgent = "urgent"
+)
+
+// Task statuses. Tasks that require approval go through pending_review
+// after the execution and are either approved (done) or rejected.
+const (
+	TaskStatusOpen          = "open"
+	TaskStatusPendingReview = "pending_review"
+	TaskStatusRejected      = "rejected"
+	TaskStatusDone          = "done"
+)
+
+// TaskPriorities lists the accepted task priorities
+var TaskPriorities = []string{TaskPriorityLow, TaskPriorityNormal, TaskPriorityHigh, TaskPriorityUrgent}
+
 type Task struct {
-	ID         uint32     `gorm:"primary_key;auto_increment" json:"id"`
-	Title      string     `gorm:"size:200;not null" json:"title"`
-	Summary    string     `gorm:"size:2500;not null" json:"summary"`
-	UserId     uint32     `gorm:"not null" json:"user_id"`
-	Done       bool       `json:"done"`
-	User       User       `json:"user,omitempty"`
-	CreatedAt  time.Time  `json:"created_at,omitempty"`
-	UpdatedAt  time.Time  `json:"updated_at,omitempty"`
-	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	ID                uint32       `gorm:"primary_key;auto_increment" json:"id"`
+	Title             string       `gorm:"size:200;not null" json:"title"`
+	Summary           string       `gorm:"size:2500;not null" json:"summary"`
+	UserId            uint32       `gorm:"not null" json:"user_id"`
+	OrganizationId    uint32       `gorm:"index" json:"organization_id"`
+	ProjectId         *uint32      `gorm:"index" json:"project_id,omitempty"`
+	Done              bool         `json:"done"`
+	Status            string       `gorm:"size:20;default:open" json:"status"`
+	RequiresApproval  bool         `json:"requires_approval"`
+	User              User         `json:"user,omitempty"`
+	Priority          string       `gorm:"size:20" json:"priority,omitempty"`
+	Tags              StringSlice  `gorm:"type:json" json:"tags,omitempty"`
+	Checklist         Checklist    `gorm:"type:json" json:"checklist,omitempty"`
+	EstimatedMinutes  uint32       `json:"estimated_minutes,omitempty"`
+	TemplateVersionId *uint32      `json:"template_version_id,omitempty"`
+	CustomFields      CustomFields `gorm:"type:json" json:"custom_fields,omitempty"`
+	DueAt             *time.Time   `gorm:"index" json:"due_at,omitempty"`
+	OverdueNotifiedAt *time.Time   `json:"-"` // when the overdue email was sent, reset when due_at changes
+	CreatedAt         time.Time    `json:"created_at,omitempty"`
+	UpdatedAt         time.Time    `json:"updated_at,omitempty"`
+	FinishedAt        *time.Time   `json:"finished_at,omitempty"`
+}
+
+type ChecklistItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// Checklist is the list of steps of a task, stored as a JSON column
+type Checklist []ChecklistItem
+
+func (c Checklist) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *Checklist) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+// CustomFieldSortPrefix marks a TaskFilter.SortBy value as a custom field key
+const CustomFieldSortPrefix = "cf."
+
+// TaskSortColumns lists the task columns FindTasks can be sorted by
+var TaskSortColumns = []string{"id", "title", "created_at", "updated_at", "finished_at", "due_at"}
+
+// TaskFilter narrows and orders the tasks returned by FindTasks
+type TaskFilter struct {
+	OrganizationId uint32 // always applied, tasks of other organizations are never returned
+	UserId         uint32
+	CustomerId     uint32
+	SiteId         uint32
+	ProjectId      uint32
+	CustomFields   map[string]string
+	SortBy         string
+	SortDesc       bool
+}
+
+type TaskReview struct {
+	ID         uint32    `gorm:"primary_key;auto_increment" json:"id"`
+	TaskId     uint32    `gorm:"not null;index" json:"task_id"`
+	ReviewerId uint32    `gorm:"not null" json:"reviewer_id"`
+	Approved   bool      `json:"approved"`
+	Comment    string    `gorm:"size:2500" json:"comment"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+}
+
+// TaskReviewRequest is the body of POST /task/:id/review
+type TaskReviewRequest struct {
+	Approved bool   `json:"approved"`
+	Comment  string `json:"comment"`
+}
+
+// TaskCreate is the body of POST /task. The status and done are set by the
+// server; requires_approval is only chosen by managers.
+type TaskCreate struct {
+	Title            string       `json:"title"`
+	Summary          string       `json:"summary"`
+	UserId           uint32       `json:"user_id"`
+	ProjectId        *uint32      `json:"project_id"`
+	RequiresApproval *bool        `json:"requires_approval"`
+	Priority         string       `json:"priority"`
+	Tags             StringSlice  `json:"tags"`
+	Checklist        Checklist    `json:"checklist"`
+	EstimatedMinutes uint32       `json:"estimated_minutes"`
+	CustomFields     CustomFields `json:"custom_fields"`
+	DueAt            *time.Time   `json:"due_at"`
 }
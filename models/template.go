@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// TaskTemplate is a reusable job type. Every edit creates a new
+// TaskTemplateVersion, so tasks created from a template keep the version
+// they were created from.
+type TaskTemplate struct {
+	ID             uint32                `gorm:"primary_key;auto_increment" json:"id"`
+	OrganizationId uint32                `gorm:"index" json:"organization_id"`
+	Name           string                `gorm:"size:200;not null" json:"name"`
+	LatestVersion  uint32                `gorm:"not null" json:"latest_version"`
+	Versions       []TaskTemplateVersion `gorm:"ForeignKey:TemplateId" json:"versions,omitempty"`
+	CreatedAt      time.Time             `json:"created_at,omitempty"`
+	UpdatedAt      time.Time             `json:"updated_at,omitempty"`
+}
+
+type TaskTemplateVersion struct {
+	ID               uint32      `gorm:"primary_key;auto_increment" json:"id"`
+	TemplateId       uint32      `gorm:"not null;uniqueIndex:idx_template_version" json:"template_id"`
+	Version          uint32      `gorm:"not null;uniqueIndex:idx_template_version" json:"version"`
+	Title            string      `gorm:"size:200;not null" json:"title"`
+	Summary          string      `gorm:"size:2500;not null" json:"summary"`
+	Checklist        StringSlice `gorm:"type:json" json:"checklist,omitempty"`
+	Priority         string      `gorm:"size:20" json:"priority,omitempty"`
+	Tags             StringSlice `gorm:"type:json" json:"tags,omitempty"`
+	EstimatedMinutes uint32      `json:"estimated_minutes,omitempty"`
+	RequiresApproval bool        `json:"requires_approval"`
+	CreatedAt        time.Time   `json:"created_at,omitempty"`
+}
+
+type TaskTemplateRegister struct {
+	Name             string   `json:"name"`
+	Title            string   `json:"title"`
+	Summary          string   `json:"summary"`
+	Checklist        []string `json:"checklist"`
+	Priority         string   `json:"priority"`
+	Tags             []string `json:"tags"`
+	EstimatedMinutes uint32   `json:"estimated_minutes"`
+	RequiresApproval bool     `json:"requires_approval"`
+}
+
+// TaskFromTemplate is the body of POST /task/from-template/:templateId
+type TaskFromTemplate struct {
+	UserId       uint32            `json:"user_id"`
+	Variables    map[string]string `json:"variables"`
+	CustomFields CustomFields      `json:"custom_fields"`
+}
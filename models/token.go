@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// User token purposes
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+// UserToken is a single-use token sent by email. Only the SHA-256 hash of the
+// token is stored.
+type UserToken struct {
+	ID        uint32     `gorm:"primary_key;auto_increment" json:"id"`
+	UserId    uint32     `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"size:30;not null" json:"purpose"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+}
+
+// PasswordForgot is the body of POST /user/password/forgot
+type PasswordForgot struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// PasswordReset is the body of POST /user/password/reset
+type PasswordReset struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// EmailVerification is the body of POST /user/email/verify
+type EmailVerification struct {
+	Token string `json:"token" binding:"required"`
+}
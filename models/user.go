@@ -5,14 +5,26 @@ import (
 )
 
 type User struct {
-	ID        uint32    `gorm:"primary_key;auto_increment" json:"id"`
-	Name      string    `gorm:"not null" json:"name"`
-	Email     string    `gorm:"not null" json:"email"`
-	Password  string    `gorm:"not null" json:"password"`
-	IsManager bool      `json:"is_manager"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
-	UpdatedAt time.Time `json:"updated_at,omitempty"`
-	Tasks     []Task    `gorm:"ForeignKey:UserId" json:"tasks,omitempty"`
+	ID             uint32     `gorm:"primary_key;auto_increment" json:"id"`
+	Name           string     `gorm:"not null" json:"name"`
+	Email          string     `gorm:"size:255;not null" json:"email"`               // unique index created by database.MigrateUserEmails
+	EmailVerified  bool       `gorm:"not null;default:false" json:"email_verified"` // required to link a single sign-on identity, informational otherwise
+	Password       string     `gorm:"not null" json:"-"`
+	IsManager      bool       `json:"is_manager"`
+	OrganizationId uint32     `gorm:"index" json:"organization_id"`
+	Timezone       string     `gorm:"size:64" json:"timezone,omitempty"`
+	Locale         string     `gorm:"size:16" json:"locale,omitempty"`
+	AvatarUrl      string     `gorm:"size:500" json:"avatar_url,omitempty"`
+	Active         bool       `gorm:"default:true" json:"active"`
+	MfaEnabled     bool       `gorm:"not null;default:false" json:"mfa_enabled"`
+	MfaSecret      string     `gorm:"size:64" json:"-"`
+	MfaLastStep    int64      `gorm:"not null;default:0" json:"-"`
+	OidcSubject    *string    `gorm:"size:255;uniqueIndex" json:"-"` // subject of the user at the identity provider
+	TokenVersion   uint32     `gorm:"not null;default:0" json:"-"`
+	DeactivatedAt  *time.Time `json:"deactivated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`
+	Tasks          []Task     `gorm:"ForeignKey:UserId" json:"tasks,omitempty"`
 }
 
 func (user *User) TableName() string {
@@ -24,8 +36,36 @@ type UserLogin struct {
 	Password string `form:"password" binding:"required"`
 }
 
+// UserRegister is the body of the self-signup, POST /user. The role and the
+// organization are not chosen by the user.
 type UserRegister struct {
 	Email    string `form:"email" json:"email" binding:"required"`
 	Password string `form:"password" json:"password" binding:"required"`
-	Name     string `form:"name"`
+	Name     string `form:"name" json:"name"`
+}
+
+// UserUpdate is the body of PATCH /user/:id, only the given fields change
+type UserUpdate struct {
+	Name      *string `json:"name"`
+	Email     *string `json:"email"`
+	IsManager *bool   `json:"is_manager"`
+}
+
+// UserProfile is the body of PATCH /user/me, only the given fields change
+type UserProfile struct {
+	Name      *string `json:"name"`
+	Timezone  *string `json:"timezone"`
+	Locale    *string `json:"locale"`
+	AvatarUrl *string `json:"avatar_url"`
+}
+
+// UserPasswordChange is the body of POST /user/me/password
+type UserPasswordChange struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// UserDeactivate is the body of POST /user/:id/deactivate
+type UserDeactivate struct {
+	ReassignTo uint32 `json:"reassign_to"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+type TaskWatcher struct {
+	ID        uint32    `gorm:"primary_key;auto_increment" json:"id"`
+	TaskId    uint32    `gorm:"not null;uniqueIndex:idx_task_watcher" json:"task_id"`
+	UserId    uint32    `gorm:"not null;uniqueIndex:idx_task_watcher" json:"user_id"`
+	User      User      `json:"user,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
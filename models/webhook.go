@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook is a subscription of an organization to task events, delivered as
+// signed HTTP POST requests to its url. The secret signs the requests, so it is
+// kept in clear and only returned when the webhook is created. Webhooks are
+// disabled after too many consecutive failed deliveries.
+type Webhook struct {
+	ID                  uint32     `gorm:"primary_key;auto_increment" json:"id"`
+	OrganizationId      uint32     `gorm:"not null;index" json:"organization_id"`
+	Url                 string     `gorm:"size:500;not null" json:"url"`
+	EventTypes          string     `gorm:"size:255;not null" json:"event_types"` // comma separated
+	Secret              string     `gorm:"size:100;not null" json:"-"`
+	Active              bool       `gorm:"not null;default:true" json:"active"`
+	ConsecutiveFailures uint32     `gorm:"not null;default:0" json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at,omitempty"`
+	UpdatedAt           time.Time  `json:"updated_at,omitempty"`
+}
+
+// EventTypeList returns the event types the webhook is subscribed to
+func (webhook Webhook) EventTypeList() []string {
+	if webhook.EventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(webhook.EventTypes, ",")
+}
+
+// WebhookCreate is the body of POST /webhook
+type WebhookCreate struct {
+	Url        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required"`
+}
+
+// WebhookUpdate is the body of PATCH /webhook/:id, only the fields sent are
+// changed. Activating a webhook resets its failures.
+type WebhookUpdate struct {
+	Url        *string   `json:"url"`
+	EventTypes *[]string `json:"event_types"`
+	Active     *bool     `json:"active"`
+}
+
+// Status of a webhook delivery
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is an event to deliver to a webhook and the log of its
+// attempts. Pending deliveries are retried with an exponential backoff until
+// they succeed or fail for good.
+type WebhookDelivery struct {
+	ID             uint64     `gorm:"primary_key;auto_increment" json:"id"`
+	WebhookId      uint32     `gorm:"not null;uniqueIndex:idx_webhook_delivery_event" json:"webhook_id"`
+	EventId        string     `gorm:"size:36;not null;uniqueIndex:idx_webhook_delivery_event" json:"event_id"`
+	EventType      string     `gorm:"size:50;not null" json:"event_type"`
+	Payload        string     `gorm:"type:json;not null" json:"-"`
+	Status         string     `gorm:"size:20;not null;index:idx_webhook_delivery_status" json:"status"`
+	Attempts       uint32     `json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `gorm:"size:500" json:"last_error,omitempty"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_webhook_delivery_status" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Webhook        Webhook    `gorm:"foreignKey:WebhookId" json:"-"`
+}
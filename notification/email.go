@@ -0,0 +1,229 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/mailer"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// EmailConsumer sends the assignment and completion emails of the task
+// events, added to broker.Consumers by the services sending emails
+var EmailConsumer = broker.Consumer{
+	Queue:   broker.Queue{Name: broker.QueueEmails, Bindings: []string{"task.created.*", "task.updated.*", "task.executed.*"}},
+	Handler: HandleEmailEvent,
+}
+
+// dateLayouts formats the dates of the emails by language, English by default
+var dateLayouts = map[string]string{
+	"pt": "02/01/2006 15:04 MST",
+}
+
+const defaultDateLayout = "Jan 2, 2006 15:04 MST"
+
+// HandleEmailEvent emails the assignee of a task created for or reassigned to
+// them by someone else, and the watchers of a completed task. Failed emails are
+// retried by the broker.
+func HandleEmailEvent(ctx context.Context, msg broker.Message) error {
+	event, err := broker.DecodeEvent(msg)
+	if err != nil {
+		return broker.Permanent(fmt.Errorf("error decoding event: %w", err))
+	}
+
+	switch event.Type {
+	case events.TaskCreated:
+		var data events.TaskCreatedData
+		if err := event.Decode(&data); err != nil {
+			return broker.Permanent(fmt.Errorf("error decoding %s: %w", event.Type, err))
+		}
+		if data.Task.UserId == data.ActorId {
+			return nil
+		}
+		return sendAssigned(ctx, data.Task)
+	case events.TaskUpdated:
+		var data events.TaskUpdatedData
+		if err := event.Decode(&data); err != nil {
+			return broker.Permanent(fmt.Errorf("error decoding %s: %w", event.Type, err))
+		}
+		if !utils.ContainsString(data.Changes, "user_id") || data.Task.UserId == data.ActorId {
+			return nil
+		}
+		return sendAssigned(ctx, data.Task)
+	case events.TaskExecuted:
+		var data events.TaskExecutedData
+		if err := event.Decode(&data); err != nil {
+			return broker.Permanent(fmt.Errorf("error decoding %s: %w", event.Type, err))
+		}
+		return sendCompleted(ctx, event.ID, data)
+	}
+
+	return nil
+}
+
+func sendAssigned(ctx context.Context, task events.Task) error {
+	user, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(task.UserId))
+	if err != nil {
+		log.Printf("skipping assignment email of task %d: %s\n", task.ID, err)
+		return nil
+	}
+
+	optedOut, err := emailOptOuts([]uint32{user.ID}, models.EmailEventAssignment)
+	if err != nil {
+		return err
+	}
+	if optedOut[user.ID] || !canEmail(user) {
+		return nil
+	}
+
+	return sendEmail(ctx, mailer.TemplateTaskAssigned, user, map[string]interface{}{
+		"Name":    user.Name,
+		"Title":   task.Title,
+		"Summary": task.Summary,
+		"DueAt":   formatDate(task.DueAt, user),
+		"Link":    taskLink(task.ID),
+	})
+}
+
+// sendCompleted emails the watchers of the task except its technician. Each
+// email sent is recorded with the event, so when some of them fail the event
+// is retried for the others only.
+func sendCompleted(ctx context.Context, eventId string, data events.TaskExecutedData) error {
+	watchers, err := repository.WatcherRepositoryServices.FindWatchers(fmt.Sprint(data.Task.ID))
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]uint32, 0, len(watchers))
+	for _, watcher := range watchers {
+		if watcher.UserId != data.TechnicianId {
+			userIds = append(userIds, watcher.UserId)
+		}
+	}
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	optedOut, err := emailOptOuts(userIds, models.EmailEventCompletion)
+	if err != nil {
+		return err
+	}
+
+	sentEmails, err := repository.EmailRepositoryServices.FindSentEmails(eventId)
+	if err != nil {
+		return err
+	}
+	sent := make(map[uint32]bool, len(sentEmails))
+	for _, sentEmail := range sentEmails {
+		sent[sentEmail.UserId] = true
+	}
+
+	technician := ""
+	if user, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(data.TechnicianId)); err == nil {
+		technician = user.Name
+	} else {
+		log.Printf("technician %d of task %d not found: %s\n", data.TechnicianId, data.Task.ID, err)
+	}
+
+	var failed error
+	for _, watcher := range watchers {
+		if watcher.UserId == data.TechnicianId || optedOut[watcher.UserId] || sent[watcher.UserId] || !canEmail(watcher.User) {
+			continue
+		}
+
+		completedAt := data.ExecutedAt
+		err := sendEmail(ctx, mailer.TemplateTaskCompleted, watcher.User, map[string]interface{}{
+			"Name":        watcher.User.Name,
+			"Title":       data.Task.Title,
+			"Technician":  technician,
+			"CompletedAt": formatDate(&completedAt, watcher.User),
+			"Link":        taskLink(data.Task.ID),
+		})
+		if err != nil {
+			log.Printf("error sending completion email of task %d to user %d: %s\n", data.Task.ID, watcher.UserId, err)
+			if failed == nil {
+				failed = err
+			}
+			continue
+		}
+
+		if err := repository.EmailRepositoryServices.SaveSentEmail(models.SentEmail{EventId: eventId, UserId: watcher.UserId, SentAt: time.Now()}); err != nil {
+			return err
+		}
+	}
+
+	return failed
+}
+
+// sendEmail renders the template in the locale of the user and sends it
+func sendEmail(ctx context.Context, template string, user models.User, data map[string]interface{}) error {
+	message, err := mailer.RenderLocalized(template, user.Locale, user.Email, data)
+	if err != nil {
+		return broker.Permanent(err)
+	}
+
+	return mailer.MailerServices.Send(ctx, message)
+}
+
+// emailOptOuts returns the users that disabled the email in their preferences
+func emailOptOuts(userIds []uint32, emailEvent string) (map[uint32]bool, error) {
+	preferences, err := repository.NotificationRepositoryServices.FindPreferences(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	optedOut := make(map[uint32]bool)
+	for _, preference := range preferences {
+		if preference.Event == emailEvent && !preference.Enabled {
+			optedOut[preference.UserId] = true
+		}
+	}
+
+	return optedOut, nil
+}
+
+func canEmail(user models.User) bool {
+	return user.ID != 0 && user.Active && user.Email != ""
+}
+
+// userLocation is the timezone of the user, UTC when unset
+func userLocation(user models.User) *time.Location {
+	if user.Timezone != "" {
+		if location, err := time.LoadLocation(user.Timezone); err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
+// formatDate formats a date in the timezone and language of the user, empty when nil
+func formatDate(date *time.Time, user models.User) string {
+	if date == nil {
+		return ""
+	}
+
+	language, _, _ := strings.Cut(user.Locale, "-")
+	layout, ok := dateLayouts[language]
+	if !ok {
+		layout = defaultDateLayout
+	}
+
+	return date.In(userLocation(user)).Format(layout)
+}
+
+// taskLink is the address of the task in the front-end, APP_URL
+func taskLink(taskId uint32) string {
+	appURL := "http://localhost:8080"
+	if value := os.Getenv("APP_URL"); value != "" {
+		appURL = strings.TrimRight(value, "/")
+	}
+	return fmt.Sprintf("%s/task/%d", appURL, taskId)
+}
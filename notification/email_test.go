@@ -0,0 +1,199 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/mailer"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/notification"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func emailMessage(eventType string, data interface{}) broker.Message {
+	event, _ := events.New(eventType, events.TaskSubject(1), "corr-1", data)
+	body, _ := json.Marshal(event)
+
+	return broker.Message{Queue: broker.QueueEmails, Body: body}
+}
+
+func mockMailer() *taskMock.IMailer {
+	iMailerMock := new(taskMock.IMailer)
+	iMailerMock.On("Send", tmock.Anything, tmock.Anything).Return(nil)
+	mailer.MailerServices = iMailerMock
+
+	return iMailerMock
+}
+
+func mockSentEmails(sent []models.SentEmail) *taskMock.IEmailRepository {
+	iEmailMock := new(taskMock.IEmailRepository)
+	iEmailMock.On("FindSentEmails", tmock.Anything).Return(sent, nil)
+	iEmailMock.On("SaveSentEmail", tmock.Anything).Return(nil)
+	repository.EmailRepositoryServices = iEmailMock
+
+	return iEmailMock
+}
+
+func TestHandleEmailEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	dueAt := time.Date(2026, 10, 20, 15, 0, 0, 0, time.UTC)
+	task := events.Task{ID: 1, Title: "Fix the pump", Summary: "The pump leaks", UserId: 2, DueAt: &dueAt}
+	assignee := models.User{ID: 2, Name: "Tech", Email: "tech@gtasks.com", Active: true, Locale: "pt-BR", Timezone: "America/Sao_Paulo"}
+
+	t.Run("Success: assignee emailed in their language and timezone", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(assignee, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", []uint32{2}).Return([]models.NotificationPreference{}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		iMailerMock := mockMailer()
+
+		err := notification.HandleEmailEvent(context.Background(), emailMessage(events.TaskCreated, events.TaskCreatedData{Task: task, ActorId: 5}))
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertCalled(t, "Send", tmock.Anything, tmock.MatchedBy(func(message mailer.Message) bool {
+			return message.To == "tech@gtasks.com" &&
+				message.Subject == "Nova tarefa atribuída a você: Fix the pump" &&
+				strings.Contains(message.Text, "O prazo é 20/10/2026 12:00")
+		}))
+	})
+
+	t.Run("Success: reassignment emails the new assignee", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(assignee, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", []uint32{2}).Return([]models.NotificationPreference{}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		iMailerMock := mockMailer()
+
+		err := notification.HandleEmailEvent(context.Background(), emailMessage(events.TaskUpdated, events.TaskUpdatedData{Task: task, Changes: []string{"title"}, ActorId: 5}))
+		assert.Nil(err)
+		iMailerMock.AssertNotCalled(t, "Send", tmock.Anything, tmock.Anything)
+
+		err = notification.HandleEmailEvent(context.Background(), emailMessage(events.TaskUpdated, events.TaskUpdatedData{Task: task, Changes: []string{"user_id"}, ActorId: 5}))
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertNumberOfCalls(t, "Send", 1)
+	})
+
+	t.Run("Success: no email for a task the user assigned to themselves", func(t *testing.T) {
+		iMailerMock := mockMailer()
+
+		err := notification.HandleEmailEvent(context.Background(), emailMessage(events.TaskCreated, events.TaskCreatedData{Task: task, ActorId: 2}))
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertNotCalled(t, "Send", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: assignee opted out of the email", func(t *testing.T) {
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(assignee, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", []uint32{2}).Return([]models.NotificationPreference{
+			{UserId: 2, Event: models.EmailEventAssignment, Enabled: false},
+		}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		iMailerMock := mockMailer()
+
+		err := notification.HandleEmailEvent(context.Background(), emailMessage(events.TaskCreated, events.TaskCreatedData{Task: task, ActorId: 5}))
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertNotCalled(t, "Send", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: completion emailed to the watchers except the technician", func(t *testing.T) {
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", "1").Return([]models.TaskWatcher{
+			{TaskId: 1, UserId: 2, User: assignee},
+			{TaskId: 1, UserId: 3, User: models.User{ID: 3, Name: "Manager", Email: "manager@gtasks.com", Active: true}},
+			{TaskId: 1, UserId: 4, User: models.User{ID: 4, Name: "Other", Email: "other@gtasks.com", Active: true}},
+		}, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", []uint32{3, 4}).Return([]models.NotificationPreference{
+			{UserId: 4, Event: models.EmailEventCompletion, Enabled: false},
+		}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(assignee, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iEmailMock := mockSentEmails(nil)
+		iMailerMock := mockMailer()
+
+		err := notification.HandleEmailEvent(context.Background(), emailMessage(events.TaskExecuted, events.TaskExecutedData{Task: task, TechnicianId: 2, ExecutedAt: dueAt}))
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertNumberOfCalls(t, "Send", 1)
+		iMailerMock.AssertCalled(t, "Send", tmock.Anything, tmock.MatchedBy(func(message mailer.Message) bool {
+			return message.To == "manager@gtasks.com" && strings.Contains(message.Text, "completed by Tech on Oct 20, 2026 15:00 UTC")
+		}))
+		iEmailMock.AssertCalled(t, "SaveSentEmail", tmock.MatchedBy(func(sent models.SentEmail) bool { return sent.UserId == 3 }))
+	})
+
+	t.Run("Failed: completion retried only for the watchers not emailed", func(t *testing.T) {
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", "1").Return([]models.TaskWatcher{
+			{TaskId: 1, UserId: 3, User: models.User{ID: 3, Name: "Manager", Email: "manager@gtasks.com", Active: true}},
+			{TaskId: 1, UserId: 4, User: models.User{ID: 4, Name: "Other", Email: "other@gtasks.com", Active: true}},
+			{TaskId: 1, UserId: 5, User: models.User{ID: 5, Name: "Sent", Email: "sent@gtasks.com", Active: true}},
+		}, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", []uint32{3, 4, 5}).Return([]models.NotificationPreference{}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(assignee, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		iEmailMock := mockSentEmails([]models.SentEmail{{UserId: 5}})
+
+		iMailerMock := new(taskMock.IMailer)
+		iMailerMock.On("Send", tmock.Anything, tmock.MatchedBy(func(message mailer.Message) bool { return message.To == "manager@gtasks.com" })).Return(errors.New("mailbox unavailable"))
+		iMailerMock.On("Send", tmock.Anything, tmock.Anything).Return(nil)
+		mailer.MailerServices = iMailerMock
+
+		err := notification.HandleEmailEvent(context.Background(), emailMessage(events.TaskExecuted, events.TaskExecutedData{Task: task, TechnicianId: 2, ExecutedAt: dueAt}))
+
+		// asserts
+		assert.NotNil(err)
+		iMailerMock.AssertNumberOfCalls(t, "Send", 2)
+		iEmailMock.AssertNumberOfCalls(t, "SaveSentEmail", 1)
+		iEmailMock.AssertCalled(t, "SaveSentEmail", tmock.MatchedBy(func(sent models.SentEmail) bool { return sent.UserId == 4 }))
+	})
+
+	t.Run("Failed: undecodable event is permanent", func(t *testing.T) {
+		err := notification.HandleEmailEvent(context.Background(), broker.Message{Queue: broker.QueueEmails, Body: []byte("{")})
+
+		// asserts
+		assert.True(broker.IsPermanent(err))
+	})
+}
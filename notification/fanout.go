@@ -0,0 +1,152 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// FanOut builds one notification per watcher of the task. The actor of the
+// event and watchers that disabled the event in their preferences are skipped.
+func FanOut(event string, task models.Task, actorId uint32, message string) ([]models.Notification, error) {
+	watchers, err := repository.WatcherRepositoryServices.FindWatchers(fmt.Sprint(task.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	userIds := make([]uint32, 0, len(watchers))
+	for _, watcher := range watchers {
+		if watcher.UserId != actorId {
+			userIds = append(userIds, watcher.UserId)
+		}
+	}
+
+	preferences, err := repository.NotificationRepositoryServices.FindPreferences(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := make(map[uint32]bool)
+	for _, preference := range preferences {
+		if preference.Event == event && !preference.Enabled {
+			disabled[preference.UserId] = true
+		}
+	}
+
+	now := time.Now()
+	notifications := make([]models.Notification, 0, len(userIds))
+	for _, userId := range userIds {
+		if disabled[userId] {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserId:    userId,
+			TaskId:    task.ID,
+			Event:     event,
+			ActorId:   actorId,
+			Message:   message,
+			CreatedAt: now,
+		})
+	}
+
+	return notifications, nil
+}
+
+// Dispatch fans the event out and publishes every notification with the broker of the service
+func Dispatch(ctx context.Context, event string, task models.Task, actorId uint32, message string) error {
+	notifications, err := FanOut(event, task, actorId, message)
+	if err != nil {
+		return fmt.Errorf("error fanning out %s notifications for task %d: %w", event, task.ID, err)
+	}
+
+	for _, n := range notifications {
+		if err := broker.PublishNotification(ctx, n); err != nil {
+			return fmt.Errorf("error publishing %s notification for task %d: %w", event, task.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// WatcherConsumer notifies the watchers of the reassigned, reviewed and
+// completed tasks, added to broker.Consumers by the services owning the tasks.
+// The notifications follow the events of the outbox, so they are only sent for
+// committed changes.
+var WatcherConsumer = broker.Consumer{
+	Queue:   broker.Queue{Name: broker.QueueWatchers, Bindings: []string{"task.updated.*", "task.executed.*"}},
+	Handler: HandleWatcherEvent,
+}
+
+// HandleWatcherEvent dispatches the watcher notifications of a task event.
+// Failed notifications are retried by the broker.
+func HandleWatcherEvent(ctx context.Context, msg broker.Message) error {
+	event, err := broker.DecodeEvent(msg)
+	if err != nil {
+		return broker.Permanent(fmt.Errorf("error decoding event: %w", err))
+	}
+
+	switch event.Type {
+	case events.TaskUpdated:
+		var data events.TaskUpdatedData
+		if err := event.Decode(&data); err != nil {
+			return broker.Permanent(fmt.Errorf("error decoding %s: %w", event.Type, err))
+		}
+		return notifyUpdated(ctx, data)
+	case events.TaskExecuted:
+		var data events.TaskExecutedData
+		if err := event.Decode(&data); err != nil {
+			return broker.Permanent(fmt.Errorf("error decoding %s: %w", event.Type, err))
+		}
+		actorId := data.TechnicianId
+		if data.Review != nil {
+			actorId = data.Review.ReviewerId
+		}
+		msg := "The tech " + technicianName(data.Task) + " performed the task " + data.Task.Title + " on date " + data.ExecutedAt.Format("2006-01-02 15:04:05")
+		return Dispatch(ctx, models.TaskEventCompletion, models.Task{ID: data.Task.ID}, actorId, msg)
+	}
+
+	return nil
+}
+
+// notifyUpdated notifies the reassignment and the status change of a task
+func notifyUpdated(ctx context.Context, data events.TaskUpdatedData) error {
+	task := models.Task{ID: data.Task.ID}
+
+	if utils.ContainsString(data.Changes, "user_id") {
+		msg := "The task " + data.Task.Title + " was reassigned"
+		if err := Dispatch(ctx, models.TaskEventReassignment, task, data.ActorId, msg); err != nil {
+			return err
+		}
+	}
+
+	if !utils.ContainsString(data.Changes, "done") && !utils.ContainsString(data.Changes, "status") {
+		return nil
+	}
+
+	msg := "The task " + data.Task.Title + " changed its status"
+	switch {
+	case data.Task.Status == models.TaskStatusPendingReview:
+		msg = "The tech " + technicianName(data.Task) + " performed the task " + data.Task.Title + ", waiting for review"
+	case data.Review != nil && !data.Review.Approved:
+		msg = "The task " + data.Task.Title + " performed by the tech " + technicianName(data.Task) + " was rejected: " + data.Review.Comment
+	}
+
+	return Dispatch(ctx, models.TaskEventStatusChange, task, data.ActorId, msg)
+}
+
+// technicianName is the name of the assignee of the task, empty when not found
+func technicianName(task events.Task) string {
+	user, err := repository.UserRepositoryServices.FindUserById(fmt.Sprint(task.UserId))
+	if err != nil {
+		log.Printf("technician %d of task %d not found: %s\n", task.UserId, task.ID, err)
+		return ""
+	}
+	return user.Name
+}
@@ -0,0 +1,192 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/notification"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func TestFanOut(t *testing.T) {
+	assert := assert.New(t)
+
+	taskModel := models.Task{
+		ID:      1,
+		Title:   "Test Title",
+		Summary: "Test Summary",
+		UserId:  1,
+	}
+
+	watchers := []models.TaskWatcher{
+		{TaskId: 1, UserId: 1},
+		{TaskId: 1, UserId: 2},
+		{TaskId: 1, UserId: 3},
+	}
+
+	t.Run("Success: one notification per watcher except the actor", func(t *testing.T) {
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", "1").Return(watchers, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", []uint32{2, 3}).Return([]models.NotificationPreference{}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		notifications, err := notification.FanOut(models.TaskEventCompletion, taskModel, 1, "done")
+
+		// asserts
+		assert.Nil(err)
+		assert.Len(notifications, 2)
+		assert.Equal(uint32(2), notifications[0].UserId)
+		assert.Equal(uint32(3), notifications[1].UserId)
+		assert.Equal(models.TaskEventCompletion, notifications[0].Event)
+	})
+
+	t.Run("Success: watchers that disabled the event are skipped", func(t *testing.T) {
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", "1").Return(watchers, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", tmock.Anything).Return([]models.NotificationPreference{
+			{UserId: 2, Event: models.TaskEventCompletion, Enabled: false},
+			{UserId: 3, Event: models.TaskEventReassignment, Enabled: false},
+		}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		notifications, err := notification.FanOut(models.TaskEventCompletion, taskModel, 1, "done")
+
+		// asserts
+		assert.Nil(err)
+		assert.Len(notifications, 1)
+		assert.Equal(uint32(3), notifications[0].UserId)
+	})
+}
+
+func TestDispatch(t *testing.T) {
+	assert := assert.New(t)
+
+	taskModel := models.Task{ID: 1, Title: "Test Title", UserId: 1}
+
+	iWatcherMock := new(taskMock.IWatcherRepository)
+	iWatcherMock.On("FindWatchers", "1").Return([]models.TaskWatcher{{TaskId: 1, UserId: 1}, {TaskId: 1, UserId: 2}}, nil)
+	repository.WatcherRepositoryServices = iWatcherMock
+
+	iNotificationMock := new(taskMock.INotificationRepository)
+	iNotificationMock.On("FindPreferences", []uint32{2}).Return([]models.NotificationPreference{}, nil)
+	repository.NotificationRepositoryServices = iNotificationMock
+
+	memory := broker.NewMemory()
+	broker.BrokerServices = memory
+
+	notification.Dispatch(context.Background(), models.TaskEventCompletion, taskModel, 1, "done")
+
+	published := memory.Published(broker.QueueNotifications)
+	var n models.Notification
+	json.Unmarshal(published[0].Body, &n)
+
+	// asserts
+	assert.Len(published, 1)
+	assert.Equal(uint32(2), n.UserId)
+	assert.Equal("done", n.Message)
+}
+
+func TestHandleWatcherEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	task := events.Task{ID: 1, Title: "Fix the pump", UserId: 2, Status: models.TaskStatusRejected}
+
+	mockWatchers := func() *broker.Memory {
+		iWatcherMock := new(taskMock.IWatcherRepository)
+		iWatcherMock.On("FindWatchers", "1").Return([]models.TaskWatcher{{TaskId: 1, UserId: 2}, {TaskId: 1, UserId: 3}}, nil)
+		repository.WatcherRepositoryServices = iWatcherMock
+
+		iNotificationMock := new(taskMock.INotificationRepository)
+		iNotificationMock.On("FindPreferences", tmock.Anything).Return([]models.NotificationPreference{}, nil)
+		repository.NotificationRepositoryServices = iNotificationMock
+
+		iUserMock := new(taskMock.IUserRepository)
+		iUserMock.On("FindUserById", "2").Return(models.User{ID: 2, Name: "Tech"}, nil)
+		repository.UserRepositoryServices = iUserMock
+
+		memory := broker.NewMemory()
+		broker.BrokerServices = memory
+
+		return memory
+	}
+
+	published := func(memory *broker.Memory) []models.Notification {
+		var notifications []models.Notification
+		for _, msg := range memory.Published(broker.QueueNotifications) {
+			var n models.Notification
+			json.Unmarshal(msg.Body, &n)
+			notifications = append(notifications, n)
+		}
+		return notifications
+	}
+
+	t.Run("Success: rejection and reassignment notify the watchers but the actor", func(t *testing.T) {
+		memory := mockWatchers()
+
+		data := events.TaskUpdatedData{
+			Task:    task,
+			Changes: []string{"user_id", "status"},
+			ActorId: 3,
+			Review:  &events.Review{ReviewerId: 3, Approved: false, Comment: "Missing photos"},
+		}
+		event, _ := events.New(events.TaskUpdated, events.TaskSubject(1), "corr-1", data)
+		body, _ := json.Marshal(event)
+
+		err := notification.HandleWatcherEvent(context.Background(), broker.Message{Queue: broker.QueueWatchers, Body: body})
+		notifications := published(memory)
+
+		// asserts
+		assert.Nil(err)
+		assert.Len(notifications, 2)
+		assert.Equal(models.TaskEventReassignment, notifications[0].Event)
+		assert.Equal(uint32(2), notifications[0].UserId)
+		assert.Equal(models.TaskEventStatusChange, notifications[1].Event)
+		assert.Equal("The task Fix the pump performed by the tech Tech was rejected: Missing photos", notifications[1].Message)
+	})
+
+	t.Run("Success: completion notifies the watchers but the technician", func(t *testing.T) {
+		memory := mockWatchers()
+
+		data := events.TaskExecutedData{Task: task, TechnicianId: 2, ExecutedAt: time.Date(2026, 10, 20, 15, 0, 0, 0, time.UTC)}
+		event, _ := events.New(events.TaskExecuted, events.TaskSubject(1), "corr-1", data)
+		body, _ := json.Marshal(event)
+
+		err := notification.HandleWatcherEvent(context.Background(), broker.Message{Queue: broker.QueueWatchers, Body: body})
+		notifications := published(memory)
+
+		// asserts
+		assert.Nil(err)
+		assert.Len(notifications, 1)
+		assert.Equal(uint32(3), notifications[0].UserId)
+		assert.Equal(models.TaskEventCompletion, notifications[0].Event)
+		assert.Equal("The tech Tech performed the task Fix the pump on date 2026-10-20 15:00:00", notifications[0].Message)
+	})
+
+	t.Run("Success: other changes notify nobody", func(t *testing.T) {
+		memory := mockWatchers()
+
+		data := events.TaskUpdatedData{Task: task, Changes: []string{"title"}, ActorId: 3}
+		event, _ := events.New(events.TaskUpdated, events.TaskSubject(1), "corr-1", data)
+		body, _ := json.Marshal(event)
+
+		err := notification.HandleWatcherEvent(context.Background(), broker.Message{Queue: broker.QueueWatchers, Body: body})
+
+		// asserts
+		assert.Nil(err)
+		assert.Empty(published(memory))
+	})
+}
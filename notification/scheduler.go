@@ -0,0 +1,222 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hugohenrick/gtasks/mailer"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+)
+
+// sentEmailRetention is how long the emails sent for an event are recorded,
+// longer than the retries of the event
+const sentEmailRetention = 7 * 24 * time.Hour
+
+// Scheduler sends the emails that are not triggered by an event: the overdue
+// tasks and the daily digest of the open tasks of every user
+type Scheduler struct {
+	Interval   time.Duration
+	BatchSize  int
+	DigestHour int
+	Now        func() time.Time
+}
+
+// NewScheduler returns a scheduler configured by EMAIL_SCHEDULER_INTERVAL_SECONDS,
+// EMAIL_OVERDUE_BATCH_SIZE and EMAIL_DIGEST_HOUR
+func NewScheduler() *Scheduler {
+	digestHour, err := strconv.Atoi(os.Getenv("EMAIL_DIGEST_HOUR"))
+	if err != nil || digestHour < 0 || digestHour > 23 {
+		digestHour = 8
+	}
+
+	return &Scheduler{
+		Interval:   time.Duration(envInt("EMAIL_SCHEDULER_INTERVAL_SECONDS", 60)) * time.Second,
+		BatchSize:  envInt("EMAIL_OVERDUE_BATCH_SIZE", 100),
+		DigestHour: digestHour,
+		Now:        time.Now,
+	}
+}
+
+// StartScheduler runs the scheduler until the context is done
+func StartScheduler(ctx context.Context) {
+	scheduler := NewScheduler()
+
+	go scheduler.Run(ctx)
+}
+
+// Run sends the scheduled emails every interval until the context is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RunOnce(ctx); err != nil {
+				log.Printf("error sending scheduled emails: %s\n", err)
+			}
+		}
+	}
+}
+
+// RunOnce sends the overdue and digest emails that are due holding the
+// scheduler lock, so a single instance sends them
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	_, err := repository.EmailRepositoryServices.WithEmailLock(func() error {
+		now := s.Now()
+		if err := s.sendOverdue(ctx, now); err != nil {
+			return err
+		}
+		if err := s.sendDigests(ctx, now); err != nil {
+			return err
+		}
+
+		_, err := repository.EmailRepositoryServices.DeleteSentEmails(now.Add(-sentEmailRetention))
+		return err
+	})
+
+	return err
+}
+
+// sendOverdue emails the assignee of every task that became overdue once. A
+// failed email is retried on the next run.
+func (s *Scheduler) sendOverdue(ctx context.Context, now time.Time) error {
+	tasks, err := repository.EmailRepositoryServices.FindOverdueTasks(now, s.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]uint32, 0, len(tasks))
+	for _, task := range tasks {
+		userIds = append(userIds, task.UserId)
+	}
+	optedOut, err := emailOptOuts(userIds, models.EmailEventOverdue)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if !optedOut[task.UserId] && canEmail(task.User) {
+			err := sendEmail(ctx, mailer.TemplateTaskOverdue, task.User, map[string]interface{}{
+				"Name":  task.User.Name,
+				"Title": task.Title,
+				"DueAt": formatDate(task.DueAt, task.User),
+				"Link":  taskLink(task.ID),
+			})
+			if err != nil {
+				log.Printf("error sending overdue email of task %d: %s\n", task.ID, err)
+				continue
+			}
+		}
+
+		if err := repository.EmailRepositoryServices.MarkTaskOverdueNotified(task.ID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// digestTask is a task listed in the daily digest
+type digestTask struct {
+	Title   string
+	DueAt   string
+	Overdue bool
+	Link    string
+}
+
+// sendDigests emails the digest of the open tasks to the users whose local
+// time passed the digest hour and who didn't get it today. The digest is
+// recorded even when no task is open anymore, so the user is not checked again
+// until tomorrow.
+func (s *Scheduler) sendDigests(ctx context.Context, now time.Time) error {
+	users, err := repository.EmailRepositoryServices.FindDigestUsers()
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]uint32, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, user.ID)
+	}
+
+	digests, err := repository.EmailRepositoryServices.FindEmailDigests(userIds)
+	if err != nil {
+		return err
+	}
+	sentOn := make(map[uint32]string, len(digests))
+	for _, digest := range digests {
+		sentOn[digest.UserId] = digest.SentOn
+	}
+
+	optedOut, err := emailOptOuts(userIds, models.EmailEventDigest)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		local := now.In(userLocation(user))
+		today := local.Format("2006-01-02")
+		if local.Hour() < s.DigestHour || sentOn[user.ID] == today || optedOut[user.ID] || !canEmail(user) {
+			continue
+		}
+
+		if err := s.sendDigest(ctx, user, now, today); err != nil {
+			log.Printf("error sending digest email to user %d: %s\n", user.ID, err)
+			continue
+		}
+
+		if err := repository.EmailRepositoryServices.SaveEmailDigest(models.EmailDigest{UserId: user.ID, SentOn: today, SentAt: now}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Scheduler) sendDigest(ctx context.Context, user models.User, now time.Time, today string) error {
+	tasks, err := repository.EmailRepositoryServices.FindOpenTasks(user.ID)
+	if err != nil {
+		return err
+	}
+	// the tasks were done since FindDigestUsers, there is nothing to send
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	overdue := 0
+	listed := make([]digestTask, 0, len(tasks))
+	for _, task := range tasks {
+		late := task.DueAt != nil && task.DueAt.Before(now)
+		if late {
+			overdue++
+		}
+		listed = append(listed, digestTask{
+			Title:   task.Title,
+			DueAt:   formatDate(task.DueAt, user),
+			Overdue: late,
+			Link:    taskLink(task.ID),
+		})
+	}
+
+	return sendEmail(ctx, mailer.TemplateDailyDigest, user, map[string]interface{}{
+		"Name":    user.Name,
+		"Date":    today,
+		"Tasks":   listed,
+		"Overdue": overdue,
+	})
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,125 @@
+package notification_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/mailer"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/notification"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func mockEmailRepository(overdue []models.Task, users []models.User, digests []models.EmailDigest, open []models.Task) *taskMock.IEmailRepository {
+	iEmailMock := new(taskMock.IEmailRepository)
+	iEmailMock.On("WithEmailLock", tmock.Anything).Return(func(fn func() error) bool {
+		return true
+	}, func(fn func() error) error {
+		return fn()
+	})
+	iEmailMock.On("FindOverdueTasks", tmock.Anything, 10).Return(overdue, nil)
+	iEmailMock.On("MarkTaskOverdueNotified", tmock.Anything, tmock.Anything).Return(nil)
+	iEmailMock.On("FindDigestUsers").Return(users, nil)
+	iEmailMock.On("FindEmailDigests", tmock.Anything).Return(digests, nil)
+	iEmailMock.On("FindOpenTasks", tmock.Anything).Return(open, nil)
+	iEmailMock.On("SaveEmailDigest", tmock.Anything).Return(nil)
+	iEmailMock.On("DeleteSentEmails", tmock.Anything).Return(int64(0), nil)
+	repository.EmailRepositoryServices = iEmailMock
+
+	return iEmailMock
+}
+
+func TestSchedulerRunOnce(t *testing.T) {
+	assert := assert.New(t)
+
+	// 11:30 in Sao Paulo, 04:30 in Honolulu
+	now := time.Date(2026, 10, 19, 14, 30, 0, 0, time.UTC)
+	dueAt := now.Add(-2 * time.Hour)
+	tech := models.User{ID: 2, Name: "Tech", Email: "tech@gtasks.com", Active: true, Timezone: "America/Sao_Paulo"}
+	early := models.User{ID: 3, Name: "Early", Email: "early@gtasks.com", Active: true, Timezone: "Pacific/Honolulu"}
+
+	iNotificationMock := new(taskMock.INotificationRepository)
+	iNotificationMock.On("FindPreferences", tmock.Anything).Return([]models.NotificationPreference{}, nil)
+	repository.NotificationRepositoryServices = iNotificationMock
+
+	scheduler := &notification.Scheduler{BatchSize: 10, DigestHour: 8, Now: func() time.Time { return now }}
+
+	t.Run("Success: overdue task emailed once", func(t *testing.T) {
+		iEmailMock := mockEmailRepository([]models.Task{{ID: 7, Title: "Fix the pump", UserId: 2, User: tech, DueAt: &dueAt}}, nil, nil, nil)
+		iMailerMock := mockMailer()
+
+		err := scheduler.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertCalled(t, "Send", tmock.Anything, tmock.MatchedBy(func(message mailer.Message) bool {
+			return message.To == "tech@gtasks.com" && message.Subject == "Task overdue: Fix the pump"
+		}))
+		iEmailMock.AssertCalled(t, "MarkTaskOverdueNotified", uint32(7), now)
+	})
+
+	t.Run("Failed: overdue email retried on the next run", func(t *testing.T) {
+		iEmailMock := mockEmailRepository([]models.Task{{ID: 7, Title: "Fix the pump", UserId: 2, User: tech, DueAt: &dueAt}}, nil, nil, nil)
+		iMailerMock := new(taskMock.IMailer)
+		iMailerMock.On("Send", tmock.Anything, tmock.Anything).Return(errors.New("smtp is down"))
+		mailer.MailerServices = iMailerMock
+
+		err := scheduler.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		iEmailMock.AssertNotCalled(t, "MarkTaskOverdueNotified", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: digest sent after the digest hour in the timezone of the user", func(t *testing.T) {
+		iEmailMock := mockEmailRepository(nil, []models.User{tech, early}, nil, []models.Task{
+			{ID: 7, Title: "Fix the pump", UserId: 2, DueAt: &dueAt},
+			{ID: 8, Title: "Paint the wall", UserId: 2},
+		})
+		iMailerMock := mockMailer()
+
+		err := scheduler.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertNumberOfCalls(t, "Send", 1)
+		iMailerMock.AssertCalled(t, "Send", tmock.Anything, tmock.MatchedBy(func(message mailer.Message) bool {
+			return message.To == "tech@gtasks.com" &&
+				message.Subject == "Your gtasks summary for 2026-10-19" &&
+				strings.Contains(message.Text, "You have 2 open task(s), 1 of them overdue") &&
+				strings.Contains(message.Text, "- Fix the pump (due Oct 19, 2026 09:30 -03, overdue)")
+		}))
+		iEmailMock.AssertCalled(t, "SaveEmailDigest", models.EmailDigest{UserId: 2, SentOn: "2026-10-19", SentAt: now})
+		iEmailMock.AssertNumberOfCalls(t, "SaveEmailDigest", 1)
+	})
+
+	t.Run("Success: digest recorded without email when no task is open", func(t *testing.T) {
+		iEmailMock := mockEmailRepository(nil, []models.User{tech}, nil, []models.Task{})
+		iMailerMock := mockMailer()
+
+		err := scheduler.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertNotCalled(t, "Send", tmock.Anything, tmock.Anything)
+		iEmailMock.AssertCalled(t, "SaveEmailDigest", models.EmailDigest{UserId: 2, SentOn: "2026-10-19", SentAt: now})
+	})
+
+	t.Run("Success: digest sent once a day", func(t *testing.T) {
+		iEmailMock := mockEmailRepository(nil, []models.User{tech}, []models.EmailDigest{{UserId: 2, SentOn: "2026-10-19"}}, nil)
+		iMailerMock := mockMailer()
+
+		err := scheduler.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		iMailerMock.AssertNotCalled(t, "Send", tmock.Anything, tmock.Anything)
+		iEmailMock.AssertNotCalled(t, "SaveEmailDigest", tmock.Anything)
+	})
+}
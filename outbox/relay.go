@@ -0,0 +1,200 @@
+// Package outbox publishes the events saved in the outbox table to the
+// message broker. The events are saved with the transaction of the change
+// they describe, so an outage of the broker delays them instead of losing them.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+)
+
+// maxBackoff caps the wait between the attempts of a message
+const maxBackoff = 5 * time.Minute
+
+// Relay polls the outbox and publishes the pending messages in order
+type Relay struct {
+	Interval        time.Duration
+	BatchSize       int
+	MaxAttempts     uint32 // attempts rejected by the broker before a message is abandoned, unlimited when 0
+	Retention       time.Duration
+	CleanupInterval time.Duration
+	Publish         func(ctx context.Context, event events.Event) error
+
+	lastCleanup time.Time
+}
+
+// NewRelay returns a relay publishing with the broker of the service, configured by
+// OUTBOX_POLL_INTERVAL_MS, OUTBOX_BATCH_SIZE, OUTBOX_MAX_ATTEMPTS and OUTBOX_RETENTION_HOURS
+func NewRelay() *Relay {
+	return &Relay{
+		Interval:        time.Duration(envInt("OUTBOX_POLL_INTERVAL_MS", 1000, 1)) * time.Millisecond,
+		BatchSize:       envInt("OUTBOX_BATCH_SIZE", 100, 1),
+		MaxAttempts:     uint32(envInt("OUTBOX_MAX_ATTEMPTS", 20, 0)),
+		Retention:       time.Duration(envInt("OUTBOX_RETENTION_HOURS", 72, 0)) * time.Hour,
+		CleanupInterval: time.Hour,
+		Publish:         broker.PublishEvent,
+	}
+}
+
+// Start runs the relay until the context is done
+func Start(ctx context.Context) {
+	relay := NewRelay()
+
+	go relay.Run(ctx)
+}
+
+// Run polls the outbox every interval until the context is done
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.RunOnce(ctx); err != nil {
+				log.Printf("error relaying outbox: %s\n", err)
+			}
+		}
+	}
+}
+
+// RunOnce publishes a batch of pending messages holding the relay lock, so a
+// single instance publishes at a time, and deletes the old sent messages. It
+// returns the number of messages sent.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	var sent int
+
+	_, err := repository.OutboxRepositoryServices.WithRelayLock(func() error {
+		var err error
+		sent, err = r.publishPending(ctx)
+		if err != nil {
+			return err
+		}
+
+		r.cleanup()
+		return nil
+	})
+
+	return sent, err
+}
+
+func (r *Relay) publishPending(ctx context.Context) (int, error) {
+	now := time.Now()
+	messages, err := repository.OutboxRepositoryServices.FindPendingOutbox(now, r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	// aggregates with a failed message in this batch, their later messages
+	// wait so the events of an aggregate are published in order
+	blocked := make(map[string]bool)
+	for _, message := range messages {
+		aggregate := fmt.Sprintf("%s/%d", message.AggregateType, message.AggregateId)
+		if blocked[aggregate] {
+			continue
+		}
+
+		if message.NextAttemptAt.After(now) {
+			blocked[aggregate] = true
+			continue
+		}
+
+		if err := r.publish(ctx, message); err != nil {
+			// a message refused for good, e.g. unroutable, must not hold back its aggregate
+			// forever, while an outage of the broker is retried until it is over
+			rejected := isRejection(err)
+			if rejected && r.MaxAttempts > 0 && message.Rejections+1 >= r.MaxAttempts {
+				if err := repository.OutboxRepositoryServices.MarkOutboxAbandoned(message.ID, err.Error()); err != nil {
+					return sent, err
+				}
+				log.Printf("abandoned outbox message %d (%s) after %d rejections: %s\n", message.ID, message.EventType, message.Rejections+1, err)
+				continue
+			}
+
+			blocked[aggregate] = true
+			next := now.Add(backoff(message.Attempts + 1))
+			if err := repository.OutboxRepositoryServices.MarkOutboxFailed(message.ID, err.Error(), next, rejected); err != nil {
+				return sent, err
+			}
+			log.Printf("error publishing outbox message %d (%s), attempt %d: %s\n", message.ID, message.EventType, message.Attempts+1, err)
+			continue
+		}
+
+		if err := repository.OutboxRepositoryServices.MarkOutboxSent(message.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (r *Relay) publish(ctx context.Context, message models.OutboxMessage) error {
+	var event events.Event
+	if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+
+	return r.Publish(ctx, event)
+}
+
+// errInvalidPayload is returned for a message whose payload is not an event
+var errInvalidPayload = errors.New("invalid outbox payload")
+
+// isRejection reports whether a publish failed because of the message itself,
+// which retrying will not fix, rather than because the broker is unreachable
+func isRejection(err error) bool {
+	return errors.Is(err, broker.ErrUnroutable) || errors.Is(err, broker.ErrNacked) || errors.Is(err, errInvalidPayload)
+}
+
+func (r *Relay) cleanup() {
+	if r.Retention <= 0 || time.Since(r.lastCleanup) < r.CleanupInterval {
+		return
+	}
+	r.lastCleanup = time.Now()
+
+	deleted, err := repository.OutboxRepositoryServices.DeleteSentOutbox(time.Now().Add(-r.Retention))
+	if err != nil {
+		log.Printf("error cleaning up outbox: %s\n", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("deleted %d sent outbox messages\n", deleted)
+	}
+}
+
+// backoff returns the wait before the given attempt: 1s, 2s, 4s... up to maxBackoff
+func backoff(attempt uint32) time.Duration {
+	if attempt > 9 {
+		return maxBackoff
+	}
+
+	wait := time.Second << (attempt - 1)
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+// envInt reads a number from the environment, the fallback when it is unset or below min
+func envInt(name string, fallback int, min int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < min {
+		return fallback
+	}
+	return value
+}
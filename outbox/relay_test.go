@@ -0,0 +1,236 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/outbox"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func outboxMessage(id uint64, taskId uint32, eventType string) models.OutboxMessage {
+	event, _ := events.New(eventType, events.TaskSubject(taskId), "corr-1", events.TaskDeletedData{TaskId: taskId})
+	payload, _ := json.Marshal(event)
+
+	return models.OutboxMessage{
+		ID:            id,
+		EventId:       event.ID,
+		EventType:     eventType,
+		AggregateType: "task",
+		AggregateId:   taskId,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+}
+
+func mockOutbox(messages []models.OutboxMessage) *taskMock.IOutboxRepository {
+	iOutboxMock := new(taskMock.IOutboxRepository)
+	iOutboxMock.On("WithRelayLock", tmock.Anything).Return(func(fn func() error) bool {
+		return true
+	}, func(fn func() error) error {
+		return fn()
+	})
+	iOutboxMock.On("FindPendingOutbox", tmock.Anything, 10).Return(messages, nil)
+	iOutboxMock.On("MarkOutboxSent", tmock.Anything).Return(nil)
+	iOutboxMock.On("MarkOutboxFailed", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).Return(nil)
+	iOutboxMock.On("MarkOutboxAbandoned", tmock.Anything, tmock.Anything).Return(nil)
+	iOutboxMock.On("DeleteSentOutbox", tmock.Anything).Return(int64(0), nil)
+	repository.OutboxRepositoryServices = iOutboxMock
+
+	return iOutboxMock
+}
+
+func TestRelayRunOnce(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: publish pending messages in order and mark them sent", func(t *testing.T) {
+		iOutboxMock := mockOutbox([]models.OutboxMessage{
+			outboxMessage(1, 7, events.TaskCreated),
+			outboxMessage(2, 8, events.TaskCreated),
+			outboxMessage(3, 7, events.TaskExecuted),
+		})
+
+		var published []string
+		relay := &outbox.Relay{BatchSize: 10, Retention: time.Hour, CleanupInterval: time.Hour}
+		relay.Publish = func(ctx context.Context, event events.Event) error {
+			published = append(published, event.Subject+" "+event.Type)
+			return nil
+		}
+
+		sent, err := relay.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(3, sent)
+		assert.Equal([]string{"task/7 task.created", "task/8 task.created", "task/7 task.executed"}, published)
+		iOutboxMock.AssertCalled(t, "MarkOutboxSent", uint64(1))
+		iOutboxMock.AssertCalled(t, "MarkOutboxSent", uint64(2))
+		iOutboxMock.AssertCalled(t, "MarkOutboxSent", uint64(3))
+		iOutboxMock.AssertCalled(t, "DeleteSentOutbox", tmock.Anything)
+	})
+
+	t.Run("Failed: a failure holds back the later messages of the aggregate", func(t *testing.T) {
+		iOutboxMock := mockOutbox([]models.OutboxMessage{
+			outboxMessage(1, 7, events.TaskCreated),
+			outboxMessage(2, 8, events.TaskCreated),
+			outboxMessage(3, 7, events.TaskExecuted),
+		})
+
+		var published []string
+		relay := &outbox.Relay{BatchSize: 10}
+		relay.Publish = func(ctx context.Context, event events.Event) error {
+			if event.Subject == "task/7" {
+				return errors.New("connection closed")
+			}
+			published = append(published, event.Subject+" "+event.Type)
+			return nil
+		}
+
+		start := time.Now()
+		sent, err := relay.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(1, sent)
+		assert.Equal([]string{"task/8 task.created"}, published)
+		iOutboxMock.AssertCalled(t, "MarkOutboxFailed", uint64(1), "connection closed", tmock.MatchedBy(func(next time.Time) bool {
+			return !next.Before(start.Add(time.Second))
+		}), false)
+		iOutboxMock.AssertNotCalled(t, "MarkOutboxSent", uint64(1))
+		iOutboxMock.AssertNotCalled(t, "MarkOutboxSent", uint64(3))
+		iOutboxMock.AssertNotCalled(t, "MarkOutboxFailed", uint64(3), tmock.Anything, tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Failed: an unroutable message is marked failed with the error of the broker", func(t *testing.T) {
+		iOutboxMock := mockOutbox([]models.OutboxMessage{
+			outboxMessage(1, 7, events.TaskCreated),
+		})
+
+		broker.BrokerServices = broker.NewMemory()
+		relay := &outbox.Relay{BatchSize: 10, Publish: broker.PublishEvent}
+
+		sent, err := relay.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(0, sent)
+		iOutboxMock.AssertCalled(t, "MarkOutboxFailed", uint64(1), broker.ErrUnroutable.Error(), tmock.Anything, true)
+		iOutboxMock.AssertNotCalled(t, "MarkOutboxSent", uint64(1))
+	})
+
+	t.Run("Failed: a message out of attempts is abandoned and releases its aggregate", func(t *testing.T) {
+		unroutable := outboxMessage(1, 7, events.TaskCreated)
+		unroutable.Attempts = 9
+		unroutable.Rejections = 4
+		iOutboxMock := mockOutbox([]models.OutboxMessage{unroutable, outboxMessage(2, 7, events.TaskUpdated)})
+
+		var published []string
+		relay := &outbox.Relay{BatchSize: 10, MaxAttempts: 5}
+		relay.Publish = func(ctx context.Context, event events.Event) error {
+			if event.Type == events.TaskCreated {
+				return broker.ErrUnroutable
+			}
+			published = append(published, event.Type)
+			return nil
+		}
+
+		sent, err := relay.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(1, sent)
+		assert.Equal([]string{events.TaskUpdated}, published)
+		iOutboxMock.AssertCalled(t, "MarkOutboxAbandoned", uint64(1), broker.ErrUnroutable.Error())
+		iOutboxMock.AssertNotCalled(t, "MarkOutboxFailed", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+		iOutboxMock.AssertCalled(t, "MarkOutboxSent", uint64(2))
+	})
+
+	t.Run("Failed: a broker outage is retried past the attempts limit", func(t *testing.T) {
+		unreachable := outboxMessage(1, 7, events.TaskCreated)
+		unreachable.Attempts = 30
+		iOutboxMock := mockOutbox([]models.OutboxMessage{unreachable})
+
+		relay := &outbox.Relay{BatchSize: 10, MaxAttempts: 5}
+		relay.Publish = func(ctx context.Context, event events.Event) error {
+			return broker.ErrNotStarted
+		}
+
+		sent, err := relay.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(0, sent)
+		iOutboxMock.AssertCalled(t, "MarkOutboxFailed", uint64(1), broker.ErrNotStarted.Error(), tmock.Anything, false)
+		iOutboxMock.AssertNotCalled(t, "MarkOutboxAbandoned", tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: messages waiting for a retry are not published", func(t *testing.T) {
+		waiting := outboxMessage(1, 7, events.TaskCreated)
+		waiting.Attempts = 2
+		waiting.NextAttemptAt = time.Now().Add(time.Minute)
+		iOutboxMock := mockOutbox([]models.OutboxMessage{waiting, outboxMessage(2, 7, events.TaskUpdated)})
+
+		relay := &outbox.Relay{BatchSize: 10}
+		relay.Publish = func(ctx context.Context, event events.Event) error {
+			t.Fatalf("unexpected publish of %s", event.Type)
+			return nil
+		}
+
+		sent, err := relay.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(0, sent)
+		iOutboxMock.AssertNotCalled(t, "MarkOutboxSent", tmock.Anything)
+		iOutboxMock.AssertNotCalled(t, "MarkOutboxFailed", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Success: another instance holds the lock", func(t *testing.T) {
+		iOutboxMock := new(taskMock.IOutboxRepository)
+		iOutboxMock.On("WithRelayLock", tmock.Anything).Return(false, nil)
+		repository.OutboxRepositoryServices = iOutboxMock
+
+		relay := &outbox.Relay{BatchSize: 10}
+		sent, err := relay.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(0, sent)
+		iOutboxMock.AssertNotCalled(t, "FindPendingOutbox", tmock.Anything, tmock.Anything)
+	})
+}
+
+func TestNewRelay(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: defaults when unset", func(t *testing.T) {
+		t.Setenv("OUTBOX_MAX_ATTEMPTS", "")
+		t.Setenv("OUTBOX_POLL_INTERVAL_MS", "")
+
+		relay := outbox.NewRelay()
+
+		// asserts
+		assert.Equal(uint32(20), relay.MaxAttempts)
+		assert.Equal(time.Second, relay.Interval)
+	})
+
+	t.Run("Success: 0 attempts retries rejected messages forever", func(t *testing.T) {
+		t.Setenv("OUTBOX_MAX_ATTEMPTS", "0")
+		t.Setenv("OUTBOX_POLL_INTERVAL_MS", "0")
+
+		relay := outbox.NewRelay()
+
+		// asserts
+		assert.Equal(uint32(0), relay.MaxAttempts)
+		assert.Equal(time.Second, relay.Interval)
+	})
+}
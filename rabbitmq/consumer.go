@@ -0,0 +1,166 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gbeletti/rabbitmq"
+	"github.com/hugohenrick/gtasks/broker"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Headers of the retried and dead-lettered messages
+const (
+	headerRetries       = "x-retries"
+	headerOriginalQueue = "x-original-queue"
+	headerLastError     = "x-last-error"
+	headerFailedAt      = "x-failed-at"
+)
+
+// retryDelays are the waits before the retries of a failed message, the
+// retries after the last one keep waiting the last delay. Each delay has its
+// own retry queue: the messages expire there (TTL) and are dead-lettered (DLX)
+// back to the queue of the consumer.
+var retryDelays = []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute, 10 * time.Minute}
+
+// RetryQueue returns the name of the retry queue of the given level, from 1
+func RetryQueue(queue string, level int) string {
+	return fmt.Sprintf("%s.retry.%d", queue, level)
+}
+
+// DeadLetterQueue returns the name of the dead-letter queue of a consumed queue
+func DeadLetterQueue(queue string) string {
+	return queue + ".dead"
+}
+
+// subscriptionQueues returns the declarations of the retry and dead-letter queues of the subscription
+func subscriptionQueues(c subscription) []rabbitmq.ConfigQueue {
+	queues := make([]rabbitmq.ConfigQueue, 0, len(retryDelays)+1)
+	for i, delay := range retryDelays {
+		queues = append(queues, rabbitmq.ConfigQueue{
+			Name:    RetryQueue(c.Queue, i+1),
+			Durable: true,
+			Args: amqp.Table{
+				"x-message-ttl":             int32(delay / time.Millisecond),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": c.Queue,
+			},
+		})
+	}
+
+	return append(queues, rabbitmq.ConfigQueue{Name: DeadLetterQueue(c.Queue), Durable: true})
+}
+
+func maxAttempts(c subscription) int {
+	if c.MaxAttempts > 0 {
+		return c.MaxAttempts
+	}
+
+	value, err := strconv.Atoi(os.Getenv("RABBITMQ_MAX_ATTEMPTS"))
+	if err != nil || value <= 0 {
+		return 5
+	}
+	return value
+}
+
+// deliver runs the handler of the subscription and acks the message. Failed
+// messages are republished to a retry queue, or to the dead-letter queue after
+// the last attempt; when that publish fails the message is requeued. Failed
+// messages of transient queues are dropped.
+func deliver(ctx context.Context, publisher rabbitmq.Publisher, c subscription, d *amqp.Delivery) {
+	err := c.Handler(ctx, broker.Message{
+		Queue:         c.Queue,
+		Exchange:      d.Exchange,
+		RoutingKey:    d.RoutingKey,
+		Body:          d.Body,
+		ContentType:   d.ContentType,
+		MessageId:     d.MessageId,
+		CorrelationId: d.CorrelationId,
+		Headers:       d.Headers,
+	})
+	if err == nil {
+		ack(d)
+		return
+	}
+
+	if c.Transient {
+		log.Printf("dropping message %s of %s: %s\n", d.MessageId, c.Queue, err)
+		ack(d)
+		return
+	}
+
+	retries := headerInt(d.Headers, headerRetries)
+	if broker.IsPermanent(err) || retries+1 >= maxAttempts(c) {
+		log.Printf("dead-lettering message %s of %s after %d attempts: %s\n", d.MessageId, c.Queue, retries+1, err)
+		err = republish(ctx, publisher, d, DeadLetterQueue(c.Queue), amqp.Table{
+			headerRetries:       int32(retries),
+			headerOriginalQueue: c.Queue,
+			headerLastError:     err.Error(),
+			headerFailedAt:      time.Now().UTC().Format(time.RFC3339),
+		})
+	} else {
+		level := retries + 1
+		if level > len(retryDelays) {
+			level = len(retryDelays)
+		}
+		log.Printf("retrying message %s of %s in %s, attempt %d: %s\n", d.MessageId, c.Queue, retryDelays[level-1], retries+1, err)
+		err = republish(ctx, publisher, d, RetryQueue(c.Queue, level), amqp.Table{
+			headerRetries:   int32(retries + 1),
+			headerLastError: err.Error(),
+		})
+	}
+
+	if err != nil {
+		log.Printf("error republishing message %s of %s, requeueing: %s\n", d.MessageId, c.Queue, err)
+		if err := d.Nack(false, true); err != nil {
+			log.Printf("error nacking message: %s\n", err)
+		}
+		return
+	}
+	ack(d)
+}
+
+// republish publishes a copy of the delivery to the queue with extra headers
+func republish(ctx context.Context, publisher rabbitmq.Publisher, d *amqp.Delivery, queue string, headers amqp.Table) error {
+	table := amqp.Table{}
+	for key, value := range d.Headers {
+		table[key] = value
+	}
+	for key, value := range headers {
+		table[key] = value
+	}
+
+	return publisher.Publish(ctx, d.Body, rabbitmq.ConfigPublish{
+		Exchange:        "",
+		RoutingKey:      queue,
+		Headers:         table,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		Priority:        d.Priority,
+		CorrelationID:   d.CorrelationId,
+		MessageID:       d.MessageId,
+	})
+}
+
+func ack(d *amqp.Delivery) {
+	if err := d.Ack(false); err != nil {
+		log.Printf("error acking message: %s\n", err)
+	}
+}
+
+// headerInt reads an integer header, 0 when missing
+func headerInt(headers amqp.Table, name string) int {
+	switch value := headers[name].(type) {
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	}
+	return 0
+}
@@ -0,0 +1,213 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gbeletti/rabbitmq"
+	"github.com/hugohenrick/gtasks/broker"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAcknowledger records the acks and nacks of a delivery
+type fakeAcknowledger struct {
+	acked   bool
+	nacked  bool
+	requeue bool
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked = true
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	a.nacked = true
+	a.requeue = requeue
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+// fakePublisher records the published messages
+type fakePublisher struct {
+	err       error
+	published []rabbitmq.ConfigPublish
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, body []byte, config rabbitmq.ConfigPublish) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, config)
+	return nil
+}
+
+func newDelivery(retries int) (*amqp.Delivery, *fakeAcknowledger) {
+	acknowledger := &fakeAcknowledger{}
+	headers := amqp.Table{}
+	if retries > 0 {
+		headers[headerRetries] = int32(retries)
+	}
+
+	return &amqp.Delivery{
+		Acknowledger:  acknowledger,
+		Headers:       headers,
+		MessageId:     "message-1",
+		CorrelationId: "corr-1",
+		Body:          []byte(`{"type":"task.executed"}`),
+	}, acknowledger
+}
+
+func TestDeliver(t *testing.T) {
+	assert := assert.New(t)
+
+	failing := func(ctx context.Context, msg broker.Message) error {
+		return errors.New("database is down")
+	}
+
+	t.Run("Success: handled message is acked", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		d, acknowledger := newDelivery(0)
+
+		var handled broker.Message
+		deliver(context.Background(), publisher, subscription{Queue: "tasks", Handler: func(ctx context.Context, msg broker.Message) error {
+			handled = msg
+			return nil
+		}, MaxAttempts: 3}, d)
+
+		// asserts
+		assert.True(acknowledger.acked)
+		assert.Equal("tasks", handled.Queue)
+		assert.Equal("message-1", handled.MessageId)
+		assert.Equal(`{"type":"task.executed"}`, string(handled.Body))
+		assert.Empty(publisher.published)
+	})
+
+	t.Run("Failed: first failure goes to the first retry queue", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		d, acknowledger := newDelivery(0)
+
+		deliver(context.Background(), publisher, subscription{Queue: "tasks", Handler: failing, MaxAttempts: 3}, d)
+
+		// asserts
+		assert.True(acknowledger.acked)
+		assert.Len(publisher.published, 1)
+		assert.Equal("tasks.retry.1", publisher.published[0].RoutingKey)
+		assert.Equal(int32(1), publisher.published[0].Headers[headerRetries])
+		assert.Equal("database is down", publisher.published[0].Headers[headerLastError])
+		assert.Equal("message-1", publisher.published[0].MessageID)
+		assert.Equal("corr-1", publisher.published[0].CorrelationID)
+	})
+
+	t.Run("Failed: retries wait longer", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		d, _ := newDelivery(1)
+
+		deliver(context.Background(), publisher, subscription{Queue: "tasks", Handler: failing, MaxAttempts: 3}, d)
+
+		// asserts
+		assert.Equal("tasks.retry.2", publisher.published[0].RoutingKey)
+		assert.Equal(int32(2), publisher.published[0].Headers[headerRetries])
+	})
+
+	t.Run("Failed: last attempt goes to the dead-letter queue", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		d, acknowledger := newDelivery(2)
+
+		deliver(context.Background(), publisher, subscription{Queue: "tasks", Handler: failing, MaxAttempts: 3}, d)
+
+		// asserts
+		assert.True(acknowledger.acked)
+		assert.Equal("tasks.dead", publisher.published[0].RoutingKey)
+		assert.Equal("tasks", publisher.published[0].Headers[headerOriginalQueue])
+		assert.Equal("database is down", publisher.published[0].Headers[headerLastError])
+		assert.NotEmpty(publisher.published[0].Headers[headerFailedAt])
+	})
+
+	t.Run("Failed: permanent error is dead-lettered at once", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		d, _ := newDelivery(0)
+
+		deliver(context.Background(), publisher, subscription{Queue: "tasks", Handler: func(ctx context.Context, msg broker.Message) error {
+			return broker.Permanent(errors.New("invalid body"))
+		}, MaxAttempts: 3}, d)
+
+		// asserts
+		assert.Equal("tasks.dead", publisher.published[0].RoutingKey)
+	})
+
+	t.Run("Failed: message is requeued when the retry cannot be published", func(t *testing.T) {
+		publisher := &fakePublisher{err: amqp.ErrClosed}
+		d, acknowledger := newDelivery(0)
+
+		deliver(context.Background(), publisher, subscription{Queue: "tasks", Handler: failing, MaxAttempts: 3}, d)
+
+		// asserts
+		assert.False(acknowledger.acked)
+		assert.True(acknowledger.nacked)
+		assert.True(acknowledger.requeue)
+	})
+}
+
+func TestSubscriptionQueues(t *testing.T) {
+	assert := assert.New(t)
+
+	queues := subscriptionQueues(subscription{Queue: "tasks"})
+
+	// asserts
+	assert.Len(queues, len(retryDelays)+1)
+	assert.Equal("tasks.retry.1", queues[0].Name)
+	assert.Equal(int32(5000), queues[0].Args["x-message-ttl"])
+	assert.Equal("tasks", queues[0].Args["x-dead-letter-routing-key"])
+	assert.Equal("tasks.dead", queues[len(queues)-1].Name)
+}
+
+// fakeTopology records the declared exchanges, queues and bindings
+type fakeTopology struct {
+	exchanges []rabbitmq.ConfigExchange
+	queues    []string
+	bindings  []rabbitmq.ConfigBindQueue
+}
+
+func (f *fakeTopology) CreateExchange(config rabbitmq.ConfigExchange) error {
+	f.exchanges = append(f.exchanges, config)
+	return nil
+}
+
+func (f *fakeTopology) CreateQueue(config rabbitmq.ConfigQueue) (amqp.Queue, error) {
+	f.queues = append(f.queues, config.Name)
+	return amqp.Queue{Name: config.Name}, nil
+}
+
+func (f *fakeTopology) BindQueueExchange(config rabbitmq.ConfigBindQueue) error {
+	f.bindings = append(f.bindings, config)
+	return nil
+}
+
+func (f *fakeTopology) UnbindQueueExchange(config rabbitmq.ConfigBindQueue) error {
+	return nil
+}
+
+func TestDeclareTopology(t *testing.T) {
+	assert := assert.New(t)
+
+	rabbit := &fakeTopology{}
+	declareTopology(rabbit, []subscription{{Queue: "tasks", Bindings: []string{"task.executed.*"}}})
+
+	// asserts
+	assert.Len(rabbit.exchanges, 1)
+	assert.Equal(broker.EventsExchange, rabbit.exchanges[0].Name)
+	assert.Equal(amqp.ExchangeTopic, rabbit.exchanges[0].Type)
+	assert.True(rabbit.exchanges[0].Durable)
+	assert.Contains(rabbit.queues, "users")
+	assert.Contains(rabbit.queues, "tasks")
+	assert.Contains(rabbit.queues, "tasks.retry.1")
+	assert.Contains(rabbit.queues, "tasks.dead")
+	assert.Contains(rabbit.bindings, rabbitmq.ConfigBindQueue{QueueName: "tasks", Exchange: broker.EventsExchange, RoutingKey: "task.executed.*"})
+	assert.Contains(rabbit.bindings, rabbitmq.ConfigBindQueue{QueueName: "users", Exchange: broker.EventsExchange, RoutingKey: "user.#"})
+}
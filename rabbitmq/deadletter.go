@@ -0,0 +1,151 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/models"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetters works on the dead-letter queues with its own connection, the
+// connection of the consumers doesn't expose basic.get and purge
+type DeadLetters struct {
+	URI string
+}
+
+func NewDeadLetters() broker.IDeadLetters {
+	return &DeadLetters{URI: loadURI()}
+}
+
+// InspectDeadLetters returns up to limit messages of the dead-letter queue of
+// queue. The messages are not acked, so they go back to the dead-letter queue.
+func (t *DeadLetters) InspectDeadLetters(queue string, limit int) ([]models.DeadLetter, error) {
+	deadLetters := []models.DeadLetter{}
+
+	err := t.withChannel(func(ch *amqp.Channel) error {
+		for len(deadLetters) < limit {
+			d, ok, err := ch.Get(DeadLetterQueue(queue), false)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+			deadLetters = append(deadLetters, deadLetterFromDelivery(queue, d))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deadLetters, nil
+}
+
+// ReplayDeadLetters publishes up to limit dead-lettered messages to queue
+// again, with their attempts reset, and returns how many were replayed
+func (t *DeadLetters) ReplayDeadLetters(queue string, limit int) (int, error) {
+	replayed := 0
+
+	err := t.withChannel(func(ch *amqp.Channel) error {
+		for replayed < limit {
+			d, ok, err := ch.Get(DeadLetterQueue(queue), false)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+
+			headers := amqp.Table{}
+			for key, value := range d.Headers {
+				headers[key] = value
+			}
+			for _, key := range []string{headerRetries, headerOriginalQueue, headerLastError, headerFailedAt} {
+				delete(headers, key)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err = ch.PublishWithContext(ctx, "", queue, false, false, amqp.Publishing{
+				Headers:         headers,
+				ContentType:     d.ContentType,
+				ContentEncoding: d.ContentEncoding,
+				Priority:        d.Priority,
+				CorrelationId:   d.CorrelationId,
+				MessageId:       d.MessageId,
+				Body:            d.Body,
+			})
+			cancel()
+			if err != nil {
+				return err
+			}
+
+			if err := d.Ack(false); err != nil {
+				return err
+			}
+			replayed++
+		}
+		return nil
+	})
+
+	return replayed, err
+}
+
+// PurgeDeadLetters deletes the messages of the dead-letter queue of queue and
+// returns how many were deleted
+func (t *DeadLetters) PurgeDeadLetters(queue string) (int, error) {
+	var purged int
+
+	err := t.withChannel(func(ch *amqp.Channel) error {
+		var err error
+		purged, err = ch.QueuePurge(DeadLetterQueue(queue), false)
+		return err
+	})
+
+	return purged, err
+}
+
+func (t *DeadLetters) withChannel(fn func(ch *amqp.Channel) error) error {
+	conn, err := amqp.Dial(t.URI)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	return fn(ch)
+}
+
+func deadLetterFromDelivery(queue string, d amqp.Delivery) models.DeadLetter {
+	deadLetter := models.DeadLetter{
+		MessageId:     d.MessageId,
+		CorrelationId: d.CorrelationId,
+		Queue:         queue,
+		ContentType:   d.ContentType,
+		Attempts:      headerInt(d.Headers, headerRetries) + 1,
+		Body:          json.RawMessage(d.Body),
+	}
+
+	if lastError, ok := d.Headers[headerLastError].(string); ok {
+		deadLetter.LastError = lastError
+	}
+	if failedAt, ok := d.Headers[headerFailedAt].(string); ok {
+		if at, err := time.Parse(time.RFC3339, failedAt); err == nil {
+			deadLetter.FailedAt = &at
+		}
+	}
+	if !json.Valid(d.Body) {
+		body, _ := json.Marshal(string(d.Body))
+		deadLetter.Body = body
+	}
+
+	return deadLetter
+}
@@ -0,0 +1,183 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gbeletti/rabbitmq"
+	"github.com/hugohenrick/gtasks/broker"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// confirmBuffer is the size of the buffers of the confirmations and returns of the channel
+const confirmBuffer = 64
+
+// errChannelClosed is returned when the channel closes before confirming a publish
+var errChannelClosed = errors.New("channel closed before the publish was confirmed")
+
+// Publisher publishes over its own channel in confirm mode, the connection of
+// the consumers exposes neither confirmations nor returns. Messages are
+// persistent and mandatory: a publish waits for the broker to ack it and fails
+// when the broker nacks or returns it. The channel is opened again on the
+// next publish after it closes.
+type Publisher struct {
+	URI     string
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+}
+
+// NewPublisher returns the publisher of RABBITMQ_URI, waiting up to
+// RABBITMQ_CONFIRM_TIMEOUT_MS (5000 by default) for each confirmation
+func NewPublisher() *Publisher {
+	timeout, err := strconv.Atoi(os.Getenv("RABBITMQ_CONFIRM_TIMEOUT_MS"))
+	if err != nil || timeout <= 0 {
+		timeout = 5000
+	}
+
+	return &Publisher{URI: loadURI(), Timeout: time.Duration(timeout) * time.Millisecond}
+}
+
+// Publish publishes the body and waits for its confirmation until the context
+// is done or the timeout of the publisher elapses. It implements the
+// rabbitmq.Publisher interface, so the consumers republish with it too.
+func (p *Publisher) Publish(ctx context.Context, body []byte, config rabbitmq.ConfigPublish) error {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.open(); err != nil {
+		return err
+	}
+
+	tag := p.channel.GetNextPublishSeqNo()
+	err := p.channel.PublishWithContext(ctx, config.Exchange, config.RoutingKey, true, false, amqp.Publishing{
+		Headers:         config.Headers,
+		ContentType:     config.ContentType,
+		ContentEncoding: config.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		Priority:        config.Priority,
+		CorrelationId:   config.CorrelationID,
+		MessageId:       config.MessageID,
+		Timestamp:       time.Now(),
+		Body:            body,
+	})
+	if err != nil {
+		p.close()
+		return err
+	}
+
+	err = awaitConfirm(ctx, tag, config.MessageID, p.confirms, p.returns)
+	if errors.Is(err, errChannelClosed) {
+		p.close()
+	}
+	return err
+}
+
+// Close closes the channel and the connection of the publisher
+func (p *Publisher) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.close()
+}
+
+func (p *Publisher) open() error {
+	if p.channel != nil && !p.channel.IsClosed() {
+		return nil
+	}
+	p.close()
+
+	conn, err := amqp.Dial(p.URI)
+	if err != nil {
+		return err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return err
+	}
+
+	p.conn = conn
+	p.channel = ch
+	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, confirmBuffer))
+	p.returns = ch.NotifyReturn(make(chan amqp.Return, confirmBuffer))
+	return nil
+}
+
+func (p *Publisher) close() {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn = nil
+	p.channel = nil
+}
+
+// awaitConfirm waits for the confirmation of the publish with the delivery
+// tag. Confirmations of earlier publishes, whose wait ended with their
+// context, are skipped. The broker sends basic.return before the ack of a
+// mandatory message it could not route, so the returns are read after the ack.
+func awaitConfirm(ctx context.Context, tag uint64, messageId string, confirms <-chan amqp.Confirmation, returns <-chan amqp.Return) error {
+	for {
+		select {
+		case confirmation, ok := <-confirms:
+			if !ok {
+				return errChannelClosed
+			}
+			if confirmation.DeliveryTag < tag {
+				continue
+			}
+			if !confirmation.Ack {
+				return broker.ErrNacked
+			}
+			return returned(returns, messageId)
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for the publish confirmation: %w", ctx.Err())
+		}
+	}
+}
+
+// returned reads the pending returns and reports whether the message was among them
+func returned(returns <-chan amqp.Return, messageId string) error {
+	var err error
+	for {
+		select {
+		case r, ok := <-returns:
+			if !ok {
+				return err
+			}
+			if r.MessageId == messageId {
+				err = fmt.Errorf("%w: %s with routing key %s (%s)", broker.ErrUnroutable, exchangeName(r.Exchange), r.RoutingKey, r.ReplyText)
+			}
+		default:
+			return err
+		}
+	}
+}
+
+func exchangeName(exchange string) string {
+	if exchange == "" {
+		return "default exchange"
+	}
+	return "exchange " + exchange
+}
@@ -0,0 +1,72 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/broker"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAwaitConfirm(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: acked", func(t *testing.T) {
+		confirms := make(chan amqp.Confirmation, 2)
+		returns := make(chan amqp.Return, 1)
+		confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: false}
+		confirms <- amqp.Confirmation{DeliveryTag: 2, Ack: true}
+
+		err := awaitConfirm(context.Background(), 2, "message-2", confirms, returns)
+
+		// asserts
+		assert.Nil(err)
+	})
+
+	t.Run("Failed: nacked", func(t *testing.T) {
+		confirms := make(chan amqp.Confirmation, 1)
+		confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: false}
+
+		err := awaitConfirm(context.Background(), 1, "message-1", confirms, make(chan amqp.Return))
+
+		// asserts
+		assert.ErrorIs(err, broker.ErrNacked)
+	})
+
+	t.Run("Failed: returned as unroutable", func(t *testing.T) {
+		confirms := make(chan amqp.Confirmation, 1)
+		returns := make(chan amqp.Return, 2)
+		returns <- amqp.Return{MessageId: "message-0", Exchange: broker.EventsExchange, RoutingKey: "task.created.1"}
+		returns <- amqp.Return{MessageId: "message-1", Exchange: broker.EventsExchange, RoutingKey: "task.executed.1", ReplyText: "NO_ROUTE"}
+		confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: true}
+
+		err := awaitConfirm(context.Background(), 1, "message-1", confirms, returns)
+
+		// asserts
+		assert.ErrorIs(err, broker.ErrUnroutable)
+		assert.Contains(err.Error(), "task.executed.1")
+		assert.Len(returns, 0)
+	})
+
+	t.Run("Failed: context done before the confirmation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := awaitConfirm(ctx, 1, "message-1", make(chan amqp.Confirmation), make(chan amqp.Return))
+
+		// asserts
+		assert.ErrorIs(err, context.DeadlineExceeded)
+	})
+
+	t.Run("Failed: channel closed", func(t *testing.T) {
+		confirms := make(chan amqp.Confirmation)
+		close(confirms)
+
+		err := awaitConfirm(context.Background(), 1, "message-1", confirms, make(chan amqp.Return))
+
+		// asserts
+		assert.ErrorIs(err, errChannelClosed)
+	})
+}
@@ -1,60 +1,165 @@
+// Package rabbitmq implements the message broker over RabbitMQ (AMQP)
 package rabbitmq
 
 import (
 	"context"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/gbeletti/rabbitmq"
+	"github.com/hugohenrick/gtasks/broker"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-var rabbit rabbitmq.RabbitMQ
+// AMQP is the broker.Broker of RabbitMQ. The connection is kept open and, on
+// every (re)connection, the topology is declared and the subscriptions consume
+// again.
+type AMQP struct {
+	rabbit    rabbitmq.RabbitMQ
+	publisher *Publisher
 
-// Start starts the RabbitMQ connection
-func Start(ctx context.Context) {
-	rabbit = rabbitmq.NewRabbitMQ()
+	mu            sync.Mutex
+	ready         bool
+	subscriptions []subscription
+}
 
-	setupRabbit(ctx)
+// subscription is a queue consumed by a handler with retries and a dead-letter queue
+type subscription struct {
+	ctx         context.Context
+	Queue       string
+	Bindings    []string
+	Transient   bool
+	Handler     broker.Handler
+	MaxAttempts int
 }
 
-// Shutdown stops the RabbitMQ connection
-func Shutdown(ctx context.Context) (done chan struct{}) {
-	done = rabbit.Close(ctx)
-	return
+func New() *AMQP {
+	return &AMQP{rabbit: rabbitmq.NewRabbitMQ(), publisher: NewPublisher()}
 }
 
-func setupRabbit(ctx context.Context) {
+// Start starts the RabbitMQ connection
+func (b *AMQP) Start(ctx context.Context) {
 	var setup rabbitmq.Setup = func() {
-		createQueues(rabbit)
-		createConsumers(ctx, rabbit)
+		b.setup()
 	}
 	configConn := rabbitmq.ConfigConnection{
 		URI:           loadURI(),
 		PrefetchCount: 1,
 	}
-	rabbitmq.KeepConnectionAndSetup(ctx, rabbit, configConn, setup)
+	rabbitmq.KeepConnectionAndSetup(ctx, b.rabbit, configConn, setup)
+}
+
+// Shutdown stops the RabbitMQ connections
+func (b *AMQP) Shutdown(ctx context.Context) (done chan struct{}) {
+	b.publisher.Close()
+	done = b.rabbit.Close(ctx)
+	return
 }
 
-func createQueues(rabbit rabbitmq.QueueCreator) {
-	config := rabbitmq.ConfigQueue{
-		Name:       "tasks",
+func (b *AMQP) setup() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	declareTopology(b.rabbit, b.subscriptions)
+	for _, s := range b.subscriptions {
+		b.consume(s)
+	}
+	b.ready = true
+}
+
+// topology is what declareTopology needs of the connection
+type topology interface {
+	rabbitmq.ExchangeCreator
+	rabbitmq.QueueCreator
+	rabbitmq.QueueBinder
+}
+
+// declareTopology declares the events exchange, the queues with their
+// bindings and the retry and dead-letter queues of the subscriptions. Transient
+// queues are exclusive to the connection and have no retry queues. Every
+// declaration is idempotent, so it runs again on every reconnection. Bindings
+// removed from the configuration are kept by RabbitMQ until unbound by hand.
+func declareTopology(rabbit topology, subscriptions []subscription) {
+	err := rabbit.CreateExchange(rabbitmq.ConfigExchange{
+		Name:       broker.EventsExchange,
+		Type:       amqp.ExchangeTopic,
 		Durable:    true,
 		AutoDelete: false,
-		Exclusive:  false,
+		Internal:   false,
 		NoWait:     false,
 		Args:       nil,
-	}
-	_, err := rabbit.CreateQueue(config)
+	})
 	if err != nil {
-		log.Printf("error creating queue: %s\n", err)
+		log.Printf("error creating exchange: %s\n", err)
 	}
+
+	queues := append([]broker.Queue{}, broker.Queues...)
+	for _, s := range subscriptions {
+		queues = append(queues, broker.Queue{Name: s.Queue, Bindings: s.Bindings, Transient: s.Transient})
+	}
+
+	for _, queue := range queues {
+		_, err := rabbit.CreateQueue(rabbitmq.ConfigQueue{
+			Name:       queue.Name,
+			Durable:    !queue.Transient,
+			AutoDelete: queue.Transient,
+			Exclusive:  queue.Transient,
+			NoWait:     false,
+			Args:       nil,
+		})
+		if err != nil {
+			log.Printf("error creating queue: %s\n", err)
+			continue
+		}
+
+		for _, pattern := range queue.Bindings {
+			err := rabbit.BindQueueExchange(rabbitmq.ConfigBindQueue{
+				QueueName:  queue.Name,
+				Exchange:   broker.EventsExchange,
+				RoutingKey: pattern,
+				NoWait:     false,
+				Args:       nil,
+			})
+			if err != nil {
+				log.Printf("error binding queue %s to %s: %s\n", queue.Name, pattern, err)
+			}
+		}
+	}
+
+	for _, s := range subscriptions {
+		if s.Transient {
+			continue
+		}
+		for _, config := range subscriptionQueues(s) {
+			if _, err := rabbit.CreateQueue(config); err != nil {
+				log.Printf("error creating queue: %s\n", err)
+			}
+		}
+	}
+}
+
+// Subscribe declares the queue of the consumer and consumes it with the
+// handler, now when connected and again after every reconnection
+func (b *AMQP) Subscribe(ctx context.Context, consumer broker.Consumer) error {
+	s := subscription{ctx: ctx, Queue: consumer.Name, Bindings: consumer.Bindings, Transient: consumer.Transient, Handler: consumer.Handler}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscriptions = append(b.subscriptions, s)
+	if b.ready {
+		declareTopology(b.rabbit, []subscription{s})
+		b.consume(s)
+	}
+
+	return nil
 }
 
-func createConsumers(ctx context.Context, rabbit rabbitmq.Consumer) {
+func (b *AMQP) consume(s subscription) {
 	config := rabbitmq.ConfigConsume{
-		QueueName:         "tasks",
-		Consumer:          "tasks",
+		QueueName:         s.Queue,
+		Consumer:          s.Queue,
 		AutoAck:           false,
 		Exclusive:         false,
 		NoLocal:           false,
@@ -63,29 +168,33 @@ func createConsumers(ctx context.Context, rabbit rabbitmq.Consumer) {
 		ExecuteConcurrent: true,
 	}
 	go func() {
-		if err := rabbit.Consume(ctx, config, receiveMessage); err != nil {
+		err := b.rabbit.Consume(s.ctx, config, func(d *amqp.Delivery) {
+			deliver(s.ctx, b.publisher, s, d)
+		})
+		if err != nil {
 			log.Printf("error consuming from queue: %s\n", err)
 		}
 	}()
 }
 
-func receiveMessage(d *amqp.Delivery) {
-	defer func() {
-		if err := d.Ack(false); err != nil {
-			log.Printf("error acking message: %s\n", err)
-		}
-	}()
-	log.Printf("received message: %s\n", d.Body)
-}
+// Publish publishes the message to its exchange with its routing key, or to
+// its queue with the default exchange, and waits for its confirmation
+func (b *AMQP) Publish(ctx context.Context, msg broker.Message) error {
+	exchange, routingKey := msg.Exchange, msg.RoutingKey
+	if exchange == "" {
+		routingKey = msg.Queue
+	}
 
-func PublishTask(ctx context.Context, msg string) {
 	config := rabbitmq.ConfigPublish{
-		Exchange:   "",
-		RoutingKey: "tasks",
-	}
-	if err := rabbit.Publish(ctx, []byte(msg), config); err != nil {
-		log.Printf("error publishing message: %s\n", err)
+		Exchange:      exchange,
+		RoutingKey:    routingKey,
+		Headers:       amqp.Table(msg.Headers),
+		ContentType:   msg.ContentType,
+		MessageID:     msg.MessageId,
+		CorrelationID: msg.CorrelationId,
 	}
+
+	return b.publisher.Publish(ctx, msg.Body, config)
 }
 
 func loadURI() (uri string) {
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+)
+
+type IApiTokenRepository interface {
+	CreateApiToken(token models.ApiToken) (models.ApiToken, error)
+	FindApiTokensByUser(userId uint32) ([]models.ApiToken, error)
+	RevokeApiToken(id string, userId uint32) (models.ApiToken, error)
+}
+
+type ApiTokenRepository struct {
+	Database *gorm.DB
+}
+
+var ApiTokenRepositoryServices IApiTokenRepository
+
+func NewApiTokenRepository() IApiTokenRepository {
+	return &ApiTokenRepository{Database: database.DB}
+}
+
+func (t *ApiTokenRepository) CreateApiToken(token models.ApiToken) (models.ApiToken, error) {
+	result := t.Database.Create(&token)
+
+	if result.RowsAffected == 0 {
+		return models.ApiToken{}, errors.New("api token not created")
+	}
+
+	return token, nil
+}
+
+// FindApiTokensByUser returns the tokens of the user that were not revoked, newest first
+func (t *ApiTokenRepository) FindApiTokensByUser(userId uint32) ([]models.ApiToken, error) {
+	var tokens []models.ApiToken
+
+	err := t.Database.Where("user_id = ? AND revoked_at IS NULL", userId).Order("created_at DESC, id DESC").Find(&tokens).Error
+
+	return tokens, err
+}
+
+// RevokeApiToken revokes a token of the user, the token stops working at once
+func (t *ApiTokenRepository) RevokeApiToken(id string, userId uint32) (models.ApiToken, error) {
+	var token models.ApiToken
+
+	t.Database.First(&token, "id = ? AND user_id = ? AND revoked_at IS NULL", id, userId)
+
+	if token.ID == 0 {
+		return models.ApiToken{}, errors.New(utils.ApiTokenNotFound)
+	}
+
+	timeNow := time.Now()
+	result := t.Database.Model(&token).Update("revoked_at", &timeNow)
+
+	if result.Error != nil {
+		return models.ApiToken{}, errors.New("api token not revoked")
+	}
+	token.RevokedAt = &timeNow
+
+	return token, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICustomerRepository interface {
+	FindCustomers(organizationId uint32) ([]models.Customer, error)
+	FindCustomerById(id string) (models.Customer, error)
+	CreateCustomer(customer models.Customer, meta events.Meta) (models.Customer, error)
+	UpdateCustomer(id string, customer models.Customer, meta events.Meta) (models.Customer, error)
+	DeleteCustomer(id string, meta events.Meta) (int64, error)
+}
+
+type CustomerRepository struct {
+	Database *gorm.DB
+}
+
+var CustomerRepositoryServices ICustomerRepository
+
+func NewCustomerRepository() ICustomerRepository {
+	return &CustomerRepository{Database: database.DB}
+}
+
+func (t *CustomerRepository) FindCustomers(organizationId uint32) ([]models.Customer, error) {
+	var customers []models.Customer
+
+	err := t.Database.Where("organization_id = ?", organizationId).Order("name").Find(&customers).Error
+	if err != nil {
+		return []models.Customer{}, err
+	}
+
+	return customers, nil
+}
+
+func (t *CustomerRepository) FindCustomerById(id string) (models.Customer, error) {
+	var customer models.Customer
+
+	result := t.Database.First(&customer, "id = ?", id)
+
+	if result.RowsAffected == 0 {
+		return models.Customer{}, errors.New(utils.CustomerNotFound)
+	}
+
+	return customer, nil
+}
+
+// The mutations of the customers save their events in the outbox with the
+// same transaction, like the tasks.
+
+func (t *CustomerRepository) CreateCustomer(customer models.Customer, meta events.Meta) (models.Customer, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Create(&customer)
+
+		if result.RowsAffected == 0 {
+			return errors.New("customer not created")
+		}
+
+		return addEntityEvent(tx, events.CustomerCreated, customer.ID, customer.OrganizationId, meta, events.CustomerData{
+			Customer: serializers.NewCustomerEvent(customer),
+			ActorId:  meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.Customer{}, err
+	}
+
+	return customer, nil
+}
+
+func (t *CustomerRepository) UpdateCustomer(id string, customer models.Customer, meta events.Meta) (models.Customer, error) {
+	var current models.Customer
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, id)
+
+		if current.ID == 0 {
+			return errors.New(utils.CustomerNotFound)
+		}
+
+		current.Name = customer.Name
+
+		result := tx.Save(&current)
+
+		if result.RowsAffected == 0 {
+			return errors.New("customer not save")
+		}
+
+		return addEntityEvent(tx, events.CustomerUpdated, current.ID, current.OrganizationId, meta, events.CustomerData{
+			Customer: serializers.NewCustomerEvent(current),
+			ActorId:  meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.Customer{}, err
+	}
+
+	return current, nil
+}
+
+// DeleteCustomer deletes a customer without sites
+func (t *CustomerRepository) DeleteCustomer(id string, meta events.Meta) (int64, error) {
+	var affected int64
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var customer models.Customer
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, "id = ?", id)
+		if customer.ID == 0 {
+			return errors.New(utils.CustomerNotFound)
+		}
+
+		var sites int64
+
+		tx.Model(&models.Site{}).Where("customer_id = ?", customer.ID).Count(&sites)
+		if sites > 0 {
+			return utils.ErrCustomerHasSites
+		}
+
+		result := tx.Delete(&customer)
+		if result.RowsAffected == 0 {
+			return errors.New(utils.CustomerNotFound)
+		}
+		affected = result.RowsAffected
+
+		return addDeletedEvent(tx, events.CustomerDeleted, customer.ID, customer.OrganizationId, meta)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICustomFieldRepository interface {
+	FindCustomFields(organizationId uint32) ([]models.CustomFieldSchema, error)
+	FindCustomFieldById(id string) (models.CustomFieldSchema, error)
+	CreateCustomField(field models.CustomFieldSchema, meta events.Meta) (models.CustomFieldSchema, error)
+	UpdateCustomField(id string, field models.CustomFieldSchema, meta events.Meta) (models.CustomFieldSchema, error)
+	DeleteCustomField(id string, meta events.Meta) (int64, error)
+}
+
+type CustomFieldRepository struct {
+	Database *gorm.DB
+}
+
+var CustomFieldRepositoryServices ICustomFieldRepository
+
+func NewCustomFieldRepository() ICustomFieldRepository {
+	return &CustomFieldRepository{Database: database.DB}
+}
+
+func (t *CustomFieldRepository) FindCustomFields(organizationId uint32) ([]models.CustomFieldSchema, error) {
+	var fields []models.CustomFieldSchema
+
+	err := t.Database.Where("organization_id = ?", organizationId).Order("id").Find(&fields).Error
+	if err != nil {
+		return []models.CustomFieldSchema{}, err
+	}
+
+	return fields, nil
+}
+
+func (t *CustomFieldRepository) FindCustomFieldById(id string) (models.CustomFieldSchema, error) {
+	var field models.CustomFieldSchema
+
+	result := t.Database.First(&field, "id = ?", id)
+
+	if result.RowsAffected == 0 {
+		return models.CustomFieldSchema{}, errors.New(utils.CustomFieldNotFound)
+	}
+
+	return field, nil
+}
+
+// The mutations of the custom fields save their events in the outbox with the
+// same transaction, like the tasks.
+
+func (t *CustomFieldRepository) CreateCustomField(field models.CustomFieldSchema, meta events.Meta) (models.CustomFieldSchema, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Create(&field)
+
+		if result.RowsAffected == 0 {
+			return errors.New("custom field not created")
+		}
+
+		return addEntityEvent(tx, events.CustomFieldCreated, field.ID, field.OrganizationId, meta, events.CustomFieldData{
+			CustomField: serializers.NewCustomFieldEvent(field),
+			ActorId:     meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.CustomFieldSchema{}, err
+	}
+
+	return field, nil
+}
+
+func (t *CustomFieldRepository) UpdateCustomField(id string, field models.CustomFieldSchema, meta events.Meta) (models.CustomFieldSchema, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.CustomFieldSchema
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, id)
+
+		if current.ID == 0 {
+			return errors.New(utils.CustomFieldNotFound)
+		}
+
+		field.ID = current.ID
+		field.OrganizationId = current.OrganizationId
+		field.Key = current.Key
+		field.CreatedAt = current.CreatedAt
+
+		result := tx.Save(&field)
+
+		if result.RowsAffected == 0 {
+			return errors.New("custom field not save")
+		}
+
+		return addEntityEvent(tx, events.CustomFieldUpdated, field.ID, field.OrganizationId, meta, events.CustomFieldData{
+			CustomField: serializers.NewCustomFieldEvent(field),
+			ActorId:     meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.CustomFieldSchema{}, err
+	}
+
+	return field, nil
+}
+
+// DeleteCustomField deletes the schema and the values stored under its key in
+// the tasks of the organization, so they can't be filtered or sorted on anymore
+func (t *CustomFieldRepository) DeleteCustomField(id string, meta events.Meta) (int64, error) {
+	var affected int64
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var field models.CustomFieldSchema
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&field, "id = ?", id)
+		if field.ID == 0 {
+			return errors.New(utils.CustomFieldNotFound)
+		}
+
+		path := customFieldPath(field.Key)
+		err := tx.Model(&models.Task{}).
+			Where("organization_id = ? AND JSON_CONTAINS_PATH(custom_fields, 'one', ?)", field.OrganizationId, path).
+			UpdateColumn("custom_fields", gorm.Expr("JSON_REMOVE(custom_fields, ?)", path)).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Delete(&field)
+		if result.RowsAffected == 0 {
+			return errors.New(utils.CustomFieldNotFound)
+		}
+		affected = result.RowsAffected
+
+		return addDeletedEvent(tx, events.CustomFieldDeleted, field.ID, field.OrganizationId, meta)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
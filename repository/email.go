@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// emailSchedulerLock is the name of the MySQL lock held by the scheduler of the overdue and digest emails
+const emailSchedulerLock = "gtasks_email_scheduler"
+
+type IEmailRepository interface {
+	FindOverdueTasks(now time.Time, limit int) ([]models.Task, error)
+	MarkTaskOverdueNotified(taskId uint32, notifiedAt time.Time) error
+	FindDigestUsers() ([]models.User, error)
+	FindEmailDigests(userIds []uint32) ([]models.EmailDigest, error)
+	SaveEmailDigest(digest models.EmailDigest) error
+	FindOpenTasks(userId uint32) ([]models.Task, error)
+	FindSentEmails(eventId string) ([]models.SentEmail, error)
+	SaveSentEmail(sent models.SentEmail) error
+	DeleteSentEmails(before time.Time) (int64, error)
+	WithEmailLock(fn func() error) (bool, error)
+}
+
+type EmailRepository struct {
+	Database *gorm.DB
+}
+
+var EmailRepositoryServices IEmailRepository
+
+func NewEmailRepository() IEmailRepository {
+	return &EmailRepository{Database: database.DB}
+}
+
+// FindOverdueTasks returns the open tasks due before now whose assignee was
+// not told yet, with their assignee, oldest due first
+func (t *EmailRepository) FindOverdueTasks(now time.Time, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+
+	err := t.Database.Preload("User").
+		Where("done = ? AND status <> ? AND due_at <= ? AND overdue_notified_at IS NULL", false, models.TaskStatusPendingReview, now).
+		Order("due_at").
+		Limit(limit).
+		Find(&tasks).Error
+
+	return tasks, err
+}
+
+func (t *EmailRepository) MarkTaskOverdueNotified(taskId uint32, notifiedAt time.Time) error {
+	return t.Database.Model(&models.Task{}).Where("id = ?", taskId).UpdateColumn("overdue_notified_at", notifiedAt).Error
+}
+
+// FindDigestUsers returns the active users with at least one open task
+func (t *EmailRepository) FindDigestUsers() ([]models.User, error) {
+	var users []models.User
+
+	openTasks := t.Database.Model(&models.Task{}).Select("user_id").Where("done = ?", false)
+	err := t.Database.Where("active = ? AND id IN (?)", true, openTasks).Order("id").Find(&users).Error
+
+	return users, err
+}
+
+func (t *EmailRepository) FindEmailDigests(userIds []uint32) ([]models.EmailDigest, error) {
+	var digests []models.EmailDigest
+
+	if len(userIds) == 0 {
+		return digests, nil
+	}
+
+	err := t.Database.Where("user_id IN ?", userIds).Find(&digests).Error
+
+	return digests, err
+}
+
+// SaveEmailDigest records the last digest sent to the user
+func (t *EmailRepository) SaveEmailDigest(digest models.EmailDigest) error {
+	return t.Database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"sent_on", "sent_at"}),
+	}).Create(&digest).Error
+}
+
+// FindOpenTasks returns the tasks of the user that are not done, the ones due
+// first and then the newest
+func (t *EmailRepository) FindOpenTasks(userId uint32) ([]models.Task, error) {
+	var tasks []models.Task
+
+	err := t.Database.
+		Where("user_id = ? AND done = ?", userId, false).
+		Order("due_at IS NULL, due_at, id DESC").
+		Find(&tasks).Error
+
+	return tasks, err
+}
+
+// FindSentEmails returns the users already emailed for the event
+func (t *EmailRepository) FindSentEmails(eventId string) ([]models.SentEmail, error) {
+	var sent []models.SentEmail
+
+	err := t.Database.Where("event_id = ?", eventId).Find(&sent).Error
+
+	return sent, err
+}
+
+// SaveSentEmail records an email of the event sent to the user
+func (t *EmailRepository) SaveSentEmail(sent models.SentEmail) error {
+	return t.Database.Clauses(clause.OnConflict{DoNothing: true}).Create(&sent).Error
+}
+
+// DeleteSentEmails deletes the records of the emails sent before the given time
+func (t *EmailRepository) DeleteSentEmails(before time.Time) (int64, error) {
+	result := t.Database.Where("sent_at < ?", before).Delete(&models.SentEmail{})
+
+	return result.RowsAffected, result.Error
+}
+
+// WithEmailLock runs fn holding the lock of the email scheduler. It returns
+// false without running fn when another instance holds the lock.
+func (t *EmailRepository) WithEmailLock(fn func() error) (bool, error) {
+	var locked bool
+
+	err := t.Database.Connection(func(conn *gorm.DB) error {
+		var acquired *int
+		if err := conn.Raw("SELECT GET_LOCK(?, 0)", emailSchedulerLock).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if acquired == nil || *acquired != 1 {
+			return nil
+		}
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", emailSchedulerLock)
+
+		locked = true
+		return fn()
+	})
+
+	return locked, err
+}
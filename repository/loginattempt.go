@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"gorm.io/gorm"
+)
+
+type ILoginAttemptRepository interface {
+	CreateLoginAttempt(attempt models.LoginAttempt) (models.LoginAttempt, error)
+	CountAccountFailures(email string, ip string, since time.Time) (models.LoginFailures, error)
+	CountEmailFailures(email string, since time.Time) (models.LoginFailures, error)
+	CountIPFailures(ip string, since time.Time) (models.LoginFailures, error)
+}
+
+type LoginAttemptRepository struct {
+	Database *gorm.DB
+}
+
+var LoginAttemptRepositoryServices ILoginAttemptRepository
+
+func NewLoginAttemptRepository() ILoginAttemptRepository {
+	return &LoginAttemptRepository{Database: database.DB}
+}
+
+func (t *LoginAttemptRepository) CreateLoginAttempt(attempt models.LoginAttempt) (models.LoginAttempt, error) {
+	result := t.Database.Create(&attempt)
+
+	if result.RowsAffected == 0 {
+		return models.LoginAttempt{}, errors.New("login attempt not created")
+	}
+
+	return attempt, nil
+}
+
+// CountAccountFailures counts the failures on the email from the ip since the
+// given time, or since the last successful login from the ip when it is later
+func (t *LoginAttemptRepository) CountAccountFailures(email string, ip string, since time.Time) (models.LoginFailures, error) {
+	var failures models.LoginFailures
+
+	lastSuccess := t.Database.Model(&models.LoginAttempt{}).Select("MAX(created_at)").
+		Where("email = ? AND ip = ? AND success = ?", email, ip, true)
+
+	err := t.Database.Model(&models.LoginAttempt{}).Select("COUNT(*) AS count, MAX(created_at) AS last_at").
+		Where("email = ? AND ip = ? AND result IN ? AND created_at >= ?", email, ip, models.LoginFailureResults, since).
+		Where("created_at > COALESCE((?), ?)", lastSuccess, since).
+		Scan(&failures).Error
+
+	return failures, err
+}
+
+// CountEmailFailures counts the failures on the email from every ip since the
+// given time, or since its last successful login when it is later
+func (t *LoginAttemptRepository) CountEmailFailures(email string, since time.Time) (models.LoginFailures, error) {
+	var failures models.LoginFailures
+
+	lastSuccess := t.Database.Model(&models.LoginAttempt{}).Select("MAX(created_at)").
+		Where("email = ? AND success = ?", email, true)
+
+	err := t.Database.Model(&models.LoginAttempt{}).Select("COUNT(*) AS count, MAX(created_at) AS last_at").
+		Where("email = ? AND result IN ? AND created_at >= ?", email, models.LoginFailureResults, since).
+		Where("created_at > COALESCE((?), ?)", lastSuccess, since).
+		Scan(&failures).Error
+
+	return failures, err
+}
+
+// CountIPFailures counts the failures from the ip since the given time.
+// Successful logins from the ip don't reset its failures.
+func (t *LoginAttemptRepository) CountIPFailures(ip string, since time.Time) (models.LoginFailures, error) {
+	var failures models.LoginFailures
+
+	err := t.Database.Model(&models.LoginAttempt{}).Select("COUNT(*) AS count, MAX(created_at) AS last_at").
+		Where("ip = ? AND result IN ? AND created_at >= ?", ip, models.LoginFailureResults, since).
+		Scan(&failures).Error
+
+	return failures, err
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+)
+
+type IMfaRepository interface {
+	SaveMfaSecret(userId uint32, secret string) error
+	EnableMfa(userId uint32, recoveryCodeHashes []string) error
+	DisableMfa(userId uint32) (models.User, error)
+	ReplaceRecoveryCodes(userId uint32, recoveryCodeHashes []string) error
+	UseTotpStep(userId uint32, step int64) (bool, error)
+	UseRecoveryCode(userId uint32, codeHash string) (bool, error)
+}
+
+type MfaRepository struct {
+	Database *gorm.DB
+}
+
+var MfaRepositoryServices IMfaRepository
+
+func NewMfaRepository() IMfaRepository {
+	return &MfaRepository{Database: database.DB}
+}
+
+// SaveMfaSecret starts an enrollment, MFA is enabled once a code of the secret is confirmed
+func (t *MfaRepository) SaveMfaSecret(userId uint32, secret string) error {
+	result := t.Database.Model(&models.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+		"mfa_secret":    secret,
+		"mfa_enabled":   false,
+		"mfa_last_step": 0,
+	})
+
+	if result.RowsAffected == 0 {
+		return errors.New(utils.UserNotFound)
+	}
+
+	return result.Error
+}
+
+func (t *MfaRepository) EnableMfa(userId uint32, recoveryCodeHashes []string) error {
+	return t.Database.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", userId).Update("mfa_enabled", true).Error
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(tx, userId, recoveryCodeHashes)
+	})
+}
+
+// DisableMfa removes the secret and the recovery codes of the user and
+// invalidates the tokens already issued, like a password change
+func (t *MfaRepository) DisableMfa(userId uint32) (models.User, error) {
+	var user models.User
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+			"mfa_secret":    "",
+			"mfa_enabled":   false,
+			"mfa_last_step": 0,
+			"token_version": gorm.Expr("token_version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(utils.UserNotFound)
+		}
+
+		if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.First(&user, "id = ?", userId).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (t *MfaRepository) ReplaceRecoveryCodes(userId uint32, recoveryCodeHashes []string) error {
+	return t.Database.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userId, recoveryCodeHashes)
+	})
+}
+
+// UseTotpStep records the time step of an accepted TOTP code. It reports false
+// when a code of that step (or a later one) was already used.
+func (t *MfaRepository) UseTotpStep(userId uint32, step int64) (bool, error) {
+	result := t.Database.Model(&models.User{}).
+		Where("id = ? AND mfa_last_step < ?", userId, step).
+		Update("mfa_last_step", step)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// UseRecoveryCode consumes a recovery code, it reports false when the code is
+// unknown or already used
+func (t *MfaRepository) UseRecoveryCode(userId uint32, codeHash string) (bool, error) {
+	timeNow := time.Now()
+	result := t.Database.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Limit(1).
+		Update("used_at", &timeNow)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userId uint32, recoveryCodeHashes []string) error {
+	if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]models.RecoveryCode, 0, len(recoveryCodeHashes))
+	for _, hash := range recoveryCodeHashes {
+		codes = append(codes, models.RecoveryCode{UserId: userId, CodeHash: hash})
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type INotificationRepository interface {
+	FindPreferences(userIds []uint32) ([]models.NotificationPreference, error)
+	SavePreference(preference models.NotificationPreference) (models.NotificationPreference, error)
+}
+
+type NotificationRepository struct {
+	Database *gorm.DB
+}
+
+var NotificationRepositoryServices INotificationRepository
+
+func NewNotificationRepository() INotificationRepository {
+	return &NotificationRepository{Database: database.DB}
+}
+
+func (t *NotificationRepository) FindPreferences(userIds []uint32) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+
+	if len(userIds) == 0 {
+		return preferences, nil
+	}
+
+	err := t.Database.Where("user_id IN ?", userIds).Find(&preferences).Error
+	if err != nil {
+		return []models.NotificationPreference{}, err
+	}
+
+	return preferences, nil
+}
+
+func (t *NotificationRepository) SavePreference(preference models.NotificationPreference) (models.NotificationPreference, error) {
+	result := t.Database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&preference)
+
+	if result.Error != nil {
+		return models.NotificationPreference{}, errors.New("notification preference not saved")
+	}
+
+	return preference, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+)
+
+type IOrganizationRepository interface {
+	FindOrganizationById(id uint32) (models.Organization, error)
+	UpdateOrganization(id uint32, organization models.Organization) (models.Organization, error)
+}
+
+type OrganizationRepository struct {
+	Database *gorm.DB
+}
+
+var OrganizationRepositoryServices IOrganizationRepository
+
+func NewOrganizationRepository() IOrganizationRepository {
+	return &OrganizationRepository{Database: database.DB}
+}
+
+func (t *OrganizationRepository) FindOrganizationById(id uint32) (models.Organization, error) {
+	var organization models.Organization
+
+	result := t.Database.First(&organization, "id = ?", id)
+
+	if result.RowsAffected == 0 {
+		return models.Organization{}, errors.New(utils.OrganizationNotFound)
+	}
+
+	return organization, nil
+}
+
+func (t *OrganizationRepository) UpdateOrganization(id uint32, organization models.Organization) (models.Organization, error) {
+	var current models.Organization
+
+	t.Database.First(&current, "id = ?", id)
+
+	if current.ID == 0 {
+		return models.Organization{}, errors.New(utils.OrganizationNotFound)
+	}
+
+	result := t.Database.Model(&current).Select("name", "password_login_disabled").Updates(organization)
+
+	if result.Error != nil {
+		return models.Organization{}, errors.New("organization not save")
+	}
+
+	t.Database.First(&current, "id = ?", id)
+
+	return current, nil
+}
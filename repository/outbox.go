@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"gorm.io/gorm"
+)
+
+// outboxRelayLock is the name of the MySQL lock held by the relay publishing
+// the outbox, so a single instance publishes and the order is kept
+const outboxRelayLock = "gtasks_outbox_relay"
+
+type IOutboxRepository interface {
+	FindPendingOutbox(now time.Time, limit int) ([]models.OutboxMessage, error)
+	MarkOutboxSent(id uint64) error
+	MarkOutboxFailed(id uint64, lastError string, nextAttemptAt time.Time, rejected bool) error
+	MarkOutboxAbandoned(id uint64, lastError string) error
+	DeleteSentOutbox(before time.Time) (int64, error)
+	WithRelayLock(fn func() error) (bool, error)
+}
+
+type OutboxRepository struct {
+	Database *gorm.DB
+}
+
+var OutboxRepositoryServices IOutboxRepository
+
+func NewOutboxRepository() IOutboxRepository {
+	return &OutboxRepository{Database: database.DB}
+}
+
+// FindPendingOutbox returns the messages not sent nor abandoned yet, oldest
+// first. Messages of an aggregate waiting for a retry hold back the later
+// messages of the same aggregate, so the events of a task are published in
+// order.
+//
+// The order is the auto-increment id, i.e. the order of the inserts, not of
+// the commits. It is the order of the changes of an aggregate because every
+// transaction writing an event locks the row of its aggregate (SELECT ... FOR
+// UPDATE) before inserting it; the events of different aggregates may be
+// published in any order.
+func (t *OutboxRepository) FindPendingOutbox(now time.Time, limit int) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+
+	retrying := t.Database.Table("outbox_messages AS retrying").Select("1").
+		Where("retrying.sent_at IS NULL AND retrying.failed_at IS NULL AND retrying.next_attempt_at > ?", now).
+		Where("retrying.aggregate_type = outbox_messages.aggregate_type AND retrying.aggregate_id = outbox_messages.aggregate_id")
+
+	err := t.Database.Where("sent_at IS NULL AND failed_at IS NULL").Where("NOT EXISTS (?)", retrying).
+		Order("id").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (t *OutboxRepository) MarkOutboxSent(id uint64) error {
+	timeNow := time.Now()
+	result := t.Database.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"sent_at":    &timeNow,
+		"last_error": "",
+	})
+
+	if result.Error != nil {
+		return errors.New("outbox message not save")
+	}
+
+	return nil
+}
+
+// MarkOutboxFailed counts a failed attempt, and a rejection when the broker
+// refused the message, and schedules the next one
+func (t *OutboxRepository) MarkOutboxFailed(id uint64, lastError string, nextAttemptAt time.Time, rejected bool) error {
+	if len(lastError) > 500 {
+		lastError = lastError[:500]
+	}
+
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}
+	if rejected {
+		updates["rejections"] = gorm.Expr("rejections + 1")
+	}
+
+	result := t.Database.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(updates)
+
+	if result.Error != nil {
+		return errors.New("outbox message not save")
+	}
+
+	return nil
+}
+
+// MarkOutboxAbandoned counts the last rejected attempt and gives the message up:
+// it is kept for inspection but not published anymore, and no longer holds
+// back the later messages of its aggregate
+func (t *OutboxRepository) MarkOutboxAbandoned(id uint64, lastError string) error {
+	if len(lastError) > 500 {
+		lastError = lastError[:500]
+	}
+
+	timeNow := time.Now()
+	result := t.Database.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"rejections": gorm.Expr("rejections + 1"),
+		"last_error": lastError,
+		"failed_at":  &timeNow,
+	})
+
+	if result.Error != nil {
+		return errors.New("outbox message not save")
+	}
+
+	return nil
+}
+
+// DeleteSentOutbox deletes the messages sent before the given time
+func (t *OutboxRepository) DeleteSentOutbox(before time.Time) (int64, error) {
+	result := t.Database.Where("sent_at IS NOT NULL AND sent_at < ?", before).Delete(&models.OutboxMessage{})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// WithRelayLock runs fn holding the relay lock. It returns false without
+// running fn when another instance holds the lock.
+func (t *OutboxRepository) WithRelayLock(fn func() error) (bool, error) {
+	var locked bool
+
+	err := t.Database.Connection(func(conn *gorm.DB) error {
+		var acquired *int
+		if err := conn.Raw("SELECT GET_LOCK(?, 0)", outboxRelayLock).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if acquired == nil || *acquired != 1 {
+			return nil
+		}
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", outboxRelayLock)
+
+		locked = true
+		return fn()
+	})
+
+	return locked, err
+}
+
+// addOutboxEvent saves an event in the outbox with the transaction of the
+// change it describes
+func addOutboxEvent(tx *gorm.DB, eventType string, aggregateId uint32, subject string, organizationId uint32, meta events.Meta, data interface{}) error {
+	event, err := events.New(eventType, subject, meta.CorrelationId, data)
+	if err != nil {
+		return err
+	}
+	event.OrganizationId = organizationId
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.OutboxMessage{
+		EventId:       event.ID,
+		EventType:     event.Type,
+		AggregateType: events.Aggregate(event.Type),
+		AggregateId:   aggregateId,
+		Payload:       string(payload),
+		NextAttemptAt: event.Time,
+	}).Error
+}
+
+// addEntityEvent saves in the outbox an event about the entity of the given
+// id, its aggregate is the one of the event type, e.g. project for project.updated
+func addEntityEvent(tx *gorm.DB, eventType string, id uint32, organizationId uint32, meta events.Meta, data interface{}) error {
+	return addOutboxEvent(tx, eventType, id, events.Subject(events.Aggregate(eventType), id), organizationId, meta, data)
+}
+
+// addDeletedEvent saves in the outbox the deleted event of the entity
+func addDeletedEvent(tx *gorm.DB, eventType string, id uint32, organizationId uint32, meta events.Meta) error {
+	return addEntityEvent(tx, eventType, id, organizationId, meta, events.DeletedData{
+		ID:             id,
+		OrganizationId: organizationId,
+		ActorId:        meta.ActorId,
+	})
+}
+
+// addTaskEvent saves a task event in the outbox
+func addTaskEvent(tx *gorm.DB, eventType string, task models.Task, meta events.Meta, data interface{}) error {
+	return addOutboxEvent(tx, eventType, task.ID, events.TaskSubject(task.ID), task.OrganizationId, meta, data)
+}
+
+// addTaskUpdated saves task.updated in the outbox when fields of the task changed
+func addTaskUpdated(tx *gorm.DB, current models.Task, updated models.Task, meta events.Meta, review *events.Review) error {
+	changes := taskChanges(current, updated)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	data := events.TaskUpdatedData{
+		Task:    serializers.NewTaskEvent(updated),
+		Changes: changes,
+		ActorId: meta.ActorId,
+		Review:  review,
+	}
+	if current.UserId != updated.UserId {
+		data.PreviousUserId = current.UserId
+	}
+
+	return addTaskEvent(tx, events.TaskUpdated, updated, meta, data)
+}
+
+// addTaskDeleted saves task.deleted in the outbox
+func addTaskDeleted(tx *gorm.DB, task models.Task, meta events.Meta) error {
+	return addTaskEvent(tx, events.TaskDeleted, task, meta, events.TaskDeletedData{
+		TaskId:         task.ID,
+		UserId:         task.UserId,
+		OrganizationId: task.OrganizationId,
+		ActorId:        meta.ActorId,
+	})
+}
+
+// addUserUpdated saves user.updated in the outbox when fields of the user changed
+func addUserUpdated(tx *gorm.DB, current models.User, updated models.User, meta events.Meta) error {
+	changes := userChanges(current, updated)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return addOutboxEvent(tx, events.UserUpdated, updated.ID, events.UserSubject(updated.ID), updated.OrganizationId, meta, events.UserUpdatedData{
+		User:    serializers.NewUserEvent(updated),
+		Changes: changes,
+		Active:  updated.Active,
+		ActorId: meta.ActorId,
+	})
+}
+
+// userChanges lists the json names of the published fields of the user that changed
+func userChanges(current models.User, updated models.User) []string {
+	var changes []string
+	fields := []struct {
+		name    string
+		changed bool
+	}{
+		{"name", current.Name != updated.Name},
+		{"email", current.Email != updated.Email},
+		{"is_manager", current.IsManager != updated.IsManager},
+		{"active", current.Active != updated.Active},
+		{"timezone", current.Timezone != updated.Timezone},
+		{"locale", current.Locale != updated.Locale},
+		{"avatar_url", current.AvatarUrl != updated.AvatarUrl},
+	}
+	for _, field := range fields {
+		if field.changed {
+			changes = append(changes, field.name)
+		}
+	}
+
+	return changes
+}
+
+// taskChanges lists the json names of the fields of the task that changed
+func taskChanges(current models.Task, updated models.Task) []string {
+	var changes []string
+	fields := []struct {
+		name    string
+		changed bool
+	}{
+		{"title", current.Title != updated.Title},
+		{"summary", current.Summary != updated.Summary},
+		{"user_id", current.UserId != updated.UserId},
+		{"project_id", !equalUint32Ptr(current.ProjectId, updated.ProjectId)},
+		{"done", current.Done != updated.Done},
+		{"status", current.Status != updated.Status},
+		{"requires_approval", current.RequiresApproval != updated.RequiresApproval},
+		{"priority", current.Priority != updated.Priority},
+		{"tags", !equalStrings(current.Tags, updated.Tags)},
+		{"checklist", !equalChecklists(current.Checklist, updated.Checklist)},
+		{"estimated_minutes", current.EstimatedMinutes != updated.EstimatedMinutes},
+		{"custom_fields", !equalCustomFields(current.CustomFields, updated.CustomFields)},
+		{"finished_at", !equalTimePtr(current.FinishedAt, updated.FinishedAt)},
+		{"due_at", !equalTimePtr(current.DueAt, updated.DueAt)},
+	}
+	for _, field := range fields {
+		if field.changed {
+			changes = append(changes, field.name)
+		}
+	}
+
+	return changes
+}
+
+func equalUint32Ptr(a *uint32, b *uint32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTimePtr(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func equalChecklists(a models.Checklist, b models.Checklist) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalCustomFields compares the values as saved, so 5 and 5.0 are the same
+func equalCustomFields(a models.CustomFields, b models.CustomFields) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aJSON) == string(bJSON)
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	current := models.Task{
+		Title:            "Test Title",
+		Checklist:        models.Checklist{{Text: "check the pump", Done: false}},
+		EstimatedMinutes: 30,
+		CustomFields:     models.CustomFields{"floor": float64(2), "contract": "A-12"},
+	}
+
+	t.Run("Success: no change", func(t *testing.T) {
+		updated := current
+		updated.Checklist = models.Checklist{{Text: "check the pump", Done: false}}
+		updated.CustomFields = models.CustomFields{"contract": "A-12", "floor": 2}
+
+		// asserts
+		assert.Empty(taskChanges(current, updated))
+	})
+
+	t.Run("Success: checklist changed", func(t *testing.T) {
+		updated := current
+		updated.Checklist = models.Checklist{{Text: "check the pump", Done: true}}
+
+		// asserts
+		assert.Equal([]string{"checklist"}, taskChanges(current, updated))
+
+		updated.Checklist = nil
+		assert.Equal([]string{"checklist"}, taskChanges(current, updated))
+	})
+
+	t.Run("Success: estimated minutes changed", func(t *testing.T) {
+		updated := current
+		updated.EstimatedMinutes = 45
+
+		// asserts
+		assert.Equal([]string{"estimated_minutes"}, taskChanges(current, updated))
+	})
+
+	t.Run("Success: custom fields changed", func(t *testing.T) {
+		updated := current
+		updated.CustomFields = models.CustomFields{"floor": float64(3), "contract": "A-12"}
+
+		// asserts
+		assert.Equal([]string{"custom_fields"}, taskChanges(current, updated))
+
+		updated.CustomFields = nil
+		assert.Equal([]string{"custom_fields"}, taskChanges(current, updated))
+	})
+}
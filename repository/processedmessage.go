@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IProcessedMessageRepository interface {
+	ClaimMessage(queue string, messageId string, leaseUntil time.Time) (string, error)
+	CompleteMessage(queue string, messageId string, expiresAt time.Time) error
+	ReleaseMessage(queue string, messageId string) error
+	DeleteExpiredMessages(before time.Time) (int64, error)
+}
+
+type ProcessedMessageRepository struct {
+	Database *gorm.DB
+}
+
+var ProcessedMessageRepositoryServices IProcessedMessageRepository
+
+func NewProcessedMessageRepository() IProcessedMessageRepository {
+	return &ProcessedMessageRepository{Database: database.DB}
+}
+
+// ClaimMessage leases the message to the caller until leaseUntil and returns
+// models.MessageClaimed. It returns models.MessageProcessed when the message
+// was already processed and models.MessageInProgress when another consumer
+// holds its lease. Expired records are claimed again.
+func (t *ProcessedMessageRepository) ClaimMessage(queue string, messageId string, leaseUntil time.Time) (string, error) {
+	result := models.MessageClaimed
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.ProcessedMessage
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("queue = ? AND message_id = ?", queue, messageId).
+			Limit(1).Find(&current).Error
+		if err != nil {
+			return err
+		}
+
+		if current.MessageId == "" {
+			err := tx.Create(&models.ProcessedMessage{Queue: queue, MessageId: messageId, ExpiresAt: leaseUntil}).Error
+			if isDuplicateKey(err) {
+				result = models.MessageInProgress
+				return nil
+			}
+			return err
+		}
+
+		if current.ExpiresAt.After(time.Now()) {
+			result = models.MessageInProgress
+			if current.Processed {
+				result = models.MessageProcessed
+			}
+			return nil
+		}
+
+		return tx.Model(&current).
+			Where("queue = ? AND message_id = ?", queue, messageId).
+			Updates(map[string]interface{}{"processed": false, "expires_at": leaseUntil}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+// CompleteMessage marks the claimed message processed until expiresAt
+func (t *ProcessedMessageRepository) CompleteMessage(queue string, messageId string, expiresAt time.Time) error {
+	return t.Database.Model(&models.ProcessedMessage{}).
+		Where("queue = ? AND message_id = ?", queue, messageId).
+		Updates(map[string]interface{}{"processed": true, "expires_at": expiresAt}).Error
+}
+
+// ReleaseMessage gives up the lease of a message that failed, so it can be processed again
+func (t *ProcessedMessageRepository) ReleaseMessage(queue string, messageId string) error {
+	return t.Database.
+		Where("queue = ? AND message_id = ? AND processed = ?", queue, messageId, false).
+		Delete(&models.ProcessedMessage{}).Error
+}
+
+// DeleteExpiredMessages deletes the records expired before the given time
+func (t *ProcessedMessageRepository) DeleteExpiredMessages(before time.Time) (int64, error) {
+	result := t.Database.Where("expires_at < ?", before).Delete(&models.ProcessedMessage{})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
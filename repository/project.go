@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IProjectRepository interface {
+	FindProjects(organizationId uint32, siteId uint32) ([]models.Project, error)
+	FindProjectById(id string) (models.Project, error)
+	FindProjectSummary(id string) (models.ProjectSummary, error)
+	CreateProject(project models.Project, meta events.Meta) (models.Project, error)
+	UpdateProject(id string, project models.Project, meta events.Meta) (models.Project, error)
+	DeleteProject(id string, cascade bool, meta events.Meta) (int64, error)
+}
+
+type ProjectRepository struct {
+	Database *gorm.DB
+}
+
+var ProjectRepositoryServices IProjectRepository
+
+func NewProjectRepository() IProjectRepository {
+	return &ProjectRepository{Database: database.DB}
+}
+
+// FindProjects lists the projects of the organization, optionally of a single site
+func (t *ProjectRepository) FindProjects(organizationId uint32, siteId uint32) ([]models.Project, error) {
+	var projects []models.Project
+
+	query := t.Database.Where("organization_id = ?", organizationId)
+	if siteId != 0 {
+		query = query.Where("site_id = ?", siteId)
+	}
+
+	err := query.Order("name").Find(&projects).Error
+	if err != nil {
+		return []models.Project{}, err
+	}
+
+	return projects, nil
+}
+
+func (t *ProjectRepository) FindProjectById(id string) (models.Project, error) {
+	var project models.Project
+
+	result := t.Database.Preload("Site.Customer").First(&project, "id = ?", id)
+
+	if result.RowsAffected == 0 {
+		return models.Project{}, errors.New(utils.ProjectNotFound)
+	}
+
+	return project, nil
+}
+
+func (t *ProjectRepository) FindProjectSummary(id string) (models.ProjectSummary, error) {
+	var project models.Project
+	var summary models.ProjectSummary
+
+	t.Database.First(&project, "id = ?", id)
+	if project.ID == 0 {
+		return models.ProjectSummary{}, errors.New(utils.ProjectNotFound)
+	}
+
+	err := t.Database.Model(&models.Task{}).
+		Select(`? AS project_id,
+			COALESCE(SUM(CASE WHEN done THEN 0 ELSE 1 END), 0) AS open_tasks,
+			COALESCE(SUM(CASE WHEN done THEN 1 ELSE 0 END), 0) AS done_tasks,
+			COALESCE(SUM(estimated_minutes), 0) / 60 AS estimated_hours,
+			COALESCE(SUM(CASE WHEN done THEN estimated_minutes ELSE 0 END), 0) / 60 AS done_hours`, project.ID).
+		Where("project_id = ?", project.ID).
+		Scan(&summary).Error
+	if err != nil {
+		return models.ProjectSummary{}, err
+	}
+
+	return summary, nil
+}
+
+// The mutations of the projects save their events in the outbox with the
+// same transaction, like the tasks.
+
+func (t *ProjectRepository) CreateProject(project models.Project, meta events.Meta) (models.Project, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Site").Create(&project)
+
+		if result.RowsAffected == 0 {
+			return errors.New("project not created")
+		}
+
+		return addEntityEvent(tx, events.ProjectCreated, project.ID, project.OrganizationId, meta, events.ProjectData{
+			Project: serializers.NewProjectEvent(project),
+			ActorId: meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+func (t *ProjectRepository) UpdateProject(id string, project models.Project, meta events.Meta) (models.Project, error) {
+	var current models.Project
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, id)
+
+		if current.ID == 0 {
+			return errors.New(utils.ProjectNotFound)
+		}
+
+		current.Name = project.Name
+
+		result := tx.Save(&current)
+
+		if result.RowsAffected == 0 {
+			return errors.New("project not save")
+		}
+
+		return addEntityEvent(tx, events.ProjectUpdated, current.ID, current.OrganizationId, meta, events.ProjectData{
+			Project: serializers.NewProjectEvent(current),
+			ActorId: meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	return current, nil
+}
+
+// DeleteProject deletes a project. A project with tasks is only deleted, with
+// its tasks and their watchers and reviews, when cascade is set. The deleted
+// tasks are published as deleted.
+func (t *ProjectRepository) DeleteProject(id string, cascade bool, meta events.Meta) (int64, error) {
+	var affected int64
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		var tasks []models.Task
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, "id = ?", id)
+		if project.ID == 0 {
+			return errors.New(utils.ProjectNotFound)
+		}
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", project.ID).Find(&tasks)
+		if len(tasks) > 0 && !cascade {
+			return utils.ErrProjectHasTasks
+		}
+
+		taskIds := make([]uint32, len(tasks))
+		for i, task := range tasks {
+			taskIds[i] = task.ID
+		}
+		if err := deleteTaskRows(tx, taskIds); err != nil {
+			return err
+		}
+
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.Task{}).Error; err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			if err := addTaskDeleted(tx, task, meta); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Delete(&project)
+		if result.RowsAffected == 0 {
+			return errors.New(utils.ProjectNotFound)
+		}
+
+		affected = result.RowsAffected
+		return addDeletedEvent(tx, events.ProjectDeleted, project.ID, project.OrganizationId, meta)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ISiteRepository interface {
+	FindSites(organizationId uint32, customerId uint32) ([]models.Site, error)
+	FindSiteById(id string) (models.Site, error)
+	CreateSite(site models.Site, meta events.Meta) (models.Site, error)
+	UpdateSite(id string, site models.Site, meta events.Meta) (models.Site, error)
+	DeleteSite(id string, meta events.Meta) (int64, error)
+}
+
+type SiteRepository struct {
+	Database *gorm.DB
+}
+
+var SiteRepositoryServices ISiteRepository
+
+func NewSiteRepository() ISiteRepository {
+	return &SiteRepository{Database: database.DB}
+}
+
+// FindSites lists the sites of the organization, optionally of a single customer
+func (t *SiteRepository) FindSites(organizationId uint32, customerId uint32) ([]models.Site, error) {
+	var sites []models.Site
+
+	query := t.Database.Where("organization_id = ?", organizationId)
+	if customerId != 0 {
+		query = query.Where("customer_id = ?", customerId)
+	}
+
+	err := query.Order("name").Find(&sites).Error
+	if err != nil {
+		return []models.Site{}, err
+	}
+
+	return sites, nil
+}
+
+func (t *SiteRepository) FindSiteById(id string) (models.Site, error) {
+	var site models.Site
+
+	result := t.Database.Preload("Customer").First(&site, "id = ?", id)
+
+	if result.RowsAffected == 0 {
+		return models.Site{}, errors.New(utils.SiteNotFound)
+	}
+
+	return site, nil
+}
+
+// The mutations of the sites save their events in the outbox with the same
+// transaction, like the tasks.
+
+func (t *SiteRepository) CreateSite(site models.Site, meta events.Meta) (models.Site, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Customer").Create(&site)
+
+		if result.RowsAffected == 0 {
+			return errors.New("site not created")
+		}
+
+		return addEntityEvent(tx, events.SiteCreated, site.ID, site.OrganizationId, meta, events.SiteData{
+			Site:    serializers.NewSiteEvent(site),
+			ActorId: meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.Site{}, err
+	}
+
+	return site, nil
+}
+
+func (t *SiteRepository) UpdateSite(id string, site models.Site, meta events.Meta) (models.Site, error) {
+	var current models.Site
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, id)
+
+		if current.ID == 0 {
+			return errors.New(utils.SiteNotFound)
+		}
+
+		current.Name = site.Name
+		current.Address = site.Address
+
+		result := tx.Save(&current)
+
+		if result.RowsAffected == 0 {
+			return errors.New("site not save")
+		}
+
+		return addEntityEvent(tx, events.SiteUpdated, current.ID, current.OrganizationId, meta, events.SiteData{
+			Site:    serializers.NewSiteEvent(current),
+			ActorId: meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.Site{}, err
+	}
+
+	return current, nil
+}
+
+// DeleteSite deletes a site without projects
+func (t *SiteRepository) DeleteSite(id string, meta events.Meta) (int64, error) {
+	var affected int64
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var site models.Site
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&site, "id = ?", id)
+		if site.ID == 0 {
+			return errors.New(utils.SiteNotFound)
+		}
+
+		var projects int64
+
+		tx.Model(&models.Project{}).Where("site_id = ?", site.ID).Count(&projects)
+		if projects > 0 {
+			return utils.ErrSiteHasProjects
+		}
+
+		result := tx.Delete(&site)
+		if result.RowsAffected == 0 {
+			return errors.New(utils.SiteNotFound)
+		}
+		affected = result.RowsAffected
+
+		return addDeletedEvent(tx, events.SiteDeleted, site.ID, site.OrganizationId, meta)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
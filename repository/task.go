@@ -2,21 +2,26 @@ package repository
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
 	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
 	"github.com/hugohenrick/gtasks/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ITaskRepository interface {
-	FindTasks(task models.Task) ([]models.Task, error)
+	FindTasks(filter models.TaskFilter) ([]models.Task, error)
 	FindTaskById(id string) (models.Task, error)
-	CreateTask(task models.Task) (models.Task, error)
-	UpdateTask(id string, task models.Task) (models.Task, error)
-	DeleteTask(id string) (int64, error)
-	ExecuteTask(id string, task models.Task) (models.Task, error)
+	CreateTask(task models.Task, meta events.Meta) (models.Task, error)
+	UpdateTask(id string, task models.Task, meta events.Meta) (models.Task, error)
+	DeleteTask(id string, meta events.Meta) (int64, error)
+	ExecuteTask(id string, task models.Task, meta events.Meta) (models.Task, error)
+	ReviewTask(id string, review models.TaskReview, meta events.Meta) (models.Task, error)
 }
 
 type TaskRepository struct {
@@ -29,10 +34,33 @@ func NewTaskRepository() ITaskRepository {
 	return &TaskRepository{Database: database.DB}
 }
 
-func (t *TaskRepository) FindTasks(task models.Task) ([]models.Task, error) {
+func (t *TaskRepository) FindTasks(filter models.TaskFilter) ([]models.Task, error) {
 	var tasks []models.Task
 
-	result := t.Database.Preload("User").Find(&tasks, task)
+	query := t.Database.Preload("User").Where("organization_id = ?", filter.OrganizationId).Where(models.Task{UserId: filter.UserId})
+
+	if filter.ProjectId != 0 {
+		query = query.Where("project_id = ?", filter.ProjectId)
+	}
+
+	if filter.SiteId != 0 {
+		query = query.Where("project_id IN (?)", t.Database.Model(&models.Project{}).Select("id").Where("site_id = ?", filter.SiteId))
+	}
+
+	if filter.CustomerId != 0 {
+		sites := t.Database.Model(&models.Site{}).Select("id").Where("customer_id = ?", filter.CustomerId)
+		query = query.Where("project_id IN (?)", t.Database.Model(&models.Project{}).Select("id").Where("site_id IN (?)", sites))
+	}
+
+	for key, value := range filter.CustomFields {
+		query = query.Where("JSON_UNQUOTE(JSON_EXTRACT(custom_fields, ?)) = ?", customFieldPath(key), value)
+	}
+
+	if filter.SortBy != "" {
+		query = query.Clauses(taskOrder(filter.SortBy, filter.SortDesc))
+	}
+
+	result := query.Find(&tasks)
 
 	if result.RowsAffected == 0 {
 		return []models.Task{}, errors.New("task data not found")
@@ -53,65 +81,246 @@ func (t *TaskRepository) FindTaskById(id string) (models.Task, error) {
 	return task, nil
 }
 
-func (t *TaskRepository) CreateTask(task models.Task) (models.Task, error) {
-	result := t.Database.Create(&task)
+// The mutations of the tasks save their events in the outbox with the same
+// transaction, the outbox relay publishes them.
 
-	if result.RowsAffected == 0 {
-		return models.Task{}, errors.New("task not created")
+func (t *TaskRepository) CreateTask(task models.Task, meta events.Meta) (models.Task, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Create(&task)
+
+		if result.RowsAffected == 0 {
+			return errors.New("task not created")
+		}
+
+		return addTaskEvent(tx, events.TaskCreated, task, meta, events.TaskCreatedData{
+			Task:    serializers.NewTaskEvent(task),
+			ActorId: meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.Task{}, err
 	}
 
 	return task, nil
 }
 
-func (t *TaskRepository) UpdateTask(id string, task models.Task) (models.Task, error) {
-	t.Database.First(&task, id)
+func (t *TaskRepository) UpdateTask(id string, task models.Task, meta events.Meta) (models.Task, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.Task
 
-	if task.ID == 0 {
-		return models.Task{}, errors.New(utils.TaskNotFound)
-	}
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, id)
 
-	result := t.Database.Save(&task)
+		if current.ID == 0 {
+			return errors.New(utils.TaskNotFound)
+		}
 
-	if result.RowsAffected == 0 {
-		return models.Task{}, errors.New("task not save")
+		task = mergeTaskUpdate(current, task)
+
+		result := tx.Omit(clause.Associations).Save(&task)
+
+		if result.RowsAffected == 0 {
+			return errors.New("task not save")
+		}
+
+		return addTaskUpdated(tx, current, task, meta, nil)
+	})
+	if err != nil {
+		return models.Task{}, err
 	}
 
 	return task, nil
 }
 
-func (t *TaskRepository) DeleteTask(id string) (int64, error) {
-	var deletedTask models.Task
+// mergeTaskUpdate keeps the fields of the current task an update cannot change
+// and derives the status from done
+func mergeTaskUpdate(current models.Task, task models.Task) models.Task {
+	task.ID = current.ID
+	task.OrganizationId = current.OrganizationId
+	task.TemplateVersionId = current.TemplateVersionId
+	task.CreatedAt = current.CreatedAt
+	// a new due date may become overdue again
+	if equalTimePtr(current.DueAt, task.DueAt) {
+		task.OverdueNotifiedAt = current.OverdueNotifiedAt
+	}
 
-	t.Database.First(&deletedTask, id)
-	if deletedTask.ID == 0 {
-		return 0, errors.New(utils.TaskNotFound)
+	switch {
+	case task.Done:
+		task.Status = models.TaskStatusDone
+	case current.Status == models.TaskStatusDone:
+		task.Status = models.TaskStatusOpen
+	default:
+		task.Status = current.Status
 	}
 
-	result := t.Database.Where("id = ?", id).Delete(&deletedTask)
+	return task
+}
 
-	if result.RowsAffected == 0 {
-		return 0, errors.New("task not deleted")
+func (t *TaskRepository) DeleteTask(id string, meta events.Meta) (int64, error) {
+	var affected int64
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var deletedTask models.Task
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deletedTask, id)
+		if deletedTask.ID == 0 {
+			return errors.New(utils.TaskNotFound)
+		}
+
+		if err := deleteTaskRows(tx, []uint32{deletedTask.ID}); err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ?", deletedTask.ID).Delete(&models.Task{})
+
+		if result.RowsAffected == 0 {
+			return errors.New("task not deleted")
+		}
+		affected = result.RowsAffected
+
+		return addTaskDeleted(tx, deletedTask, meta)
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return result.RowsAffected, nil
+	return affected, nil
+}
+
+// ExecuteTask finishes the task: done, or pending review when it requires
+// approval. Done tasks publish task.executed, the others task.updated.
+func (t *TaskRepository) ExecuteTask(id string, task models.Task, meta events.Meta) (models.Task, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.Task
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, id)
+
+		if current.ID == 0 {
+			return errors.New(utils.TaskNotFound)
+		}
+
+		task = current
+		if task.RequiresApproval {
+			task.Status = models.TaskStatusPendingReview
+		} else {
+			task.Done = true
+			task.Status = models.TaskStatusDone
+		}
+		timeNow := time.Now()
+		task.FinishedAt = &timeNow
+
+		result := tx.Omit(clause.Associations).Save(&task)
+
+		if result.RowsAffected == 0 {
+			return errors.New("task not save")
+		}
+
+		if task.RequiresApproval {
+			return addTaskUpdated(tx, current, task, meta, nil)
+		}
+
+		return addTaskEvent(tx, events.TaskExecuted, task, meta, events.TaskExecutedData{
+			Task:         serializers.NewTaskEvent(task),
+			TechnicianId: task.UserId,
+			ExecutedAt:   timeNow,
+		})
+	})
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// customFieldPath returns the JSON path of a custom field key. Keys are
+// validated with utils.ValidCustomFieldKey before reaching the repository.
+func customFieldPath(key string) string {
+	return `$."` + key + `"`
 }
 
-func (t *TaskRepository) ExecuteTask(id string, task models.Task) (models.Task, error) {
-	t.Database.First(&task, id)
+// taskOrder builds the ORDER BY clause for a task column or a custom field
+// prefixed with "cf.".
+func taskOrder(sortBy string, desc bool) clause.OrderBy {
+	var column clause.Expression
+	if strings.HasPrefix(sortBy, models.CustomFieldSortPrefix) {
+		column = clause.Expr{SQL: "JSON_EXTRACT(custom_fields, ?)", Vars: []interface{}{customFieldPath(strings.TrimPrefix(sortBy, models.CustomFieldSortPrefix))}}
+	} else {
+		column = clause.Expr{SQL: "?", Vars: []interface{}{clause.Column{Name: sortBy}}}
+	}
 
-	if task.ID == 0 {
-		return models.Task{}, errors.New(utils.TaskNotFound)
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
 	}
 
-	task.Done = true
-	timeNow := time.Now()
-	task.FinishedAt = &timeNow
+	return clause.OrderBy{Expression: clause.Expr{SQL: "? " + direction, Vars: []interface{}{column}}}
+}
 
-	result := t.Database.Save(&task)
+func (t *TaskRepository) ReviewTask(id string, review models.TaskReview, meta events.Meta) (models.Task, error) {
+	var task models.Task
 
-	if result.RowsAffected == 0 {
-		return models.Task{}, errors.New("task not save")
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.Task
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("User").First(&task, "id = ?", id)
+
+		if task.ID == 0 {
+			return errors.New(utils.TaskNotFound)
+		}
+
+		if task.Status != models.TaskStatusPendingReview {
+			return errors.New(utils.TaskNotPendingReview)
+		}
+
+		review.TaskId = task.ID
+		if err := tx.Create(&review).Error; err != nil {
+			return errors.New("task review not created")
+		}
+		current = task
+
+		if review.Approved {
+			task.Done = true
+			task.Status = models.TaskStatusDone
+		} else {
+			task.Status = models.TaskStatusRejected
+			task.FinishedAt = nil
+		}
+
+		if err := tx.Omit(clause.Associations).Save(&task).Error; err != nil {
+			return err
+		}
+
+		decision := &events.Review{ReviewerId: review.ReviewerId, Approved: review.Approved, Comment: review.Comment}
+		if !review.Approved {
+			return addTaskUpdated(tx, current, task, meta, decision)
+		}
+
+		executed := events.TaskExecutedData{
+			Task:         serializers.NewTaskEvent(task),
+			TechnicianId: task.UserId,
+			Review:       decision,
+		}
+		if task.FinishedAt != nil {
+			executed.ExecutedAt = *task.FinishedAt
+		}
+		return addTaskEvent(tx, events.TaskExecuted, task, meta, executed)
+	})
+	if err != nil {
+		return models.Task{}, err
 	}
 
 	return task, nil
 }
+
+// deleteTaskRows deletes the rows referencing the tasks, their watchers and
+// reviews, with the transaction deleting the tasks
+func deleteTaskRows(tx *gorm.DB, taskIds []uint32) error {
+	if len(taskIds) == 0 {
+		return nil
+	}
+
+	if err := tx.Where("task_id IN ?", taskIds).Delete(&models.TaskWatcher{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("task_id IN ?", taskIds).Delete(&models.TaskReview{}).Error
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITemplateRepository interface {
+	FindTemplates(organizationId uint32) ([]models.TaskTemplate, error)
+	FindTemplateById(id string) (models.TaskTemplate, error)
+	FindLatestTemplateVersion(templateId string) (models.TaskTemplateVersion, error)
+	CreateTemplate(template models.TaskTemplate, meta events.Meta) (models.TaskTemplate, error)
+	CreateTemplateVersion(templateId string, version models.TaskTemplateVersion, meta events.Meta) (models.TaskTemplateVersion, error)
+}
+
+type TemplateRepository struct {
+	Database *gorm.DB
+}
+
+var TemplateRepositoryServices ITemplateRepository
+
+func NewTemplateRepository() ITemplateRepository {
+	return &TemplateRepository{Database: database.DB}
+}
+
+func (t *TemplateRepository) FindTemplates(organizationId uint32) ([]models.TaskTemplate, error) {
+	var templates []models.TaskTemplate
+
+	err := t.Database.Where("organization_id = ?", organizationId).Order("name").Find(&templates).Error
+	if err != nil {
+		return []models.TaskTemplate{}, err
+	}
+
+	return templates, nil
+}
+
+func (t *TemplateRepository) FindTemplateById(id string) (models.TaskTemplate, error) {
+	var template models.TaskTemplate
+
+	result := t.Database.Preload("Versions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("version DESC")
+	}).First(&template, "id = ?", id)
+
+	if result.RowsAffected == 0 {
+		return models.TaskTemplate{}, errors.New(utils.TemplateNotFound)
+	}
+
+	return template, nil
+}
+
+func (t *TemplateRepository) FindLatestTemplateVersion(templateId string) (models.TaskTemplateVersion, error) {
+	var version models.TaskTemplateVersion
+
+	result := t.Database.Where("template_id = ?", templateId).Order("version DESC").First(&version)
+
+	if result.RowsAffected == 0 {
+		return models.TaskTemplateVersion{}, errors.New(utils.TemplateNotFound)
+	}
+
+	return version, nil
+}
+
+// The mutations of the templates save their events in the outbox with the
+// same transaction, like the tasks.
+
+func (t *TemplateRepository) CreateTemplate(template models.TaskTemplate, meta events.Meta) (models.TaskTemplate, error) {
+	template.LatestVersion = uint32(len(template.Versions))
+	for i := range template.Versions {
+		template.Versions[i].Version = uint32(i + 1)
+	}
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Create(&template)
+
+		if result.RowsAffected == 0 {
+			return errors.New("template not created")
+		}
+
+		return addEntityEvent(tx, events.TemplateCreated, template.ID, template.OrganizationId, meta, events.TemplateData{
+			Template: serializers.NewTemplateEvent(template),
+			ActorId:  meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.TaskTemplate{}, err
+	}
+
+	return template, nil
+}
+
+// CreateTemplateVersion saves a new version of the template, published as template.updated
+func (t *TemplateRepository) CreateTemplateVersion(templateId string, version models.TaskTemplateVersion, meta events.Meta) (models.TaskTemplateVersion, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var template models.TaskTemplate
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, "id = ?", templateId)
+		if template.ID == 0 {
+			return errors.New(utils.TemplateNotFound)
+		}
+
+		version.ID = 0
+		version.TemplateId = template.ID
+		version.Version = template.LatestVersion + 1
+
+		if err := tx.Create(&version).Error; err != nil {
+			return errors.New("template version not created")
+		}
+
+		if err := tx.Model(&template).Update("latest_version", version.Version).Error; err != nil {
+			return err
+		}
+
+		return addEntityEvent(tx, events.TemplateUpdated, template.ID, template.OrganizationId, meta, events.TemplateData{
+			Template: serializers.NewTemplateEvent(template),
+			ActorId:  meta.ActorId,
+		})
+	})
+	if err != nil {
+		return models.TaskTemplateVersion{}, err
+	}
+
+	return version, nil
+}
@@ -2,16 +2,32 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
 	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/hugohenrick/gtasks/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IUserRepository interface {
-	FindUsers() ([]models.User, error)
+	FindUsers(organizationId *uint32) ([]models.User, error)
+	FindUserById(id string) (models.User, error)
 	FindUserByEmail(email string) (models.User, error)
-	CreateUser(User models.User) (models.User, error)
+	FindUserByOidcSubject(subject string) (models.User, error)
+	CreateUser(User models.User, meta events.Meta) (models.User, error)
+	UpdateUser(id string, user models.User, meta events.Meta) (models.User, error)
+	UpdateProfile(id string, user models.User, meta events.Meta) (models.User, error)
+	UpdatePassword(id string, password string) (models.User, error)
+	UpdatePasswordHash(id string, password string) error
+	VerifyEmail(id string) (models.User, error)
+	SaveOidcLogin(id string, subject string, isManager bool, meta events.Meta) (models.User, error)
+	DeleteUser(id string, meta events.Meta) (int64, error)
+	DeactivateUser(id string, reassignTo uint32, meta events.Meta) ([]models.Task, error)
+	ActivateUser(id string, meta events.Meta) (models.User, error)
 }
 
 type UserRepository struct {
@@ -24,10 +40,16 @@ func NewUserRepository() IUserRepository {
 	return &UserRepository{Database: database.DB}
 }
 
-func (t *UserRepository) FindUsers() ([]models.User, error) {
+// FindUsers returns the users of the organization, of every organization when
+// organizationId is nil
+func (t *UserRepository) FindUsers(organizationId *uint32) ([]models.User, error) {
 	var users []models.User
 
-	result := database.DB.Find(&users)
+	query := t.Database
+	if organizationId != nil {
+		query = query.Where("organization_id = ?", *organizationId)
+	}
+	result := query.Find(&users)
 
 	if result.RowsAffected == 0 {
 		return []models.User{}, errors.New("user data not found")
@@ -36,6 +58,18 @@ func (t *UserRepository) FindUsers() ([]models.User, error) {
 	return users, nil
 }
 
+func (t *UserRepository) FindUserById(id string) (models.User, error) {
+	var user models.User
+
+	result := t.Database.First(&user, "id = ?", id)
+
+	if result.RowsAffected == 0 {
+		return models.User{}, errors.New(utils.UserNotFound)
+	}
+
+	return user, nil
+}
+
 func (t *UserRepository) FindUserByEmail(email string) (models.User, error) {
 	var user models.User
 
@@ -48,12 +82,364 @@ func (t *UserRepository) FindUserByEmail(email string) (models.User, error) {
 	return user, nil
 }
 
-func (t *UserRepository) CreateUser(user models.User) (models.User, error) {
-	result := t.Database.Create(&user)
+func (t *UserRepository) FindUserByOidcSubject(subject string) (models.User, error) {
+	var user models.User
+
+	err := t.Database.Where("oidc_subject = ?", subject).First(&user).Error
 
-	if result.RowsAffected == 0 {
-		return models.User{}, errors.New("user not created")
+	if err != nil {
+		return models.User{}, errors.New(utils.UserNotFound)
+	}
+
+	return user, nil
+}
+
+// CreateUser saves the user and its user.created event in the outbox, in one transaction
+func (t *UserRepository) CreateUser(user models.User, meta events.Meta) (models.User, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Create(&user)
+
+		if isDuplicateKey(result.Error) {
+			return utils.ErrEmailTaken
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("user not created")
+		}
+
+		origin := events.UserOriginSignup
+		if user.OidcSubject != nil {
+			origin = events.UserOriginSso
+		}
+
+		return addOutboxEvent(tx, events.UserCreated, user.ID, events.UserSubject(user.ID), user.OrganizationId, meta, events.UserCreatedData{
+			User:   serializers.NewUserEvent(user),
+			Origin: origin,
+		})
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// The changes of the published fields of the users save user.updated in the
+// outbox with the same transaction, like the tasks.
+
+// UpdateUser saves the profile fields of the user, credentials are not changed
+func (t *UserRepository) UpdateUser(id string, user models.User, meta events.Meta) (models.User, error) {
+	var updated models.User
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.User
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", id)
+
+		if current.ID == 0 {
+			return errors.New(utils.UserNotFound)
+		}
+
+		// a new email is not verified, and the links sent to the previous one stop verifying it
+		columns := []string{"name", "email", "is_manager"}
+		emailChanged := user.Email != current.Email
+		if emailChanged {
+			user.EmailVerified = false
+			columns = append(columns, "email_verified")
+		}
+
+		result := tx.Model(&models.User{ID: current.ID}).Select(columns).Updates(user)
+
+		if isDuplicateKey(result.Error) {
+			return utils.ErrEmailTaken
+		}
+
+		if result.Error != nil {
+			return errors.New("user not save")
+		}
+
+		if emailChanged {
+			timeNow := time.Now()
+			err := tx.Model(&models.UserToken{}).
+				Where("user_id = ? AND purpose = ? AND used_at IS NULL", current.ID, models.UserTokenEmailVerification).
+				Update("used_at", &timeNow).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		tx.First(&updated, "id = ?", id)
+
+		return addUserUpdated(tx, current, updated, meta)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return updated, nil
+}
+
+// UpdateProfile saves the fields users edit on their own profile
+func (t *UserRepository) UpdateProfile(id string, user models.User, meta events.Meta) (models.User, error) {
+	var updated models.User
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.User
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", id)
+
+		if current.ID == 0 {
+			return errors.New(utils.UserNotFound)
+		}
+
+		result := tx.Model(&models.User{ID: current.ID}).Select("name", "timezone", "locale", "avatar_url").Updates(user)
+
+		if result.Error != nil {
+			return errors.New("user not save")
+		}
+
+		tx.First(&updated, "id = ?", id)
+
+		return addUserUpdated(tx, current, updated, meta)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return updated, nil
+}
+
+// UpdatePassword saves the password hash, invalidates the tokens already issued
+// and revokes the api tokens of the user
+func (t *UserRepository) UpdatePassword(id string, password string) (models.User, error) {
+	var user models.User
+
+	t.Database.First(&user, "id = ?", id)
+
+	if user.ID == 0 {
+		return models.User{}, errors.New(utils.UserNotFound)
+	}
+
+	// the api tokens are revoked with the sessions, they would keep the access
+	// of whoever learned the previous password
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&user).Updates(map[string]interface{}{
+			"password":      password,
+			"token_version": gorm.Expr("token_version + 1"),
+		})
+
+		if result.Error != nil {
+			return errors.New("user not save")
+		}
+
+		timeNow := time.Now()
+		return tx.Model(&models.ApiToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", &timeNow).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	t.Database.First(&user, "id = ?", id)
+
+	return user, nil
+}
+
+// UpdatePasswordHash replaces the hash of the same password, e.g. made with
+// outdated parameters. The tokens already issued keep working.
+func (t *UserRepository) UpdatePasswordHash(id string, password string) error {
+	result := t.Database.Model(&models.User{}).Where("id = ?", id).Update("password", password)
+
+	if result.Error != nil {
+		return errors.New("user not save")
 	}
 
+	return nil
+}
+
+func (t *UserRepository) VerifyEmail(id string) (models.User, error) {
+	var user models.User
+
+	t.Database.First(&user, "id = ?", id)
+
+	if user.ID == 0 {
+		return models.User{}, errors.New(utils.UserNotFound)
+	}
+
+	result := t.Database.Model(&user).Update("email_verified", true)
+
+	if result.Error != nil {
+		return models.User{}, errors.New("user not save")
+	}
+	user.EmailVerified = true
+
 	return user, nil
 }
+
+// SaveOidcLogin links the user to its identity provider subject and applies
+// the role mapped from the groups of the identity provider
+func (t *UserRepository) SaveOidcLogin(id string, subject string, isManager bool, meta events.Meta) (models.User, error) {
+	var updated models.User
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.User
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", id)
+
+		if current.ID == 0 {
+			return errors.New(utils.UserNotFound)
+		}
+
+		result := tx.Model(&models.User{ID: current.ID}).Updates(map[string]interface{}{
+			"oidc_subject":   subject,
+			"is_manager":     isManager,
+			"email_verified": true,
+		})
+
+		if isDuplicateKey(result.Error) {
+			return errors.New("identity provider subject already linked to another user")
+		}
+
+		if result.Error != nil {
+			return errors.New("user not save")
+		}
+
+		tx.First(&updated, "id = ?", id)
+
+		return addUserUpdated(tx, current, updated, meta)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return updated, nil
+}
+
+// DeleteUser deletes a user without tasks. Users with tasks must be deactivated.
+func (t *UserRepository) DeleteUser(id string, meta events.Meta) (int64, error) {
+	var affected int64
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", id)
+		if user.ID == 0 {
+			return errors.New(utils.UserNotFound)
+		}
+
+		var tasks int64
+
+		tx.Model(&models.Task{}).Where("user_id = ?", user.ID).Count(&tasks)
+		if tasks > 0 {
+			return utils.ErrUserHasTasks
+		}
+
+		result := tx.Delete(&user)
+
+		if result.RowsAffected == 0 {
+			return errors.New(utils.UserNotFound)
+		}
+		affected = result.RowsAffected
+
+		return addDeletedEvent(tx, events.UserDeleted, user.ID, user.OrganizationId, meta)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// DeactivateUser refuses new logins of the user, invalidates the tokens already
+// issued and, when reassignTo is set, moves the open tasks of the user to
+// reassignTo. The user is published as updated, the reassigned tasks are
+// returned and published as updated.
+func (t *UserRepository) DeactivateUser(id string, reassignTo uint32, meta events.Meta) ([]models.Task, error) {
+	var reassigned []models.Task
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", id)
+		if user.ID == 0 {
+			return errors.New(utils.UserNotFound)
+		}
+
+		current := user
+		timeNow := time.Now()
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"active":         false,
+			"token_version":  gorm.Expr("token_version + 1"),
+			"deactivated_at": &timeNow,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		deactivated := current
+		deactivated.Active = false
+		if err := addUserUpdated(tx, current, deactivated, meta); err != nil {
+			return err
+		}
+
+		if reassignTo == 0 {
+			return nil
+		}
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ? AND done = ?", user.ID, false).Find(&reassigned)
+		if len(reassigned) == 0 {
+			return nil
+		}
+
+		err = tx.Model(&models.Task{}).Where("user_id = ? AND done = ?", user.ID, false).Update("user_id", reassignTo).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range reassigned {
+			current := reassigned[i]
+			reassigned[i].UserId = reassignTo
+			if err := addTaskUpdated(tx, current, reassigned[i], meta, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reassigned, nil
+}
+
+func (t *UserRepository) ActivateUser(id string, meta events.Meta) (models.User, error) {
+	var updated models.User
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var current models.User
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", id)
+
+		if current.ID == 0 {
+			return errors.New(utils.UserNotFound)
+		}
+
+		result := tx.Model(&models.User{ID: current.ID}).Updates(map[string]interface{}{
+			"active":         true,
+			"deactivated_at": nil,
+		})
+
+		if result.Error != nil {
+			return errors.New("user not save")
+		}
+
+		tx.First(&updated, "id = ?", id)
+
+		return addUserUpdated(tx, current, updated, meta)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// testDatabase opens the MySQL database of TEST_DB_DSN migrated with the
+// models, the tests needing it are skipped when it is not set
+func testDatabase(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN not set")
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn))
+	if err != nil {
+		t.Fatalf("error connecting to the test database: %s", err)
+	}
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("error migrating the test database: %s", err)
+	}
+
+	return db
+}
+
+func TestUpdatePassword(t *testing.T) {
+	assert := assert.New(t)
+	db := testDatabase(t)
+
+	// every change is rolled back
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	suffix := time.Now().Format("150405.000000")
+	user := models.User{Name: "Tech", Email: "password-" + suffix + "@gtasks.com", Password: "x", OrganizationId: 1, Active: true}
+	assert.Nil(tx.Create(&user).Error)
+
+	revokedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	active := models.ApiToken{UserId: user.ID, Name: "ci", Prefix: "gtk_pwd1", TokenHash: "password-active-" + suffix, Scopes: "task:read"}
+	revoked := models.ApiToken{UserId: user.ID, Name: "old", Prefix: "gtk_pwd2", TokenHash: "password-revoked-" + suffix, Scopes: "task:read", RevokedAt: &revokedAt}
+	assert.Nil(tx.Create(&active).Error)
+	assert.Nil(tx.Create(&revoked).Error)
+
+	updated, err := (&UserRepository{Database: tx}).UpdatePassword(fmt.Sprint(user.ID), "new hash")
+
+	var tokens []models.ApiToken
+	tx.Where("user_id = ?", user.ID).Order("id").Find(&tokens)
+
+	// asserts
+	assert.Nil(err)
+	assert.Equal("new hash", updated.Password)
+	assert.Equal(user.TokenVersion+1, updated.TokenVersion)
+	assert.Len(tokens, 2)
+	assert.NotNil(tokens[0].RevokedAt)
+	assert.True(revokedAt.Equal(*tokens[1].RevokedAt))
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IUserTokenRepository interface {
+	CreateToken(token models.UserToken) (models.UserToken, error)
+	ConsumeToken(tokenHash string, purpose string) (models.UserToken, error)
+}
+
+type UserTokenRepository struct {
+	Database *gorm.DB
+}
+
+var UserTokenRepositoryServices IUserTokenRepository
+
+func NewUserTokenRepository() IUserTokenRepository {
+	return &UserTokenRepository{Database: database.DB}
+}
+
+// CreateToken saves a token, the unused tokens with the same purpose issued
+// before to the user are discarded
+func (t *UserTokenRepository) CreateToken(token models.UserToken) (models.UserToken, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		timeNow := time.Now()
+		err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserId, token.Purpose).
+			Update("used_at", &timeNow).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&token).Error
+	})
+	if err != nil {
+		return models.UserToken{}, errors.New("token not created")
+	}
+
+	return token, nil
+}
+
+// ConsumeToken marks the token as used. Tokens already used, expired or issued
+// for another purpose are refused.
+func (t *UserTokenRepository) ConsumeToken(tokenHash string, purpose string) (models.UserToken, error) {
+	var token models.UserToken
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND purpose = ?", tokenHash, purpose).
+			First(&token)
+
+		if token.ID == 0 || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+			return errors.New(utils.UserTokenInvalid)
+		}
+
+		timeNow := time.Now()
+		token.UsedAt = &timeNow
+		return tx.Model(&token).Update("used_at", &timeNow).Error
+	})
+	if err != nil {
+		return models.UserToken{}, err
+	}
+
+	return token, nil
+}
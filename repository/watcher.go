@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWatcherRepository interface {
+	FindWatchers(taskId string) ([]models.TaskWatcher, error)
+	AddWatcher(watcher models.TaskWatcher, meta events.Meta) (models.TaskWatcher, error)
+	RemoveWatcher(taskId string, userId uint32, meta events.Meta) (int64, error)
+}
+
+type WatcherRepository struct {
+	Database *gorm.DB
+}
+
+var WatcherRepositoryServices IWatcherRepository
+
+func NewWatcherRepository() IWatcherRepository {
+	return &WatcherRepository{Database: database.DB}
+}
+
+func (t *WatcherRepository) FindWatchers(taskId string) ([]models.TaskWatcher, error) {
+	var watchers []models.TaskWatcher
+
+	err := t.Database.Preload("User").Where("task_id = ?", taskId).Find(&watchers).Error
+	if err != nil {
+		return []models.TaskWatcher{}, err
+	}
+
+	return watchers, nil
+}
+
+// The watchers save their events in the outbox with the same transaction. The
+// aggregate of the watcher events is the watched task, whose row is locked so
+// the events of its watchers are published in order.
+
+// AddWatcher saves the watcher, a user already watching the task is returned
+// without a new event
+func (t *WatcherRepository) AddWatcher(watcher models.TaskWatcher, meta events.Meta) (models.TaskWatcher, error) {
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, "id = ?", watcher.TaskId)
+		if task.ID == 0 {
+			return errors.New(utils.TaskNotFound)
+		}
+
+		var existing models.TaskWatcher
+
+		tx.Where("task_id = ? AND user_id = ?", watcher.TaskId, watcher.UserId).First(&existing)
+		if existing.ID != 0 {
+			watcher = existing
+			return nil
+		}
+
+		result := tx.Create(&watcher)
+
+		if result.RowsAffected == 0 {
+			return errors.New("watcher not created")
+		}
+
+		return addWatcherEvent(tx, events.WatcherAdded, task, watcher.UserId, meta)
+	})
+	if err != nil {
+		return models.TaskWatcher{}, err
+	}
+
+	return watcher, nil
+}
+
+func (t *WatcherRepository) RemoveWatcher(taskId string, userId uint32, meta events.Meta) (int64, error) {
+	var affected int64
+
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, "id = ?", taskId)
+		if task.ID == 0 {
+			return errors.New(utils.TaskNotWatched)
+		}
+
+		result := tx.Where("task_id = ? AND user_id = ?", task.ID, userId).Delete(&models.TaskWatcher{})
+
+		if result.RowsAffected == 0 {
+			return errors.New(utils.TaskNotWatched)
+		}
+		affected = result.RowsAffected
+
+		return addWatcherEvent(tx, events.WatcherRemoved, task, userId, meta)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// addWatcherEvent saves watcher.added or watcher.removed in the outbox
+func addWatcherEvent(tx *gorm.DB, eventType string, task models.Task, userId uint32, meta events.Meta) error {
+	return addOutboxEvent(tx, eventType, task.ID, events.TaskSubject(task.ID), task.OrganizationId, meta, events.WatcherData{
+		Watcher: events.Watcher{TaskId: task.ID, UserId: userId},
+		ActorId: meta.ActorId,
+	})
+}
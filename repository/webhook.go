@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hugohenrick/gtasks/database"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webhookWorkerLock is the name of the MySQL lock held by the worker delivering the webhooks
+const webhookWorkerLock = "gtasks_webhook_worker"
+
+type IWebhookRepository interface {
+	FindWebhooks(organizationId uint32) ([]models.Webhook, error)
+	FindWebhookById(id string, organizationId uint32) (models.Webhook, error)
+	FindSubscribedWebhooks(organizationId uint32, eventType string) ([]models.Webhook, error)
+	CreateWebhook(webhook models.Webhook) (models.Webhook, error)
+	UpdateWebhook(webhook models.Webhook) (models.Webhook, error)
+	DeleteWebhook(id string, organizationId uint32) error
+	FindWebhookDeliveries(webhookId uint32, limit int) ([]models.WebhookDelivery, error)
+}
+
+type IWebhookDeliveryRepository interface {
+	CreateWebhookDeliveries(deliveries []models.WebhookDelivery) error
+	FindPendingWebhookDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error)
+	MarkWebhookDelivered(id uint64, responseStatus int) error
+	MarkWebhookDeliveryFailed(id uint64, responseStatus int, lastError string, nextAttemptAt *time.Time) error
+	RecordWebhookResult(webhookId uint32, succeeded bool, disableAfter uint32) (bool, error)
+	DeleteFinishedWebhookDeliveries(before time.Time) (int64, error)
+	WithWebhookLock(fn func() error) (bool, error)
+}
+
+type WebhookRepository struct {
+	Database *gorm.DB
+}
+
+var WebhookRepositoryServices IWebhookRepository
+
+var WebhookDeliveryRepositoryServices IWebhookDeliveryRepository
+
+func NewWebhookRepository() IWebhookRepository {
+	return &WebhookRepository{Database: database.DB}
+}
+
+func NewWebhookDeliveryRepository() IWebhookDeliveryRepository {
+	return &WebhookRepository{Database: database.DB}
+}
+
+// FindWebhooks returns the webhooks of the organization
+func (t *WebhookRepository) FindWebhooks(organizationId uint32) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+
+	err := t.Database.Where("organization_id = ?", organizationId).Order("id").Find(&webhooks).Error
+
+	return webhooks, err
+}
+
+func (t *WebhookRepository) FindWebhookById(id string, organizationId uint32) (models.Webhook, error) {
+	var webhook models.Webhook
+
+	t.Database.First(&webhook, "id = ? AND organization_id = ?", id, organizationId)
+
+	if webhook.ID == 0 {
+		return models.Webhook{}, errors.New(utils.WebhookNotFound)
+	}
+
+	return webhook, nil
+}
+
+// FindSubscribedWebhooks returns the active webhooks of the organization subscribed to the event type
+func (t *WebhookRepository) FindSubscribedWebhooks(organizationId uint32, eventType string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+
+	err := t.Database.
+		Where("organization_id = ? AND active = ? AND FIND_IN_SET(?, event_types) > 0", organizationId, true, eventType).
+		Find(&webhooks).Error
+
+	return webhooks, err
+}
+
+func (t *WebhookRepository) CreateWebhook(webhook models.Webhook) (models.Webhook, error) {
+	result := t.Database.Create(&webhook)
+
+	if result.RowsAffected == 0 {
+		return models.Webhook{}, errors.New("webhook not created")
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook saves the url, event types and state of the webhook
+func (t *WebhookRepository) UpdateWebhook(webhook models.Webhook) (models.Webhook, error) {
+	result := t.Database.Model(&webhook).
+		Select("url", "event_types", "active", "consecutive_failures", "disabled_at").
+		Updates(webhook)
+
+	if result.Error != nil {
+		return models.Webhook{}, errors.New("webhook not updated")
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook deletes the webhook of the organization with its deliveries
+func (t *WebhookRepository) DeleteWebhook(id string, organizationId uint32) error {
+	return t.Database.Transaction(func(tx *gorm.DB) error {
+		var webhook models.Webhook
+		tx.First(&webhook, "id = ? AND organization_id = ?", id, organizationId)
+
+		if webhook.ID == 0 {
+			return errors.New(utils.WebhookNotFound)
+		}
+
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&webhook).Error
+	})
+}
+
+// FindWebhookDeliveries returns the latest deliveries of the webhook, newest first
+func (t *WebhookRepository) FindWebhookDeliveries(webhookId uint32, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := t.Database.Where("webhook_id = ?", webhookId).Order("id DESC").Limit(limit).Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+// CreateWebhookDeliveries saves pending deliveries, an event already saved for
+// a webhook is skipped
+func (t *WebhookRepository) CreateWebhookDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return t.Database.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(&deliveries).Error
+}
+
+// FindPendingWebhookDeliveries returns the pending deliveries of active
+// webhooks that are due, with their webhook, oldest first
+func (t *WebhookRepository) FindPendingWebhookDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := t.Database.
+		Joins("Webhook").
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ? AND Webhook.active = ?", models.WebhookDeliveryPending, now, true).
+		Order("webhook_deliveries.id").
+		Limit(limit).
+		Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+func (t *WebhookRepository) MarkWebhookDelivered(id uint64, responseStatus int) error {
+	timeNow := time.Now()
+
+	return t.Database.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          models.WebhookDeliverySucceeded,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": responseStatus,
+		"last_error":      "",
+		"delivered_at":    &timeNow,
+	}).Error
+}
+
+// MarkWebhookDeliveryFailed records a failed attempt, the delivery is retried
+// at nextAttemptAt or fails for good when it is nil
+func (t *WebhookRepository) MarkWebhookDeliveryFailed(id uint64, responseStatus int, lastError string, nextAttemptAt *time.Time) error {
+	if len(lastError) > 500 {
+		lastError = lastError[:500]
+	}
+
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": responseStatus,
+		"last_error":      lastError,
+	}
+	if nextAttemptAt != nil {
+		updates["next_attempt_at"] = *nextAttemptAt
+	} else {
+		updates["status"] = models.WebhookDeliveryFailed
+	}
+
+	return t.Database.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// RecordWebhookResult resets the consecutive failures of the webhook after a
+// success, or counts a failure and disables the webhook when they reach
+// disableAfter. It reports whether the webhook was disabled.
+func (t *WebhookRepository) RecordWebhookResult(webhookId uint32, succeeded bool, disableAfter uint32) (bool, error) {
+	if succeeded {
+		return false, t.Database.Model(&models.Webhook{}).Where("id = ?", webhookId).Update("consecutive_failures", 0).Error
+	}
+
+	disabled := false
+	err := t.Database.Transaction(func(tx *gorm.DB) error {
+		var webhook models.Webhook
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&webhook, webhookId)
+
+		if webhook.ID == 0 {
+			return errors.New(utils.WebhookNotFound)
+		}
+
+		updates := map[string]interface{}{"consecutive_failures": webhook.ConsecutiveFailures + 1}
+		if webhook.Active && webhook.ConsecutiveFailures+1 >= disableAfter {
+			timeNow := time.Now()
+			updates["active"] = false
+			updates["disabled_at"] = &timeNow
+			disabled = true
+		}
+
+		return tx.Model(&webhook).Updates(updates).Error
+	})
+
+	return disabled, err
+}
+
+// DeleteFinishedWebhookDeliveries deletes the deliveries that succeeded or
+// failed for good before the given time
+func (t *WebhookRepository) DeleteFinishedWebhookDeliveries(before time.Time) (int64, error) {
+	result := t.Database.
+		Where("status <> ? AND updated_at < ?", models.WebhookDeliveryPending, before).
+		Delete(&models.WebhookDelivery{})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// WithWebhookLock runs fn holding the lock of the webhook worker. It returns
+// false without running fn when another instance holds the lock.
+func (t *WebhookRepository) WithWebhookLock(fn func() error) (bool, error) {
+	var locked bool
+
+	err := t.Database.Connection(func(conn *gorm.DB) error {
+		var acquired *int
+		if err := conn.Raw("SELECT GET_LOCK(?, 0)", webhookWorkerLock).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if acquired == nil || *acquired != 1 {
+			return nil
+		}
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", webhookWorkerLock)
+
+		locked = true
+		return fn()
+	})
+
+	return locked, err
+}
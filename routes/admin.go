@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/controllers"
+)
+
+// AddAdminRoutes adds the administration routes of the message broker to gin router
+func AddAdminRoutes(router *gin.Engine) {
+	router.GET("/admin/dead-letters/:queue", controllers.GetDeadLetters)
+	router.POST("/admin/dead-letters/:queue/replay", controllers.ReplayDeadLetters)
+	router.DELETE("/admin/dead-letters/:queue", controllers.PurgeDeadLetters)
+	router.GET("/admin/consumers", controllers.GetConsumerStats)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/controllers"
+)
+
+// AddCustomFieldRoutes adds task custom field schema routes to gin router
+func AddCustomFieldRoutes(router *gin.Engine) {
+	router.GET("/custom-field", controllers.GetCustomFields)
+	router.POST("/custom-field", controllers.CreateCustomField)
+	router.PATCH("/custom-field/:id", controllers.UpdateCustomField)
+	router.DELETE("/custom-field/:id", controllers.DeleteCustomField)
+}
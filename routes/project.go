@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/controllers"
+)
+
+// AddProjectRoutes adds customers, sites and projects routes to gin router
+func AddProjectRoutes(router *gin.Engine) {
+	router.GET("/customer", controllers.GetCustomers)
+	router.GET("/customer/:id", controllers.GetCustomerById)
+	router.POST("/customer", controllers.CreateCustomer)
+	router.PATCH("/customer/:id", controllers.UpdateCustomer)
+	router.DELETE("/customer/:id", controllers.DeleteCustomer)
+
+	router.GET("/site", controllers.GetSites)
+	router.GET("/site/:id", controllers.GetSiteById)
+	router.POST("/site", controllers.CreateSite)
+	router.PATCH("/site/:id", controllers.UpdateSite)
+	router.DELETE("/site/:id", controllers.DeleteSite)
+
+	router.GET("/project", controllers.GetProjects)
+	router.GET("/project/:id", controllers.GetProjectById)
+	router.GET("/project/:id/summary", controllers.GetProjectSummary)
+	router.POST("/project", controllers.CreateProject)
+	router.PATCH("/project/:id", controllers.UpdateProject)
+	router.DELETE("/project/:id", controllers.DeleteProject)
+}
@@ -8,9 +8,14 @@ import (
 // AddTaskRoutes adds tasks routes to gin router
 func AddTaskRoutes(router *gin.Engine) {
 	router.GET("/task", controllers.GetTasks)
+	router.GET("/task/stream", controllers.StreamTasks)
 	router.GET("/task/:id", controllers.GetTaskById)
 	router.POST("/task", controllers.CreateTask)
 	router.PATCH("/task/execute/:id", controllers.ExecuteTask)
+	router.POST("/task/:id/review", controllers.ReviewTask)
 	router.PATCH("/task/:id", controllers.UpdateTask)
 	router.DELETE("/task/:id", controllers.DeleteTask)
+	router.GET("/task/:id/watchers", controllers.GetTaskWatchers)
+	router.POST("/task/:id/watch", controllers.WatchTask)
+	router.DELETE("/task/:id/watch", controllers.UnwatchTask)
 }
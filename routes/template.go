@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/controllers"
+)
+
+// AddTemplateRoutes adds task template routes to gin router
+func AddTemplateRoutes(router *gin.Engine) {
+	router.GET("/template", controllers.GetTemplates)
+	router.GET("/template/:id", controllers.GetTemplateById)
+	router.POST("/template", controllers.CreateTemplate)
+	router.PATCH("/template/:id", controllers.UpdateTemplate)
+	router.POST("/task/from-template/:templateId", controllers.CreateTaskFromTemplate)
+}
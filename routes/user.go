@@ -3,12 +3,37 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/hugohenrick/gtasks/controllers"
-	"github.com/hugohenrick/gtasks/middlewares"
 )
 
 // AddUserRoutes adds users routes to gin router
 func AddUserRoutes(router *gin.Engine) {
-	router.GET("/user", middlewares.Authenticate(), controllers.GetUsers)
+	router.GET("/user", controllers.GetUsers)
 	router.POST("/user", controllers.CreateUser)
 	router.POST("/user/login", controllers.LoginUser)
+	router.POST("/user/login/mfa", controllers.LoginMfa)
+	router.GET("/user/login/oidc", controllers.LoginOidc)
+	router.GET("/user/login/oidc/callback", controllers.LoginOidcCallback)
+	router.POST("/user/password/forgot", controllers.ForgotPassword)
+	router.POST("/user/password/reset", controllers.ResetPassword)
+	router.POST("/user/email/verify", controllers.VerifyEmail)
+	router.GET("/user/notification-preferences", controllers.GetNotificationPreferences)
+	router.PUT("/user/notification-preferences", controllers.UpdateNotificationPreferences)
+	router.GET("/user/me", controllers.GetMe)
+	router.PATCH("/user/me", controllers.UpdateMe)
+	router.POST("/user/me/password", controllers.ChangeMyPassword)
+	router.POST("/user/me/mfa/enroll", controllers.EnrollMfa)
+	router.POST("/user/me/mfa/confirm", controllers.ConfirmMfa)
+	router.POST("/user/me/mfa/disable", controllers.DisableMfa)
+	router.POST("/user/me/mfa/recovery-codes", controllers.RegenerateRecoveryCodes)
+	router.GET("/user/me/tokens", controllers.GetApiTokens)
+	router.POST("/user/me/tokens", controllers.CreateApiToken)
+	router.DELETE("/user/me/tokens/:id", controllers.RevokeApiToken)
+	router.GET("/user/:id", controllers.GetUserById)
+	router.PATCH("/user/:id", controllers.UpdateUser)
+	router.DELETE("/user/:id", controllers.DeleteUser)
+	router.POST("/user/:id/deactivate", controllers.DeactivateUser)
+	router.POST("/user/:id/activate", controllers.ActivateUser)
+
+	router.GET("/organization", controllers.GetOrganization)
+	router.PATCH("/organization", controllers.UpdateOrganization)
 }
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hugohenrick/gtasks/controllers"
+)
+
+// AddWebhookRoutes adds the webhook subscription routes to gin router
+func AddWebhookRoutes(router *gin.Engine) {
+	router.GET("/webhook", controllers.GetWebhooks)
+	router.POST("/webhook", controllers.CreateWebhook)
+	router.PATCH("/webhook/:id", controllers.UpdateWebhook)
+	router.DELETE("/webhook/:id", controllers.DeleteWebhook)
+	router.GET("/webhook/:id/deliveries", controllers.GetWebhookDeliveries)
+}
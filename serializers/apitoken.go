@@ -0,0 +1,39 @@
+package serializers
+
+import (
+	"time"
+
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// ApiTokenResponse is the public representation of an api token, the token
+// itself is only returned when it is created
+type ApiTokenResponse struct {
+	ID         uint32     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+}
+
+func NewApiTokenResponse(token models.ApiToken) ApiTokenResponse {
+	return ApiTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.ScopeList(),
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func NewApiTokenListResponse(tokens []models.ApiToken) []ApiTokenResponse {
+	response := make([]ApiTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, NewApiTokenResponse(token))
+	}
+	return response
+}
@@ -0,0 +1,102 @@
+package serializers
+
+import (
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// NewTaskEvent maps a task to the representation published in the task events
+func NewTaskEvent(task models.Task) events.Task {
+	return events.Task{
+		ID:               task.ID,
+		Title:            task.Title,
+		Summary:          task.Summary,
+		UserId:           task.UserId,
+		OrganizationId:   task.OrganizationId,
+		ProjectId:        task.ProjectId,
+		Done:             task.Done,
+		Status:           task.Status,
+		RequiresApproval: task.RequiresApproval,
+		Priority:         task.Priority,
+		Tags:             []string(task.Tags),
+		DueAt:            task.DueAt,
+		CreatedAt:        task.CreatedAt,
+		UpdatedAt:        task.UpdatedAt,
+		FinishedAt:       task.FinishedAt,
+	}
+}
+
+// NewUserEvent maps a user to the representation published in the user events
+func NewUserEvent(user models.User) events.User {
+	return events.User{
+		ID:             user.ID,
+		Name:           user.Name,
+		Email:          user.Email,
+		IsManager:      user.IsManager,
+		OrganizationId: user.OrganizationId,
+		CreatedAt:      user.CreatedAt,
+	}
+}
+
+// NewCustomerEvent maps a customer to the representation published in the customer events
+func NewCustomerEvent(customer models.Customer) events.Customer {
+	return events.Customer{
+		ID:             customer.ID,
+		OrganizationId: customer.OrganizationId,
+		Name:           customer.Name,
+		CreatedAt:      customer.CreatedAt,
+		UpdatedAt:      customer.UpdatedAt,
+	}
+}
+
+// NewSiteEvent maps a site to the representation published in the site events
+func NewSiteEvent(site models.Site) events.Site {
+	return events.Site{
+		ID:             site.ID,
+		OrganizationId: site.OrganizationId,
+		CustomerId:     site.CustomerId,
+		Name:           site.Name,
+		Address:        site.Address,
+		CreatedAt:      site.CreatedAt,
+		UpdatedAt:      site.UpdatedAt,
+	}
+}
+
+// NewProjectEvent maps a project to the representation published in the project events
+func NewProjectEvent(project models.Project) events.Project {
+	return events.Project{
+		ID:             project.ID,
+		OrganizationId: project.OrganizationId,
+		SiteId:         project.SiteId,
+		Name:           project.Name,
+		CreatedAt:      project.CreatedAt,
+		UpdatedAt:      project.UpdatedAt,
+	}
+}
+
+// NewTemplateEvent maps a task template to the representation published in the template events
+func NewTemplateEvent(template models.TaskTemplate) events.Template {
+	return events.Template{
+		ID:             template.ID,
+		OrganizationId: template.OrganizationId,
+		Name:           template.Name,
+		LatestVersion:  template.LatestVersion,
+		CreatedAt:      template.CreatedAt,
+	}
+}
+
+// NewCustomFieldEvent maps a custom field schema to the representation published in the custom field events
+func NewCustomFieldEvent(field models.CustomFieldSchema) events.CustomField {
+	return events.CustomField{
+		ID:             field.ID,
+		OrganizationId: field.OrganizationId,
+		Key:            field.Key,
+		Label:          field.Label,
+		Type:           field.Type,
+		Required:       field.Required,
+		Pattern:        field.Pattern,
+		Options:        []string(field.Options),
+		CreatedAt:      field.CreatedAt,
+		UpdatedAt:      field.UpdatedAt,
+	}
+}
@@ -0,0 +1,93 @@
+package serializers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ParseFields parses the comma separated ?fields= query parameter
+func ParseFields(raw string) []string {
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// SelectFields keeps only the given JSON fields of a response (or of each item
+// of a list of responses). Fields unknown to the response type are an error.
+// Without fields the response is returned untouched.
+func SelectFields(data interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+
+	known := jsonFields(reflect.TypeOf(data))
+	for _, field := range fields {
+		if !known[field] {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+
+	switch value := decoded.(type) {
+	case []interface{}:
+		for i, item := range value {
+			if object, ok := item.(map[string]interface{}); ok {
+				value[i] = pick(object, fields)
+			}
+		}
+		return value, nil
+	case map[string]interface{}:
+		return pick(value, fields), nil
+	}
+	return decoded, nil
+}
+
+func pick(object map[string]interface{}, fields []string) map[string]interface{} {
+	selected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := object[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected
+}
+
+// jsonFields returns the JSON names of the fields of a struct type, or of the
+// element type of a slice of structs
+func jsonFields(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	if t == nil {
+		return names
+	}
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return names
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		names[name] = true
+	}
+	return names
+}
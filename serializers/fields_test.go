@@ -0,0 +1,89 @@
+package serializers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/serializers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUserResponse(t *testing.T) {
+	assert := assert.New(t)
+
+	user := models.User{ID: 1, Name: "Tech", Email: "tech@gtasks.com", Password: "$2a$14$hash", TokenVersion: 3}
+
+	raw, err := json.Marshal(serializers.NewUserResponse(user))
+
+	// asserts
+	assert.Nil(err)
+	assert.NotContains(string(raw), "password")
+	assert.NotContains(string(raw), "$2a$14$hash")
+	assert.NotContains(string(raw), "token_version")
+}
+
+func TestNewTaskResponse(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: preloaded user without credentials", func(t *testing.T) {
+		task := models.Task{ID: 1, Title: "Task", UserId: 2, User: models.User{ID: 2, Name: "Tech", Password: "$2a$14$hash"}}
+
+		raw, err := json.Marshal(serializers.NewTaskResponse(task))
+
+		// asserts
+		assert.Nil(err)
+		assert.Contains(string(raw), `"user":{"id":2`)
+		assert.NotContains(string(raw), "$2a$14$hash")
+	})
+
+	t.Run("Success: user not preloaded is omitted", func(t *testing.T) {
+		raw, err := json.Marshal(serializers.NewTaskResponse(models.Task{ID: 1, UserId: 2}))
+
+		// asserts
+		assert.Nil(err)
+		assert.NotContains(string(raw), `"user"`)
+	})
+}
+
+func TestSelectFields(t *testing.T) {
+	assert := assert.New(t)
+
+	users := serializers.NewUserListResponse([]models.User{
+		{ID: 1, Name: "Manager", Email: "manager@gtasks.com", IsManager: true},
+		{ID: 2, Name: "Tech", Email: "tech@gtasks.com"},
+	})
+
+	t.Run("Success: list with selected fields", func(t *testing.T) {
+		selected, err := serializers.SelectFields(users, serializers.ParseFields("id, name"))
+		raw, _ := json.Marshal(selected)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(`[{"id":1,"name":"Manager"},{"id":2,"name":"Tech"}]`, string(raw))
+	})
+
+	t.Run("Success: single response with selected fields", func(t *testing.T) {
+		selected, err := serializers.SelectFields(users[1], []string{"email"})
+		raw, _ := json.Marshal(selected)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(`{"email":"tech@gtasks.com"}`, string(raw))
+	})
+
+	t.Run("Success: without fields the response is untouched", func(t *testing.T) {
+		selected, err := serializers.SelectFields(users, serializers.ParseFields(""))
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(users, selected)
+	})
+
+	t.Run("Failed: unknown field", func(t *testing.T) {
+		_, err := serializers.SelectFields(users, []string{"password"})
+
+		// asserts
+		assert.EqualError(err, `unknown field "password"`)
+	})
+}
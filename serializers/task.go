@@ -0,0 +1,100 @@
+package serializers
+
+import (
+	"time"
+
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// TaskResponse is the public representation of a task
+type TaskResponse struct {
+	ID                uint32                 `json:"id"`
+	Title             string                 `json:"title"`
+	Summary           string                 `json:"summary"`
+	UserId            uint32                 `json:"user_id"`
+	OrganizationId    uint32                 `json:"organization_id"`
+	ProjectId         *uint32                `json:"project_id,omitempty"`
+	Done              bool                   `json:"done"`
+	Status            string                 `json:"status"`
+	RequiresApproval  bool                   `json:"requires_approval"`
+	User              *UserResponse          `json:"user,omitempty"`
+	Priority          string                 `json:"priority,omitempty"`
+	Tags              []string               `json:"tags,omitempty"`
+	Checklist         []models.ChecklistItem `json:"checklist,omitempty"`
+	EstimatedMinutes  uint32                 `json:"estimated_minutes,omitempty"`
+	TemplateVersionId *uint32                `json:"template_version_id,omitempty"`
+	CustomFields      map[string]interface{} `json:"custom_fields,omitempty"`
+	DueAt             *time.Time             `json:"due_at,omitempty"`
+	CreatedAt         time.Time              `json:"created_at,omitempty"`
+	UpdatedAt         time.Time              `json:"updated_at,omitempty"`
+	FinishedAt        *time.Time             `json:"finished_at,omitempty"`
+}
+
+// WatcherResponse is the public representation of a task watcher
+type WatcherResponse struct {
+	ID        uint32        `json:"id"`
+	TaskId    uint32        `json:"task_id"`
+	UserId    uint32        `json:"user_id"`
+	User      *UserResponse `json:"user,omitempty"`
+	CreatedAt time.Time     `json:"created_at,omitempty"`
+}
+
+func NewTaskResponse(task models.Task) TaskResponse {
+	return TaskResponse{
+		ID:                task.ID,
+		Title:             task.Title,
+		Summary:           task.Summary,
+		UserId:            task.UserId,
+		OrganizationId:    task.OrganizationId,
+		ProjectId:         task.ProjectId,
+		Done:              task.Done,
+		Status:            task.Status,
+		RequiresApproval:  task.RequiresApproval,
+		User:              newEmbeddedUser(task.User),
+		Priority:          task.Priority,
+		Tags:              task.Tags,
+		Checklist:         task.Checklist,
+		EstimatedMinutes:  task.EstimatedMinutes,
+		TemplateVersionId: task.TemplateVersionId,
+		CustomFields:      task.CustomFields,
+		DueAt:             task.DueAt,
+		CreatedAt:         task.CreatedAt,
+		UpdatedAt:         task.UpdatedAt,
+		FinishedAt:        task.FinishedAt,
+	}
+}
+
+func NewTaskListResponse(tasks []models.Task) []TaskResponse {
+	response := make([]TaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		response = append(response, NewTaskResponse(task))
+	}
+	return response
+}
+
+func NewWatcherListResponse(watchers []models.TaskWatcher) []WatcherResponse {
+	response := make([]WatcherResponse, 0, len(watchers))
+	for _, watcher := range watchers {
+		response = append(response, NewWatcherResponse(watcher))
+	}
+	return response
+}
+
+func NewWatcherResponse(watcher models.TaskWatcher) WatcherResponse {
+	return WatcherResponse{
+		ID:        watcher.ID,
+		TaskId:    watcher.TaskId,
+		UserId:    watcher.UserId,
+		User:      newEmbeddedUser(watcher.User),
+		CreatedAt: watcher.CreatedAt,
+	}
+}
+
+// newEmbeddedUser returns nil when the user association was not preloaded
+func newEmbeddedUser(user models.User) *UserResponse {
+	if user.ID == 0 {
+		return nil
+	}
+	response := NewUserResponse(user)
+	return &response
+}
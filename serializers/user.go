@@ -0,0 +1,53 @@
+package serializers
+
+import (
+	"time"
+
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// UserResponse is the public representation of a user. Credentials and
+// internal columns (password hash, token version) are never part of it.
+type UserResponse struct {
+	ID             uint32     `json:"id"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	EmailVerified  bool       `json:"email_verified"`
+	IsManager      bool       `json:"is_manager"`
+	OrganizationId uint32     `json:"organization_id"`
+	Timezone       string     `json:"timezone,omitempty"`
+	Locale         string     `json:"locale,omitempty"`
+	AvatarUrl      string     `json:"avatar_url,omitempty"`
+	Active         bool       `json:"active"`
+	MfaEnabled     bool       `json:"mfa_enabled"`
+	DeactivatedAt  *time.Time `json:"deactivated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`
+}
+
+func NewUserResponse(user models.User) UserResponse {
+	return UserResponse{
+		ID:             user.ID,
+		Name:           user.Name,
+		Email:          user.Email,
+		EmailVerified:  user.EmailVerified,
+		IsManager:      user.IsManager,
+		OrganizationId: user.OrganizationId,
+		Timezone:       user.Timezone,
+		Locale:         user.Locale,
+		AvatarUrl:      user.AvatarUrl,
+		Active:         user.Active,
+		MfaEnabled:     user.MfaEnabled,
+		DeactivatedAt:  user.DeactivatedAt,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+func NewUserListResponse(users []models.User) []UserResponse {
+	response := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		response = append(response, NewUserResponse(user))
+	}
+	return response
+}
@@ -0,0 +1,41 @@
+package serializers
+
+import (
+	"time"
+
+	"github.com/hugohenrick/gtasks/models"
+)
+
+// WebhookResponse is the public representation of a webhook, the secret is
+// only returned when it is created
+type WebhookResponse struct {
+	ID                  uint32     `json:"id"`
+	Url                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures uint32     `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at,omitempty"`
+	UpdatedAt           time.Time  `json:"updated_at,omitempty"`
+}
+
+func NewWebhookResponse(webhook models.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:                  webhook.ID,
+		Url:                 webhook.Url,
+		EventTypes:          webhook.EventTypeList(),
+		Active:              webhook.Active,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		DisabledAt:          webhook.DisabledAt,
+		CreatedAt:           webhook.CreatedAt,
+		UpdatedAt:           webhook.UpdatedAt,
+	}
+}
+
+func NewWebhookListResponse(webhooks []models.Webhook) []WebhookResponse {
+	response := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, NewWebhookResponse(webhook))
+	}
+	return response
+}
@@ -0,0 +1,54 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+)
+
+// NewConsumer returns the consumer of the task events of the instance: a
+// transient queue with a unique name, so every instance gets every event
+func NewConsumer() (broker.Consumer, error) {
+	id, err := events.NewId()
+	if err != nil {
+		return broker.Consumer{}, err
+	}
+
+	return broker.Consumer{
+		Queue:   broker.Queue{Name: broker.QueueStream + "." + id, Bindings: []string{"task.#"}, Transient: true},
+		Handler: HandleEvent,
+	}, nil
+}
+
+// HandleEvent publishes the task event to the Tasks hub
+func HandleEvent(ctx context.Context, msg broker.Message) error {
+	event, err := broker.DecodeEvent(msg)
+	if err != nil {
+		return broker.Permanent(fmt.Errorf("error decoding event: %w", err))
+	}
+
+	if events.Aggregate(event.Type) != "task" {
+		return nil
+	}
+
+	// task.created, task.updated and task.executed carry the task, task.deleted
+	// its assignee and task.updated the previous assignee of a reassigned task
+	var data struct {
+		Task           *events.Task `json:"task"`
+		UserId         uint32       `json:"user_id"`
+		PreviousUserId uint32       `json:"previous_user_id"`
+	}
+	if err := event.Decode(&data); err != nil {
+		return broker.Permanent(fmt.Errorf("error decoding %s: %w", event.Type, err))
+	}
+
+	entry := Entry{ID: event.ID, Type: event.Type, OrganizationId: event.OrganizationId, UserId: data.UserId, PreviousUserId: data.PreviousUserId, Data: msg.Body}
+	if data.Task != nil {
+		entry.UserId = data.Task.UserId
+	}
+	Tasks.Publish(entry)
+
+	return nil
+}
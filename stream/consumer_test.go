@@ -0,0 +1,95 @@
+package stream_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func streamMessage(eventType string, data interface{}) (events.Event, broker.Message) {
+	event, _ := events.New(eventType, events.TaskSubject(1), "corr-1", data)
+	event.OrganizationId = 3
+	body, _ := json.Marshal(event)
+
+	return event, broker.Message{Queue: broker.QueueStream + ".test", Body: body}
+}
+
+func TestNewConsumer(t *testing.T) {
+	assert := assert.New(t)
+
+	first, err := stream.NewConsumer()
+	assert.Nil(err)
+	second, _ := stream.NewConsumer()
+
+	// asserts
+	assert.True(first.Queue.Transient)
+	assert.Equal([]string{"task.#"}, first.Queue.Bindings)
+	assert.NotEqual(first.Queue.Name, second.Queue.Name)
+}
+
+func TestHandleEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: assignee of the task", func(t *testing.T) {
+		stream.Tasks = stream.NewHub(10)
+		event, msg := streamMessage(events.TaskUpdated, events.TaskUpdatedData{Task: events.Task{ID: 1, UserId: 2}, Changes: []string{"done"}})
+
+		subscription, _ := stream.Tasks.Subscribe("", func(stream.Entry) bool { return true })
+		defer stream.Tasks.Unsubscribe(subscription)
+
+		err := stream.HandleEvent(context.Background(), msg)
+		entry := <-subscription.Events
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(event.ID, entry.ID)
+		assert.Equal(events.TaskUpdated, entry.Type)
+		assert.Equal(uint32(2), entry.UserId)
+	})
+
+	t.Run("Success: previous assignee of a reassigned task", func(t *testing.T) {
+		stream.Tasks = stream.NewHub(10)
+		_, msg := streamMessage(events.TaskUpdated, events.TaskUpdatedData{Task: events.Task{ID: 1, UserId: 2}, Changes: []string{"user_id"}, PreviousUserId: 5})
+
+		subscription, _ := stream.Tasks.Subscribe("", func(stream.Entry) bool { return true })
+		defer stream.Tasks.Unsubscribe(subscription)
+
+		err := stream.HandleEvent(context.Background(), msg)
+		entry := <-subscription.Events
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(uint32(2), entry.UserId)
+		assert.Equal(uint32(5), entry.PreviousUserId)
+	})
+
+	t.Run("Success: assignee of a deleted task", func(t *testing.T) {
+		stream.Tasks = stream.NewHub(10)
+		_, msg := streamMessage(events.TaskDeleted, events.TaskDeletedData{TaskId: 1, UserId: 4, OrganizationId: 3})
+
+		subscription, _ := stream.Tasks.Subscribe("", func(stream.Entry) bool { return true })
+		defer stream.Tasks.Unsubscribe(subscription)
+
+		err := stream.HandleEvent(context.Background(), msg)
+		entry := <-subscription.Events
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(events.TaskDeleted, entry.Type)
+		assert.Equal(uint32(4), entry.UserId)
+		assert.Equal(uint32(3), entry.OrganizationId)
+		assert.Equal(msg.Body, entry.Data)
+	})
+
+	t.Run("Error: invalid message", func(t *testing.T) {
+		err := stream.HandleEvent(context.Background(), broker.Message{Body: []byte("{")})
+
+		// asserts
+		assert.True(broker.IsPermanent(err))
+	})
+}
@@ -0,0 +1,155 @@
+// Package stream pushes the task events to the clients of GET /task/stream.
+// Every instance consumes the task events from its own transient queue into a
+// Hub, which keeps the latest ones so a client resumes where it stopped after
+// reconnecting, to any instance.
+package stream
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is the number of events a subscriber can lag behind before
+// it is dropped, the client reconnects and resumes from the history
+const subscriberBuffer = 64
+
+// Entry is a task event pushed to the clients
+type Entry struct {
+	ID             string
+	Type           string
+	OrganizationId uint32
+	UserId         uint32 // assignee of the task
+	PreviousUserId uint32 // assignee before the reassignment, 0 when not reassigned
+	Data           []byte // the event envelope
+}
+
+// Subscription receives the events visible to a client. Events is closed when
+// the subscriber lags too far behind or the hub is closed.
+type Subscription struct {
+	Backlog []Entry
+	Events  <-chan Entry
+
+	events  chan Entry
+	visible func(Entry) bool
+}
+
+// Hub fans the events out to the subscriptions and keeps the latest ones
+type Hub struct {
+	size int
+
+	mu            sync.Mutex
+	history       []Entry
+	subscriptions map[*Subscription]bool
+	closed        bool
+}
+
+// Tasks is the hub of the task events of the instance
+var Tasks = NewHub(envInt("STREAM_HISTORY_SIZE", 1000))
+
+// NewHub returns a hub keeping the latest size events
+func NewHub(size int) *Hub {
+	return &Hub{size: size, subscriptions: make(map[*Subscription]bool)}
+}
+
+// Publish keeps the event and sends it to the subscriptions it is visible to.
+// Subscriptions whose buffer is full are dropped.
+func (h *Hub) Publish(entry Entry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.history = append(h.history, entry)
+	if len(h.history) > h.size {
+		h.history = append([]Entry{}, h.history[len(h.history)-h.size:]...)
+	}
+
+	for subscription := range h.subscriptions {
+		if !subscription.visible(entry) {
+			continue
+		}
+		select {
+		case subscription.events <- entry:
+		default:
+			delete(h.subscriptions, subscription)
+			close(subscription.events)
+		}
+	}
+}
+
+// Subscribe subscribes to the events visible to the client. The backlog has
+// the visible events kept after lastEventId; resumed is false when lastEventId
+// is not kept anymore, events may have been missed and the backlog is empty.
+func (h *Hub) Subscribe(lastEventId string, visible func(Entry) bool) (*Subscription, bool) {
+	events := make(chan Entry, subscriberBuffer)
+	subscription := &Subscription{Events: events, events: events, visible: visible}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(events)
+		return subscription, lastEventId == ""
+	}
+
+	found := false
+	for _, entry := range h.history {
+		if found && visible(entry) {
+			subscription.Backlog = append(subscription.Backlog, entry)
+		}
+		if entry.ID == lastEventId {
+			found = true
+		}
+	}
+	if !found {
+		subscription.Backlog = nil
+	}
+
+	h.subscriptions[subscription] = true
+
+	return subscription, found || lastEventId == ""
+}
+
+// Unsubscribe stops sending events to the subscription
+func (h *Hub) Unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscriptions[subscription] {
+		delete(h.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+// Close ends the subscriptions and refuses the new ones, so the streams end
+// when the server shuts down
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for subscription := range h.subscriptions {
+		delete(h.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+// HeartbeatInterval is the interval of the comments sent to keep the idle
+// streams open, STREAM_HEARTBEAT_SECONDS
+func HeartbeatInterval() time.Duration {
+	return time.Duration(envInt("STREAM_HEARTBEAT_SECONDS", 15)) * time.Second
+}
+
+// RecheckInterval is the interval the session of the streams is checked
+// again, STREAM_RECHECK_SECONDS
+func RecheckInterval() time.Duration {
+	return time.Duration(envInt("STREAM_RECHECK_SECONDS", 60)) * time.Second
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+package stream_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func all(stream.Entry) bool { return true }
+
+func publish(hub *stream.Hub, count int) {
+	for i := 1; i <= count; i++ {
+		hub.Publish(stream.Entry{ID: fmt.Sprintf("%d", i), Type: "task.updated", OrganizationId: 1, UserId: uint32(i)})
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: only visible events received", func(t *testing.T) {
+		hub := stream.NewHub(10)
+		subscription, resumed := hub.Subscribe("", func(entry stream.Entry) bool { return entry.UserId == 2 })
+		defer hub.Unsubscribe(subscription)
+
+		publish(hub, 3)
+
+		// asserts
+		assert.True(resumed)
+		assert.Empty(subscription.Backlog)
+		assert.Equal("2", (<-subscription.Events).ID)
+		assert.Len(subscription.Events, 0)
+	})
+
+	t.Run("Success: slow subscriber dropped", func(t *testing.T) {
+		hub := stream.NewHub(100)
+		subscription, _ := hub.Subscribe("", all)
+
+		publish(hub, 65)
+
+		received := 0
+		for range subscription.Events {
+			received++
+		}
+
+		// asserts
+		assert.Equal(64, received)
+		hub.Unsubscribe(subscription)
+	})
+}
+
+func TestHubSubscribe(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: resumed after the last event id", func(t *testing.T) {
+		hub := stream.NewHub(10)
+		publish(hub, 5)
+
+		subscription, resumed := hub.Subscribe("2", func(entry stream.Entry) bool { return entry.UserId != 4 })
+		defer hub.Unsubscribe(subscription)
+
+		// asserts
+		assert.True(resumed)
+		assert.Len(subscription.Backlog, 2)
+		assert.Equal("3", subscription.Backlog[0].ID)
+		assert.Equal("5", subscription.Backlog[1].ID)
+	})
+
+	t.Run("Error: last event id no longer kept", func(t *testing.T) {
+		hub := stream.NewHub(3)
+		publish(hub, 5)
+
+		subscription, resumed := hub.Subscribe("1", all)
+		defer hub.Unsubscribe(subscription)
+
+		// asserts
+		assert.False(resumed)
+		assert.Empty(subscription.Backlog)
+	})
+}
+
+func TestHubClose(t *testing.T) {
+	assert := assert.New(t)
+
+	hub := stream.NewHub(10)
+	subscription, _ := hub.Subscribe("", all)
+
+	hub.Close()
+	publish(hub, 1)
+	_, open := <-subscription.Events
+	late, _ := hub.Subscribe("", all)
+	_, lateOpen := <-late.Events
+
+	// asserts
+	assert.False(open)
+	assert.False(lateOpen)
+	hub.Unsubscribe(subscription)
+}
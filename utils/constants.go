@@ -2,19 +2,117 @@ package utils
 
 const (
 	// Common messages
-	InvalidJsonProvided = "inavlid json provided"
+	InvalidJsonProvided   = "inavlid json provided"
+	InvalidQueryParameter = "invalid query parameter"
+	InvalidFieldSelection = "invalid fields selection"
 
 	//User
 	UserSuccessCreate               = "user created successfully"
 	UserFailedGetToken              = "failed to get token"
+	UserFailedHashPassword          = "failed to hash password"
 	UserNotFound                    = "user not found"
 	UserInvalidCredentials          = "user invalid credentials"
 	UserIdRequired                  = "user id is required"
 	UserWithoutAccesPermission      = "user without access permission"
 	UserCannotChangeTaskAnotherUser = "user cannot change a task of another user"
+	UserDeactivated                 = "user deactivated"
+	UserReassignToInvalid           = "tasks cannot be reassigned to this user"
+	UserCannotDeactivateItself      = "user cannot deactivate itself"
+	UserTimezoneInvalid             = "user timezone is invalid"
+	UserLocaleInvalid               = "user locale is invalid"
+	UserAvatarUrlInvalid            = "user avatar url must be an http(s) url"
+	UserNameRequired                = "user name is required"
+	UserEmailInvalid                = "user email is invalid"
+	UserTooManyLoginAttempts        = "too many login attempts, try again later"
+	UserLoginUnavailable            = "login temporarily unavailable, try again later"
+	UserMfaCodeRequired             = "two-factor authentication code required"
+	UserMfaCodeInvalid              = "two-factor authentication code is invalid"
+	UserMfaTokenInvalid             = "mfa token is invalid or expired"
+	UserMfaAlreadyEnabled           = "two-factor authentication already enabled"
+	UserMfaNotEnrolled              = "two-factor authentication not enrolled"
+	UserMfaRequired                 = "two-factor authentication is required for managers"
+	UserCurrentPasswordInvalid      = "user current password is invalid"
+	UserPasswordTooShort            = "password is too short"
+	UserPasswordTooLong             = "password is too long"
+	UserPasswordBreached            = "password is too common or appeared in a data breach"
+	UserTokenInvalid                = "token is invalid or expired"
+	UserPasswordResetRequested      = "if the email is registered, a password reset link was sent"
+	UserPasswordResetSuccess        = "password changed successfully"
+	UserEmailVerified               = "email verified successfully"
+	UserPasswordLoginDisabled       = "password login is disabled for the organization, sign in with single sign-on"
+	UserSsoNotConfigured            = "single sign-on is not configured"
+	UserSsoStateInvalid             = "single sign-on state is invalid or expired"
+	UserSsoFailed                   = "single sign-on failed"
+	UserSsoGroupNotAllowed          = "user groups are not allowed to access gtasks"
+	UserSsoEmailRequired            = "identity provider did not return a verified email"
+
+	//Api token
+	ApiTokenNotFound      = "api token not found"
+	ApiTokenIdRequired    = "api token id is required"
+	ApiTokenNameRequired  = "api token name is required"
+	ApiTokenScopeRequired = "api token requires at least one scope"
+	ApiTokenScopeInvalid  = "invalid api token scope"
 
 	//Task
 	TaskNotFound        = "task not found"
 	TaskTitleRequired   = "task title is required"
 	TaskSummaryRequired = "task summary is required"
+	TaskIdRequired      = "task id is required"
+	TaskNotWatched      = "task is not watched by user"
+	TaskPriorityInvalid = "invalid task priority"
+
+	TaskAlreadyExecuted       = "task already executed"
+	TaskRequiresApproval      = "task requires the approval of a manager to be done"
+	TaskNotPendingReview      = "task is not pending review"
+	TaskReviewCommentRequired = "a comment is required to reject a task"
+
+	//Template
+	TemplateNotFound        = "template not found"
+	TemplateIdRequired      = "template id is required"
+	TemplateNameRequired    = "template name is required"
+	TemplateVariableMissing = "template variables without value"
+
+	//Organization
+	OrganizationNotFound     = "organization not found"
+	OrganizationNameRequired = "organization name is required"
+	OrganizationSsoRequired  = "single sign-on must be configured to disable the password login"
+
+	//Customer, site and project
+	CustomerNotFound     = "customer not found"
+	CustomerIdRequired   = "customer id is required"
+	CustomerNameRequired = "customer name is required"
+	SiteNotFound         = "site not found"
+	SiteIdRequired       = "site id is required"
+	SiteNameRequired     = "site name is required"
+	ProjectNotFound      = "project not found"
+	ProjectIdRequired    = "project id is required"
+	ProjectNameRequired  = "project name is required"
+
+	//Custom field
+	CustomFieldNotFound        = "custom field not found"
+	CustomFieldIdRequired      = "custom field id is required"
+	CustomFieldKeyInvalid      = "invalid custom field key"
+	CustomFieldTypeInvalid     = "invalid custom field type"
+	CustomFieldOptionsRequired = "custom field options are required for enum fields"
+	CustomFieldPatternInvalid  = "invalid custom field pattern"
+	CustomFieldUnknown         = "unknown custom field"
+	CustomFieldRequired        = "custom field is required"
+	CustomFieldInvalidValue    = "invalid custom field value"
+	TaskSortInvalid            = "invalid task sort"
+
+	//Notification
+	NotificationEventInvalid = "invalid notification event"
+
+	//Webhook
+	WebhookNotFound             = "webhook not found"
+	WebhookIdRequired           = "webhook id is required"
+	WebhookUrlInvalid           = "webhook url must be an absolute http or https url"
+	WebhookUrlNotAllowed        = "webhook url must not point to the internal network"
+	WebhookEventRequired        = "webhook requires at least one event type"
+	WebhookEventInvalid         = "invalid webhook event type"
+	WebhookDeliveryLimitInvalid = "webhook delivery limit must be between 1 and 100"
+
+	//Dead letters
+	DeadLetterQueueInvalid = "queue has no dead-letter queue"
+	DeadLetterLimitInvalid = "dead letter limit must be between 1 and 100"
 )
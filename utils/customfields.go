@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/hugohenrick/gtasks/models"
+)
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ValidCustomFieldKey reports whether key can be used as a custom field key
+func ValidCustomFieldKey(key string) bool {
+	return customFieldKeyPattern.MatchString(key)
+}
+
+// ValidateCustomFieldSchema checks that a schema definition is usable
+func ValidateCustomFieldSchema(schema models.CustomFieldSchema) error {
+	if !ValidCustomFieldKey(schema.Key) {
+		return fmt.Errorf("%v: %v", CustomFieldKeyInvalid, schema.Key)
+	}
+
+	switch schema.Type {
+	case models.CustomFieldString, models.CustomFieldNumber, models.CustomFieldDate, models.CustomFieldBoolean:
+	case models.CustomFieldEnum:
+		if len(schema.Options) == 0 {
+			return fmt.Errorf("%v: %v", CustomFieldOptionsRequired, schema.Key)
+		}
+	default:
+		return fmt.Errorf("%v: %v", CustomFieldTypeInvalid, schema.Type)
+	}
+
+	if schema.Pattern != "" {
+		if _, err := regexp.Compile(schema.Pattern); err != nil {
+			return fmt.Errorf("%v: %v", CustomFieldPatternInvalid, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateCustomFields checks the custom field values of a task against the
+// organization schemas and returns the values normalized to their JSON types.
+func ValidateCustomFields(schemas []models.CustomFieldSchema, values models.CustomFields) (models.CustomFields, error) {
+	known := make(map[string]models.CustomFieldSchema, len(schemas))
+	for _, schema := range schemas {
+		known[schema.Key] = schema
+	}
+
+	for key := range values {
+		if _, ok := known[key]; !ok {
+			return nil, fmt.Errorf("%v: %v", CustomFieldUnknown, key)
+		}
+	}
+
+	normalized := make(models.CustomFields, len(values))
+	for _, schema := range schemas {
+		value, ok := values[schema.Key]
+		if !ok || value == nil || value == "" {
+			if schema.Required {
+				return nil, fmt.Errorf("%v: %v", CustomFieldRequired, schema.Key)
+			}
+			continue
+		}
+
+		v, err := normalizeCustomField(schema, value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v: %v", CustomFieldInvalidValue, schema.Key, err)
+		}
+		normalized[schema.Key] = v
+	}
+
+	return normalized, nil
+}
+
+func normalizeCustomField(schema models.CustomFieldSchema, value interface{}) (interface{}, error) {
+	var normalized interface{}
+	var text string
+
+	switch schema.Type {
+	case models.CustomFieldString, models.CustomFieldEnum:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string")
+		}
+		normalized, text = s, s
+	case models.CustomFieldNumber:
+		switch n := value.(type) {
+		case float64:
+			normalized = n
+		case string:
+			f, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected a number")
+			}
+			normalized = f
+		default:
+			return nil, fmt.Errorf("expected a number")
+		}
+		text = strconv.FormatFloat(normalized.(float64), 'f', -1, 64)
+	case models.CustomFieldDate:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a date")
+		}
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				return nil, fmt.Errorf("expected a date as YYYY-MM-DD or RFC 3339")
+			}
+		}
+		normalized, text = s, s
+	case models.CustomFieldBoolean:
+		switch b := value.(type) {
+		case bool:
+			normalized = b
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return nil, fmt.Errorf("expected a boolean")
+			}
+			normalized = parsed
+		default:
+			return nil, fmt.Errorf("expected a boolean")
+		}
+		text = strconv.FormatBool(normalized.(bool))
+	default:
+		return nil, fmt.Errorf("%v: %v", CustomFieldTypeInvalid, schema.Type)
+	}
+
+	if schema.Type == models.CustomFieldEnum && !ContainsString(schema.Options, text) {
+		return nil, fmt.Errorf("expected one of %v", schema.Options)
+	}
+
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		if !pattern.MatchString(text) {
+			return nil, fmt.Errorf("does not match %v", schema.Pattern)
+		}
+	}
+
+	return normalized, nil
+}
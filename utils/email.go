@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// NormalizeEmail trims and lowercases an email, emails are unique in this form
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidEmail reports whether email is a bare RFC 5322 address, without display name
+func ValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return false
+	}
+	return address.Address == email && strings.Contains(email, "@")
+}
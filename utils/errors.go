@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"errors"
+)
+
+// Errors the controllers map to a 409 Conflict
+var (
+	ErrCustomerHasSites = errors.New("customer has sites")
+	ErrSiteHasProjects  = errors.New("site has projects")
+	ErrProjectHasTasks  = errors.New("project has tasks, delete it with cascade=true to delete its tasks too")
+	ErrUserHasTasks     = errors.New("user has tasks, deactivate it instead")
+	ErrEmailTaken       = errors.New("email already registered")
+	ErrSsoLinkRefused   = errors.New("email already registered to an account that cannot be linked to the single sign-on")
+)
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordBcrypt   = "bcrypt"
+)
+
+// ErrPasswordMismatch is returned by CheckPasswordHash when the password is wrong
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// PasswordHashConfig sets the algorithm and the parameters of new password
+// hashes. Argon2 hashes are stored in the PHC string format, e.g.
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>.
+type PasswordHashConfig struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+}
+
+// PasswordHashing is the configuration of the api, read from the PASSWORD_HASH_*
+// environment variables. The defaults follow the OWASP recommendation for argon2id.
+var PasswordHashing = PasswordHashConfigFromEnv()
+
+// PasswordHashConfigFromEnv reads the PASSWORD_HASH_* environment variables
+func PasswordHashConfigFromEnv() PasswordHashConfig {
+	config := PasswordHashConfig{
+		Algorithm:         os.Getenv("PASSWORD_HASH_ALGORITHM"),
+		BcryptCost:        envInt("PASSWORD_HASH_BCRYPT_COST", 12),
+		Argon2Memory:      uint32(envInt("PASSWORD_HASH_ARGON2_MEMORY", 19*1024)),
+		Argon2Iterations:  uint32(envInt("PASSWORD_HASH_ARGON2_ITERATIONS", 2)),
+		Argon2Parallelism: uint8(envInt("PASSWORD_HASH_ARGON2_PARALLELISM", 1)),
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+	}
+	if config.Algorithm != PasswordBcrypt {
+		config.Algorithm = PasswordArgon2id
+	}
+	return config
+}
+
+// HashPassword hashes the password with the configured algorithm
+func HashPassword(password string) (string, error) {
+	return PasswordHashing.Hash(password)
+}
+
+// CheckPasswordHash verifies the password against an argon2id or bcrypt hash
+func CheckPasswordHash(password string, hash string) error {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2Hash(hash)
+		if err != nil {
+			return err
+		}
+
+		candidate := argon2.IDKey([]byte(password), salt, params.Argon2Iterations, params.Argon2Memory, params.Argon2Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(candidate, key) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrPasswordMismatch
+		}
+		return err
+	}
+	return nil
+}
+
+// PasswordNeedsRehash reports whether the hash was made with another
+// algorithm or other parameters than the configured ones
+func PasswordNeedsRehash(hash string) bool {
+	return PasswordHashing.NeedsRehash(hash)
+}
+
+// Hash hashes the password with the algorithm of the configuration
+func (config PasswordHashConfig) Hash(password string) (string, error) {
+	if config.Algorithm == PasswordBcrypt {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), config.BcryptCost)
+		return string(bytes), err
+	}
+
+	salt := make([]byte, config.Argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, config.Argon2Iterations, config.Argon2Memory, config.Argon2Parallelism, config.Argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, config.Argon2Memory, config.Argon2Iterations, config.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// NeedsRehash reports whether the hash differs from the configuration
+func (config PasswordHashConfig) NeedsRehash(hash string) bool {
+	if config.Algorithm == PasswordBcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != config.BcryptCost
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.Argon2Memory != config.Argon2Memory ||
+		params.Argon2Iterations != config.Argon2Iterations ||
+		params.Argon2Parallelism != config.Argon2Parallelism ||
+		uint32(len(salt)) != config.Argon2SaltLength ||
+		uint32(len(key)) != config.Argon2KeyLength
+}
+
+// decodeArgon2Hash parses an argon2id hash in the PHC string format
+func decodeArgon2Hash(hash string) (PasswordHashConfig, []byte, []byte, error) {
+	params := PasswordHashConfig{Algorithm: PasswordArgon2id}
+	invalid := errors.New("invalid argon2id hash")
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordArgon2id {
+		return params, nil, nil, invalid
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, invalid
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Iterations, &params.Argon2Parallelism); err != nil {
+		return params, nil, nil, invalid
+	}
+	if params.Argon2Iterations == 0 || params.Argon2Parallelism == 0 {
+		return params, nil, nil, invalid
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, invalid
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, invalid
+	}
+
+	return params, salt, key, nil
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
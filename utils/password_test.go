@@ -0,0 +1,72 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPassword(t *testing.T) {
+	assert := assert.New(t)
+
+	config := utils.PasswordHashConfig{
+		Algorithm:         utils.PasswordArgon2id,
+		Argon2Memory:      1024,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+	}
+
+	t.Run("Success: argon2id hash in the PHC format", func(t *testing.T) {
+		hash, err := config.Hash("correct-horse")
+
+		// asserts
+		assert.Nil(err)
+		assert.True(strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+		assert.Nil(utils.CheckPasswordHash("correct-horse", hash))
+		assert.ErrorIs(utils.CheckPasswordHash("wrong-horse", hash), utils.ErrPasswordMismatch)
+		assert.False(config.NeedsRehash(hash))
+	})
+
+	t.Run("Success: outdated parameters need rehash", func(t *testing.T) {
+		hash, _ := config.Hash("correct-horse")
+
+		stronger := config
+		stronger.Argon2Iterations = 2
+
+		// asserts
+		assert.True(stronger.NeedsRehash(hash))
+	})
+
+	t.Run("Success: legacy bcrypt hash is verified and rehashed", func(t *testing.T) {
+		legacy, _ := bcrypt.GenerateFromPassword([]byte("correct-horse"), bcrypt.MinCost)
+
+		// asserts
+		assert.Nil(utils.CheckPasswordHash("correct-horse", string(legacy)))
+		assert.ErrorIs(utils.CheckPasswordHash("wrong-horse", string(legacy)), utils.ErrPasswordMismatch)
+		assert.True(config.NeedsRehash(string(legacy)))
+	})
+
+	t.Run("Success: bcrypt configured with another cost", func(t *testing.T) {
+		bcryptConfig := utils.PasswordHashConfig{Algorithm: utils.PasswordBcrypt, BcryptCost: bcrypt.MinCost}
+		hash, err := bcryptConfig.Hash("correct-horse")
+
+		costlier := bcryptConfig
+		costlier.BcryptCost = bcrypt.MinCost + 1
+
+		// asserts
+		assert.Nil(err)
+		assert.Nil(utils.CheckPasswordHash("correct-horse", hash))
+		assert.False(bcryptConfig.NeedsRehash(hash))
+		assert.True(costlier.NeedsRehash(hash))
+	})
+
+	t.Run("Failed: malformed argon2id hash", func(t *testing.T) {
+		// asserts
+		assert.NotNil(utils.CheckPasswordHash("correct-horse", "$argon2id$v=19$m=1024$salt$key"))
+	})
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// RenderPlaceholders replaces the {{name}} placeholders of text with the given
+// variables and returns the names of the placeholders without a value.
+func RenderPlaceholders(text string, variables map[string]string) (string, []string) {
+	var missing []string
+
+	rendered := placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := variables[name]
+		if !ok {
+			missing = append(missing, name)
+			return placeholder
+		}
+		return value
+	})
+
+	return rendered, missing
+}
+
+// MissingPlaceholders joins the names returned by RenderPlaceholders, without duplicates
+func MissingPlaceholders(names []string) string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return strings.Join(unique, ", ")
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"time"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// ValidTimezone reports whether timezone is an IANA time zone name, e.g. America/Sao_Paulo
+func ValidTimezone(timezone string) bool {
+	if timezone == "" || timezone == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(timezone)
+	return err == nil
+}
+
+// ValidLocale reports whether locale is a language tag such as pt or pt-BR
+func ValidLocale(locale string) bool {
+	return localePattern.MatchString(locale)
+}
+
+// ValidAvatarUrl reports whether avatar is an absolute http(s) url. An empty
+// value removes the avatar.
+func ValidAvatarUrl(avatar string) bool {
+	if avatar == "" {
+		return true
+	}
+	u, err := url.Parse(avatar)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func SendJSONResponse(c *gin.Context, statusCode int, data interface{}) {
@@ -18,19 +17,19 @@ func SendJSONError(c *gin.Context, statusCode int, err error) {
 	})
 }
 
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
-}
-
-func CheckPasswordHash(password string, hash string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err
-}
-
 func LoadEnv() {
 	err := godotenv.Load(".env")
 	if err != nil {
 		log.Fatalf("unable to load .env file")
 	}
 }
+
+// ContainsString reports whether value is one of values
+func ContainsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
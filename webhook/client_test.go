@@ -0,0 +1,58 @@
+package webhook_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicIP(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, address := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "100.64.0.1", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1"} {
+		assert.False(webhook.PublicIP(net.ParseIP(address)), address)
+	}
+	for _, address := range []string{"8.8.8.8", "203.0.113.10", "2001:4860:4860::8888"} {
+		assert.True(webhook.PublicIP(net.ParseIP(address)), address)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Failed: internal address refused after resolution", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		_, err := webhook.NewClient(time.Second).Get(server.URL)
+
+		// asserts
+		assert.True(errors.Is(err, webhook.ErrAddressNotAllowed))
+	})
+
+	t.Run("Success: redirects not followed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+		}))
+		defer server.Close()
+
+		// the test server listens on the loopback, which the client refuses
+		client := webhook.NewClient(time.Second)
+		client.Transport = http.DefaultTransport
+
+		res, err := client.Get(server.URL)
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(http.StatusFound, res.StatusCode)
+		res.Body.Close()
+	})
+}
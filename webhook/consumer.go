@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+)
+
+// Consumer saves the deliveries of the task events, added to broker.Consumers
+// by the services delivering webhooks
+var Consumer = broker.Consumer{
+	Queue:   broker.Queue{Name: broker.QueueWebhooks, Bindings: []string{"task.#"}},
+	Handler: HandleEvent,
+}
+
+// HandleEvent saves a pending delivery of the event for every active webhook
+// of its organization subscribed to its type
+func HandleEvent(ctx context.Context, msg broker.Message) error {
+	event, err := broker.DecodeEvent(msg)
+	if err != nil {
+		return broker.Permanent(fmt.Errorf("error decoding event: %w", err))
+	}
+
+	if !ValidEventType(event.Type) || event.OrganizationId == 0 {
+		return nil
+	}
+
+	webhooks, err := repository.WebhookRepositoryServices.FindSubscribedWebhooks(event.OrganizationId, event.Type)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookId:     webhook.ID,
+			EventId:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(msg.Body),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: event.Time,
+		})
+	}
+
+	return repository.WebhookDeliveryRepositoryServices.CreateWebhookDeliveries(deliveries)
+}
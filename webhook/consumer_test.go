@@ -0,0 +1,51 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hugohenrick/gtasks/broker"
+	"github.com/hugohenrick/gtasks/events"
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/webhook"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func TestHandleEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	event, _ := events.New(events.TaskCreated, events.TaskSubject(7), "corr-1", events.TaskDeletedData{TaskId: 7})
+	event.OrganizationId = 2
+	body, _ := json.Marshal(event)
+
+	t.Run("Success: one pending delivery per subscribed webhook", func(t *testing.T) {
+		iWebhookMock := new(taskMock.IWebhookRepository)
+		iWebhookMock.On("FindSubscribedWebhooks", uint32(2), events.TaskCreated).Return([]models.Webhook{{ID: 1}, {ID: 3}}, nil)
+		repository.WebhookRepositoryServices = iWebhookMock
+
+		iDeliveryMock := new(taskMock.IWebhookDeliveryRepository)
+		iDeliveryMock.On("CreateWebhookDeliveries", tmock.Anything).Return(nil)
+		repository.WebhookDeliveryRepositoryServices = iDeliveryMock
+
+		err := webhook.HandleEvent(context.Background(), broker.Message{Queue: broker.QueueWebhooks, Body: body})
+
+		// asserts
+		assert.Nil(err)
+		iDeliveryMock.AssertCalled(t, "CreateWebhookDeliveries", tmock.MatchedBy(func(deliveries []models.WebhookDelivery) bool {
+			return len(deliveries) == 2 && deliveries[0].WebhookId == 1 && deliveries[1].WebhookId == 3 &&
+				deliveries[0].EventId == event.ID && deliveries[0].Payload == string(body) &&
+				deliveries[0].Status == models.WebhookDeliveryPending
+		}))
+	})
+
+	t.Run("Failed: undecodable event is permanent", func(t *testing.T) {
+		err := webhook.HandleEvent(context.Background(), broker.Message{Queue: broker.QueueWebhooks, Body: []byte("{")})
+
+		// asserts
+		assert.True(broker.IsPermanent(err))
+	})
+}
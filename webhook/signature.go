@@ -0,0 +1,99 @@
+// Package webhook delivers the task events to the webhooks of the
+// organizations: a consumer saves a delivery per subscribed webhook and a
+// worker posts them, signed with the secret of the webhook, retrying the
+// failures with an exponential backoff.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/utils"
+)
+
+// Headers of the webhook requests
+const (
+	SignatureHeader = "X-Gtasks-Signature"
+	EventHeader     = "X-Gtasks-Event"
+	DeliveryHeader  = "X-Gtasks-Delivery"
+)
+
+// secretPrefix starts the secrets of the webhooks
+const secretPrefix = "whsec_"
+
+// EventTypes are the event types a webhook can subscribe to
+var EventTypes = []string{events.TaskCreated, events.TaskUpdated, events.TaskExecuted, events.TaskDeleted}
+
+// ErrSignatureInvalid is returned by Verify when the signature doesn't match the body
+var ErrSignatureInvalid = errors.New("invalid webhook signature")
+
+// ValidEventType reports whether a webhook can subscribe to the event type
+func ValidEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSecret returns a new random secret for a webhook
+func NewSecret() (string, error) {
+	token, _, err := utils.NewToken()
+	if err != nil {
+		return "", err
+	}
+	return secretPrefix + token, nil
+}
+
+// Sign returns the signature header of a request: the unix timestamp and the
+// hex HMAC-SHA256 of "<timestamp>.<body>" with the secret, e.g.
+// t=1700000000,v1=5257a869...
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp.Unix(), signature(secret, timestamp.Unix(), body))
+}
+
+// Verify checks the signature header of a request received at now. Requests
+// signed more than tolerance ago are rejected, so they cannot be replayed.
+func Verify(secret string, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return ErrSignatureInvalid
+	}
+
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp out of tolerance", ErrSignatureInvalid)
+	}
+
+	expected := signature(secret, timestamp, body)
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrSignatureInvalid
+}
+
+func signature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,43 @@
+package webhook_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hugohenrick/gtasks/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	body := []byte(`{"type":"task.created"}`)
+	signedAt := time.Unix(1700000000, 0)
+	header := webhook.Sign("whsec_secret", signedAt, body)
+
+	t.Run("Success: signature verified within the tolerance", func(t *testing.T) {
+		// asserts
+		assert.True(strings.HasPrefix(header, "t=1700000000,v1="))
+		assert.Nil(webhook.Verify("whsec_secret", header, body, 5*time.Minute, signedAt.Add(time.Minute)))
+	})
+
+	t.Run("Failed: other secret or body", func(t *testing.T) {
+		// asserts
+		assert.True(errors.Is(webhook.Verify("whsec_other", header, body, 5*time.Minute, signedAt), webhook.ErrSignatureInvalid))
+		assert.True(errors.Is(webhook.Verify("whsec_secret", header, []byte(`{}`), 5*time.Minute, signedAt), webhook.ErrSignatureInvalid))
+	})
+
+	t.Run("Failed: timestamp out of tolerance", func(t *testing.T) {
+		err := webhook.Verify("whsec_secret", header, body, 5*time.Minute, signedAt.Add(10*time.Minute))
+
+		// asserts
+		assert.True(errors.Is(err, webhook.ErrSignatureInvalid))
+	})
+
+	t.Run("Failed: malformed header", func(t *testing.T) {
+		// asserts
+		assert.True(errors.Is(webhook.Verify("whsec_secret", "v1=abc", body, 5*time.Minute, signedAt), webhook.ErrSignatureInvalid))
+	})
+}
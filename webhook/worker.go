@@ -0,0 +1,260 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hugohenrick/gtasks/events"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+)
+
+// firstBackoff and maxBackoff bound the wait between the attempts of a delivery
+const (
+	firstBackoff = 30 * time.Second
+	maxBackoff   = time.Hour
+)
+
+// Worker polls the pending deliveries and posts them to their webhook
+type Worker struct {
+	Interval        time.Duration
+	BatchSize       int
+	MaxAttempts     uint32
+	DisableAfter    uint32
+	Retention       time.Duration
+	CleanupInterval time.Duration
+	Concurrency     int
+	Client          *http.Client
+
+	lastCleanup time.Time
+}
+
+// NewWorker returns a worker configured by WEBHOOK_POLL_INTERVAL_MS,
+// WEBHOOK_BATCH_SIZE, WEBHOOK_MAX_ATTEMPTS, WEBHOOK_DISABLE_AFTER,
+// WEBHOOK_CONCURRENCY, WEBHOOK_TIMEOUT_SECONDS and WEBHOOK_RETENTION_DAYS
+func NewWorker() *Worker {
+	return &Worker{
+		Interval:        time.Duration(envInt("WEBHOOK_POLL_INTERVAL_MS", 1000)) * time.Millisecond,
+		BatchSize:       envInt("WEBHOOK_BATCH_SIZE", 50),
+		MaxAttempts:     uint32(envInt("WEBHOOK_MAX_ATTEMPTS", 8)),
+		DisableAfter:    uint32(envInt("WEBHOOK_DISABLE_AFTER", 10)),
+		Retention:       time.Duration(envInt("WEBHOOK_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		CleanupInterval: time.Hour,
+		Concurrency:     envInt("WEBHOOK_CONCURRENCY", 10),
+		Client:          NewClient(time.Duration(envInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second),
+	}
+}
+
+// Start runs the worker until the context is done
+func Start(ctx context.Context) {
+	worker := NewWorker()
+
+	go worker.Run(ctx)
+}
+
+// Run polls the deliveries every interval until the context is done
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.RunOnce(ctx); err != nil {
+				log.Printf("error delivering webhooks: %s\n", err)
+			}
+		}
+	}
+}
+
+// RunOnce posts a batch of due deliveries holding the worker lock, so a single
+// instance delivers at a time, and deletes the old finished deliveries. It
+// returns the number of deliveries that succeeded.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	var delivered int
+
+	_, err := repository.WebhookDeliveryRepositoryServices.WithWebhookLock(func() error {
+		var err error
+		delivered, err = w.deliverPending(ctx)
+		if err != nil {
+			return err
+		}
+
+		w.cleanup()
+		return nil
+	})
+
+	return delivered, err
+}
+
+// deliverPending posts the batch. The webhooks are posted to in parallel, up
+// to Concurrency at a time, and the deliveries of a webhook one at a time in
+// order; after a failure the other deliveries of the webhook wait for the next
+// batch, so a slow or failing endpoint holds back its own deliveries only.
+func (w *Worker) deliverPending(ctx context.Context) (int, error) {
+	deliveries, err := repository.WebhookDeliveryRepositoryServices.FindPendingWebhookDeliveries(time.Now(), w.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var webhookIds []uint32
+	byWebhook := make(map[uint32][]models.WebhookDelivery)
+	for _, delivery := range deliveries {
+		if _, ok := byWebhook[delivery.WebhookId]; !ok {
+			webhookIds = append(webhookIds, delivery.WebhookId)
+		}
+		byWebhook[delivery.WebhookId] = append(byWebhook[delivery.WebhookId], delivery)
+	}
+
+	concurrency := w.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		delivered int
+		firstErr  error
+	)
+	slots := make(chan struct{}, concurrency)
+	for _, webhookId := range webhookIds {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(deliveries []models.WebhookDelivery) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			count, err := w.deliverWebhook(ctx, deliveries)
+
+			mu.Lock()
+			defer mu.Unlock()
+			delivered += count
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}(byWebhook[webhookId])
+	}
+	wg.Wait()
+
+	return delivered, firstErr
+}
+
+// deliverWebhook posts the deliveries of a webhook in order until one fails
+func (w *Worker) deliverWebhook(ctx context.Context, deliveries []models.WebhookDelivery) (int, error) {
+	delivered := 0
+	for _, delivery := range deliveries {
+		status, err := w.post(ctx, delivery)
+		if err == nil {
+			if err := repository.WebhookDeliveryRepositoryServices.MarkWebhookDelivered(delivery.ID, status); err != nil {
+				return delivered, err
+			}
+			if _, err := repository.WebhookDeliveryRepositoryServices.RecordWebhookResult(delivery.WebhookId, true, w.DisableAfter); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		attempt := delivery.Attempts + 1
+		var next *time.Time
+		if attempt < w.MaxAttempts {
+			at := time.Now().Add(backoff(attempt))
+			next = &at
+		}
+		log.Printf("error delivering %s to webhook %d, attempt %d: %s\n", delivery.EventType, delivery.WebhookId, attempt, err)
+		if err := repository.WebhookDeliveryRepositoryServices.MarkWebhookDeliveryFailed(delivery.ID, status, err.Error(), next); err != nil {
+			return delivered, err
+		}
+
+		// a webhook disabled now keeps its deliveries until it is activated again
+		off, err := repository.WebhookDeliveryRepositoryServices.RecordWebhookResult(delivery.WebhookId, false, w.DisableAfter)
+		if err != nil {
+			return delivered, err
+		}
+		if off {
+			log.Printf("webhook %d disabled after %d consecutive failures\n", delivery.WebhookId, w.DisableAfter)
+		}
+		return delivered, nil
+	}
+
+	return delivered, nil
+}
+
+// post sends the delivery to its webhook and returns the status of the
+// response, an error unless it is 2xx
+func (w *Worker) post(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", events.ContentType)
+	req.Header.Set("User-Agent", "gtasks-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, time.Now(), body))
+
+	res, err := w.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook responded %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+func (w *Worker) cleanup() {
+	if w.Retention <= 0 || time.Since(w.lastCleanup) < w.CleanupInterval {
+		return
+	}
+	w.lastCleanup = time.Now()
+
+	deleted, err := repository.WebhookDeliveryRepositoryServices.DeleteFinishedWebhookDeliveries(time.Now().Add(-w.Retention))
+	if err != nil {
+		log.Printf("error cleaning up webhook deliveries: %s\n", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("deleted %d finished webhook deliveries\n", deleted)
+	}
+}
+
+// backoff returns the wait before the attempt after the given one: 30s, 1m,
+// 2m... up to maxBackoff
+func backoff(attempt uint32) time.Duration {
+	if attempt > 8 {
+		return maxBackoff
+	}
+
+	wait := firstBackoff << (attempt - 1)
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,177 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	taskMock "github.com/hugohenrick/gtasks/mock"
+	"github.com/hugohenrick/gtasks/models"
+	"github.com/hugohenrick/gtasks/repository"
+	"github.com/hugohenrick/gtasks/webhook"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+func webhookDelivery(id uint64, url string, attempts uint32) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:        id,
+		WebhookId: 1,
+		EventId:   "event-1",
+		EventType: "task.created",
+		Payload:   `{"type":"task.created"}`,
+		Status:    models.WebhookDeliveryPending,
+		Attempts:  attempts,
+		Webhook:   models.Webhook{ID: 1, Url: url, Secret: "whsec_secret", Active: true},
+	}
+}
+
+func mockDeliveries(deliveries []models.WebhookDelivery, disabled bool) *taskMock.IWebhookDeliveryRepository {
+	iDeliveryMock := new(taskMock.IWebhookDeliveryRepository)
+	iDeliveryMock.On("WithWebhookLock", tmock.Anything).Return(func(fn func() error) bool {
+		return true
+	}, func(fn func() error) error {
+		return fn()
+	})
+	iDeliveryMock.On("FindPendingWebhookDeliveries", tmock.Anything, 10).Return(deliveries, nil)
+	iDeliveryMock.On("MarkWebhookDelivered", tmock.Anything, tmock.Anything).Return(nil)
+	iDeliveryMock.On("MarkWebhookDeliveryFailed", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).Return(nil)
+	iDeliveryMock.On("RecordWebhookResult", uint32(1), true, uint32(3)).Return(false, nil)
+	iDeliveryMock.On("RecordWebhookResult", uint32(1), false, uint32(3)).Return(disabled, nil)
+	iDeliveryMock.On("DeleteFinishedWebhookDeliveries", tmock.Anything).Return(int64(0), nil)
+	repository.WebhookDeliveryRepositoryServices = iDeliveryMock
+
+	return iDeliveryMock
+}
+
+func newWorker() *webhook.Worker {
+	return &webhook.Worker{
+		BatchSize:       10,
+		MaxAttempts:     3,
+		DisableAfter:    3,
+		Retention:       time.Hour,
+		CleanupInterval: time.Hour,
+		Client:          &http.Client{Timeout: time.Second},
+	}
+}
+
+func TestWorkerRunOnce(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Success: signed delivery marked delivered", func(t *testing.T) {
+		var header http.Header
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		iDeliveryMock := mockDeliveries([]models.WebhookDelivery{webhookDelivery(5, server.URL, 0)}, false)
+
+		delivered, err := newWorker().RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(1, delivered)
+		assert.Equal(`{"type":"task.created"}`, string(body))
+		assert.Equal("task.created", header.Get(webhook.EventHeader))
+		assert.Equal("5", header.Get(webhook.DeliveryHeader))
+		assert.Equal("application/cloudevents+json", header.Get("Content-Type"))
+		assert.Nil(webhook.Verify("whsec_secret", header.Get(webhook.SignatureHeader), body, time.Minute, time.Now()))
+		iDeliveryMock.AssertCalled(t, "MarkWebhookDelivered", uint64(5), http.StatusNoContent)
+		iDeliveryMock.AssertCalled(t, "RecordWebhookResult", uint32(1), true, uint32(3))
+	})
+
+	t.Run("Failed: error response retried with backoff", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		iDeliveryMock := mockDeliveries([]models.WebhookDelivery{webhookDelivery(5, server.URL, 1)}, false)
+
+		delivered, err := newWorker().RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(0, delivered)
+		iDeliveryMock.AssertCalled(t, "MarkWebhookDeliveryFailed", uint64(5), http.StatusInternalServerError, "webhook responded 500 Internal Server Error", tmock.MatchedBy(func(next *time.Time) bool {
+			// second attempt: retried in a minute
+			return next != nil && next.Sub(time.Now()) > 55*time.Second && next.Sub(time.Now()) <= time.Minute
+		}))
+		iDeliveryMock.AssertCalled(t, "RecordWebhookResult", uint32(1), false, uint32(3))
+	})
+
+	t.Run("Failed: delivery given up after the last attempt", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		iDeliveryMock := mockDeliveries([]models.WebhookDelivery{webhookDelivery(5, server.URL, 2)}, false)
+
+		_, err := newWorker().RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		iDeliveryMock.AssertCalled(t, "MarkWebhookDeliveryFailed", uint64(5), http.StatusBadGateway, tmock.Anything, (*time.Time)(nil))
+	})
+
+	t.Run("Failed: disabled webhook skips its other deliveries", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer server.Close()
+
+		iDeliveryMock := mockDeliveries([]models.WebhookDelivery{
+			webhookDelivery(5, server.URL, 0),
+			webhookDelivery(6, server.URL, 0),
+		}, true)
+
+		_, err := newWorker().RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(1, calls)
+		iDeliveryMock.AssertNumberOfCalls(t, "MarkWebhookDeliveryFailed", 1)
+		iDeliveryMock.AssertNotCalled(t, "MarkWebhookDeliveryFailed", uint64(6), tmock.Anything, tmock.Anything, tmock.Anything)
+	})
+
+	t.Run("Failed: failing webhook holds back only its own deliveries", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer failing.Close()
+		healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer healthy.Close()
+
+		other := webhookDelivery(7, healthy.URL, 0)
+		other.WebhookId, other.Webhook.ID = 2, 2
+		iDeliveryMock := mockDeliveries([]models.WebhookDelivery{
+			webhookDelivery(5, failing.URL, 0),
+			webhookDelivery(6, failing.URL, 0),
+			other,
+		}, false)
+		iDeliveryMock.On("RecordWebhookResult", uint32(2), true, uint32(3)).Return(false, nil)
+
+		worker := newWorker()
+		worker.Concurrency = 2
+		delivered, err := worker.RunOnce(context.Background())
+
+		// asserts
+		assert.Nil(err)
+		assert.Equal(1, delivered)
+		iDeliveryMock.AssertCalled(t, "MarkWebhookDelivered", uint64(7), http.StatusOK)
+		iDeliveryMock.AssertCalled(t, "MarkWebhookDeliveryFailed", uint64(5), http.StatusServiceUnavailable, tmock.Anything, tmock.Anything)
+		iDeliveryMock.AssertNotCalled(t, "MarkWebhookDeliveryFailed", uint64(6), tmock.Anything, tmock.Anything, tmock.Anything)
+	})
+}